@@ -0,0 +1,290 @@
+package cards
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // Animated avatars are GIFs
+	_ "image/jpeg"
+	"image/png"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"mary-bot/logging"
+	database "mary-bot/database"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Size of the card in pixels
+const (
+	cardWidth  = 800
+	cardHeight = 300
+	avatarSize = 160
+)
+
+// Colours for a profile card
+type Theme struct {
+	Background color.RGBA
+	Panel      color.RGBA
+	Accent     color.RGBA // XP bar and badges
+	Text       color.RGBA
+	Subtle     color.RGBA // Labels and the empty part of the XP bar
+}
+
+// Define the themes
+var Themes = map[string]Theme{
+	"pink": {
+		Background: color.RGBA{0xff, 0xc0, 0xcb, 0xff},
+		Panel:      color.RGBA{0xff, 0xf0, 0xf3, 0xff},
+		Accent:     color.RGBA{0xe7, 0x5a, 0x7c, 0xff},
+		Text:       color.RGBA{0x3a, 0x1f, 0x2b, 0xff},
+		Subtle:     color.RGBA{0xc9, 0x9a, 0xa8, 0xff},
+	},
+	"dark": {
+		Background: color.RGBA{0x1e, 0x1f, 0x22, 0xff},
+		Panel:      color.RGBA{0x2b, 0x2d, 0x31, 0xff},
+		Accent:     color.RGBA{0x58, 0x65, 0xf2, 0xff},
+		Text:       color.RGBA{0xf2, 0xf3, 0xf5, 0xff},
+		Subtle:     color.RGBA{0x4e, 0x50, 0x58, 0xff},
+	},
+	"mint": {
+		Background: color.RGBA{0xa8, 0xe6, 0xcf, 0xff},
+		Panel:      color.RGBA{0xf0, 0xfa, 0xf5, 0xff},
+		Accent:     color.RGBA{0x2e, 0x9e, 0x78, 0xff},
+		Text:       color.RGBA{0x1b, 0x3a, 0x30, 0xff},
+		Subtle:     color.RGBA{0x9c, 0xc9, 0xb8, 0xff},
+	},
+	"sunset": {
+		Background: color.RGBA{0xff, 0x9a, 0x5a, 0xff},
+		Panel:      color.RGBA{0x3b, 0x23, 0x3f, 0xff},
+		Accent:     color.RGBA{0xff, 0xc8, 0x57, 0xff},
+		Text:       color.RGBA{0xff, 0xf4, 0xe6, 0xff},
+		Subtle:     color.RGBA{0x6d, 0x4a, 0x6e, 0xff},
+	},
+}
+
+// The theme used when none is picked
+const DefaultTheme = "pink"
+
+// Helper to list the theme names for error messages, e.g. "`dark`, `mint`"
+func ThemeNames() (string) {
+	names := []string{}
+	for name := range Themes {
+		names = append(names, "`" + name + "`")
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// Fonts are parsed once and shared by every card
+var (
+	boldFont    = mustParse(gobold.TTF)
+	regularFont = mustParse(goregular.TTF)
+)
+
+func mustParse(ttf []byte) (*opentype.Font) {
+	parsed, err := opentype.Parse(ttf)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// The sizes of text on a card
+// Faces cache glyphs and aren't safe to share between goroutines, so every card makes its own
+type faces struct {
+	title font.Face
+	label font.Face
+	value font.Face
+	badge font.Face
+}
+
+func newFaces() (faces, error) {
+	var f faces
+	var err error
+	sizes := []struct {
+		face *font.Face
+		font *opentype.Font
+		size float64
+	}{
+		{&f.title, boldFont, 40},
+		{&f.label, regularFont, 18},
+		{&f.value, boldFont, 24},
+		{&f.badge, boldFont, 14},
+	}
+	for _, s := range sizes {
+		*s.face, err = opentype.NewFace(s.font, &opentype.FaceOptions{Size: s.size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return faces{}, err
+		}
+	}
+	return f, nil
+}
+
+// Draws a profile card as a PNG
+// The avatar is downloaded from avatarURL, and a coloured circle is drawn instead if that fails
+func RenderProfile(card database.ProfileCard, avatarURL string, themeName string) (*bytes.Buffer, error) {
+	theme, ok := Themes[themeName]
+	if !ok {
+		return nil, fmt.Errorf("there's no theme called %s", themeName)
+	}
+	cardFaces, err := newFaces()
+	if err != nil {
+		return nil, err
+	}
+	titleFace, labelFace, valueFace, badgeFace := cardFaces.title, cardFaces.label, cardFaces.value, cardFaces.badge
+
+	canvas := image.NewRGBA(image.Rect(0, 0, cardWidth, cardHeight))
+	fill(canvas, canvas.Bounds(), theme.Background)
+	fill(canvas, image.Rect(20, 20, cardWidth-20, cardHeight-20), theme.Panel)
+
+	// Avatar on the left, cut into a circle
+	avatarRect := image.Rect(50, (cardHeight-avatarSize)/2, 50+avatarSize, (cardHeight+avatarSize)/2)
+	avatar := downloadAvatar(avatarURL)
+	if avatar == nil {
+		draw.DrawMask(canvas, avatarRect, image.NewUniform(theme.Accent), image.Point{}, circle{avatarSize}, image.Point{}, draw.Over)
+		initial := strings.ToUpper(string([]rune(card.UserName + "?")[0]))
+		text(canvas, titleFace, initial, avatarRect.Min.X+avatarSize/2-measure(titleFace, initial)/2, avatarRect.Min.Y+avatarSize/2+14, theme.Panel)
+	} else {
+		draw.DrawMask(canvas, avatarRect, scale(avatar, avatarSize), image.Point{}, circle{avatarSize}, image.Point{}, draw.Over)
+	}
+
+	// Name and level along the top
+	left := avatarRect.Max.X + 40
+	text(canvas, titleFace, card.UserName, left, 80, theme.Text)
+	level := "Level " + strconv.Itoa(card.Level)
+	text(canvas, valueFace, level, cardWidth-50-measure(valueFace, level), 76, theme.Accent)
+
+	// XP bar
+	barRect := image.Rect(left, 100, cardWidth-50, 118)
+	fill(canvas, barRect, theme.Subtle)
+	if card.NextLevelXP > card.LevelXP {
+		progress := float64(card.XP-card.LevelXP) / float64(card.NextLevelXP-card.LevelXP)
+		if progress > 1 {
+			progress = 1
+		} else if progress < 0 {
+			progress = 0
+		}
+		fill(canvas, image.Rect(barRect.Min.X, barRect.Min.Y, barRect.Min.X+int(float64(barRect.Dx())*progress), barRect.Max.Y), theme.Accent)
+	}
+	xp := fmt.Sprintf("%d / %d XP", card.XP, card.NextLevelXP)
+	text(canvas, labelFace, xp, cardWidth-50-measure(labelFace, xp), 140, theme.Text)
+
+	// Balance, spouse and items in columns
+	spouse := card.Spouse
+	if spouse == "" {
+		spouse = "Nobody yet"
+	}
+	items := strings.Join(card.TopItems, ", ")
+	if items == "" {
+		items = "Nothing yet"
+	}
+	text(canvas, labelFace, "Balance", left, 170, theme.Subtle)
+	text(canvas, valueFace, strconv.FormatInt(card.Balance, 10) + " " + card.Currency, left, 196, theme.Text)
+	text(canvas, labelFace, "Married to", left+260, 170, theme.Subtle)
+	text(canvas, valueFace, spouse, left+260, 196, theme.Text)
+	text(canvas, labelFace, "Items: " + items, left, 226, theme.Text)
+
+	// Badges as a row of pills along the bottom, as many as fit
+	x := left
+	for _, badge := range card.Badges {
+		width := measure(badgeFace, badge) + 20
+		if x+width > cardWidth-50 {
+			break
+		}
+		fill(canvas, image.Rect(x, 240, x+width, 264), theme.Accent)
+		text(canvas, badgeFace, badge, x+10, 257, theme.Panel)
+		x += width + 8
+	}
+
+	buffer := new(bytes.Buffer)
+	err = png.Encode(buffer, canvas)
+	if err != nil {
+		return nil, err
+	}
+	return buffer, nil
+}
+
+// Helper to fill a rectangle with a colour
+func fill(canvas *image.RGBA, rect image.Rectangle, c color.RGBA) {
+	draw.Draw(canvas, rect, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// Helper to draw text with its baseline at (x, y)
+func text(canvas *image.RGBA, face font.Face, s string, x int, y int, c color.RGBA) {
+	drawer := &font.Drawer{
+		Dst: canvas,
+		Src: image.NewUniform(c),
+		Face: face,
+		Dot: fixed.P(x, y),
+	}
+	drawer.DrawString(s)
+}
+
+// Helper to get the width of some text in pixels
+func measure(face font.Face, s string) (int) {
+	return font.MeasureString(face, s).Round()
+}
+
+// A circular mask the size of the avatar
+type circle struct {
+	size int
+}
+
+func (c circle) ColorModel() (color.Model) {
+	return color.AlphaModel
+}
+
+func (c circle) Bounds() (image.Rectangle) {
+	return image.Rect(0, 0, c.size, c.size)
+}
+
+func (c circle) At(x, y int) (color.Color) {
+	r := float64(c.size) / 2
+	dx, dy := float64(x)+0.5-r, float64(y)+0.5-r
+	if dx*dx+dy*dy <= r*r {
+		return color.Alpha{255}
+	}
+	return color.Alpha{0}
+}
+
+// Helper to fetch the avatar, giving up quickly so the card isn't held up
+func downloadAvatar(url string) (image.Image) {
+	if url == "" {
+		return nil
+	}
+	client := http.Client{Timeout: 5 * time.Second}
+	response, err := client.Get(url)
+	if err != nil {
+		logging.Warn("Error occurred while downloading avatar!", "url", url, "err", err)
+		return nil
+	}
+	defer response.Body.Close()
+	avatar, _, err := image.Decode(response.Body)
+	if err != nil {
+		logging.Warn("Error occurred while decoding avatar!", "url", url, "err", err)
+		return nil
+	}
+	return avatar
+}
+
+// Helper to resize an image to a square with nearest-neighbour sampling
+func scale(src image.Image, size int) (image.Image) {
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	bounds := src.Bounds()
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dst.Set(x, y, src.At(bounds.Min.X+x*bounds.Dx()/size, bounds.Min.Y+y*bounds.Dy()/size))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,279 @@
+// Subcommands on the binary, so operators can fix data without going through Discord
+// e.g. mary-bot user set-balance 123 456 1000
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"mary-bot/config"
+	"mary-bot/database"
+	"mary-bot/logging"
+)
+
+const usage = `Usage: mary-bot [command]
+
+Commands:
+  serve                                    Run the bot (the default)
+  user show [guild] [user]                 Show a user's balance, inventory and more
+  user set-balance [guild] [user] [amount] Set a user's balance
+  guild reset --yes [guild]                Delete everything the bot has stored for a guild
+  migrate                                  Bring every guild's database up to date
+  export [--format json|csv] [--out file] [guild]
+                                           Back up a guild, like mary export in Discord
+
+Guilds and users are Discord IDs. Settings come from the same env vars, .env and config file as the bot.
+`
+
+// Runs the command in args (os.Args without the program name) and returns the exit code
+// serve runs the bot and is used when there's no command
+func Run(args []string, serve func() (int)) (int) {
+	if len(args) > 0 && (args[0] == "help" || args[0] == "-h" || args[0] == "--help") {
+		fmt.Print(usage)
+		return 0
+	}
+
+	// Load settings from env vars, .env and the config file (mary.yaml or mary.toml, or CONFIG_FILE)
+	err := config.Load(config.FindConfigFile())
+	if err != nil {
+		logging.Error("Error loading configuration!", "err", err)
+		return 1
+	}
+
+	// Log level and format, e.g. LOG_LEVEL=debug and LOG_FORMAT=json
+	logging.MinLevel, _ = logging.ParseLevel(config.Current.LogLevel) // Already validated
+	logging.JSON = strings.ToLower(config.Current.LogFormat) == "json"
+
+	if len(args) == 0 || args[0] == "serve" {
+		return serve()
+	}
+
+	switch args[0] {
+		case "user":
+			err = user(args[1:])
+		case "guild":
+			err = guild(args[1:])
+		case "migrate":
+			err = migrate(args[1:])
+		case "export":
+			err = export(args[1:])
+		default:
+			err = fmt.Errorf("unknown command %q", args[0])
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "mary-bot:", err)
+		if errors.As(err, new(usageError)) {
+			fmt.Fprint(os.Stderr, "\n" + usage)
+		}
+		return 1
+	}
+	return 0
+}
+
+// Wrong arguments, which also prints the usage
+type usageError string
+
+func (err usageError) Error() (string) {
+	return string(err)
+}
+
+// Not a command
+// Parses flags wherever they are, so both `guild reset --yes 123` and `guild reset 123 --yes` work
+// Returns the arguments that aren't flags
+func parse(flags *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+	for {
+		err := flags.Parse(args)
+		if err != nil {
+			return nil, usageError(err.Error())
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// Not a command
+// Parses a Discord ID, e.g. a guild or user
+func id(name string, arg string) (int, error) {
+	value, err := strconv.Atoi(arg)
+	if err != nil || value <= 0 {
+		return 0, usageError(fmt.Sprintf("%s must be a Discord ID, not %q", name, arg))
+	}
+	return value, nil
+}
+
+// mary-bot user show [guild] [user]
+// mary-bot user set-balance [guild] [user] [amount]
+func user(args []string) (error) {
+	if len(args) == 0 {
+		return usageError("user needs show or set-balance")
+	}
+	flags := flag.NewFlagSet("user " + args[0], flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard) // Errors are printed by Run
+	positional, err := parse(flags, args[1:])
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+		case "show":
+			if len(positional) != 2 {
+				return usageError("user show needs a guild and a user")
+			}
+			guildID, err := id("guild", positional[0])
+			if err != nil {
+				return err
+			}
+			userID, err := id("user", positional[1])
+			if err != nil {
+				return err
+			}
+			user, err := database.FindUser(config.Current.MongoURI, guildID, userID)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("User:      %s (%d)\n", user.UserName, user.UserID)
+			fmt.Printf("Guild:     %s (%d)\n", user.GuildName, user.GuildID)
+			fmt.Printf("Balance:   %d\n", user.Balance)
+			fmt.Printf("Level:     %d (%d XP)\n", user.Level, user.XP)
+			if user.MarriedTo != 0 {
+				fmt.Printf("Married to: %d\n", user.MarriedTo)
+			}
+			if user.JailedUntil.After(time.Now()) {
+				fmt.Printf("Jailed until: %s\n", user.JailedUntil.Format("2006-01-02 15:04:05 MST"))
+			}
+			fmt.Println("Inventory:")
+			for _, item := range user.Inventory {
+				if item.Quantity > 0 {
+					fmt.Printf("  %s x%d\n", item.Name, item.Quantity)
+				}
+			}
+			return nil
+
+		case "set-balance":
+			if len(positional) != 3 {
+				return usageError("user set-balance needs a guild, a user and an amount")
+			}
+			guildID, err := id("guild", positional[0])
+			if err != nil {
+				return err
+			}
+			userID, err := id("user", positional[1])
+			if err != nil {
+				return err
+			}
+			balance, err := strconv.ParseInt(positional[2], 10, 64)
+			if err != nil || balance < 0 {
+				return usageError(fmt.Sprintf("amount must be a whole number of at least 0, not %q", positional[2]))
+			}
+			previous, err := database.SetBalance(config.Current.MongoURI, guildID, userID, balance)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Balance for %d in %d: %d -> %d\n", userID, guildID, previous, balance)
+			return nil
+	}
+	return usageError(fmt.Sprintf("unknown user command %q", args[0]))
+}
+
+// mary-bot guild reset --yes [guild]
+func guild(args []string) (error) {
+	if len(args) == 0 || args[0] != "reset" {
+		return usageError("guild needs reset")
+	}
+	flags := flag.NewFlagSet("guild reset", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard) // Errors are printed by Run
+	yes := flags.Bool("yes", false, "really delete the guild's data")
+	positional, err := parse(flags, args[1:])
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageError("guild reset needs a guild")
+	}
+	guildID, err := id("guild", positional[0])
+	if err != nil {
+		return err
+	}
+	// There's no undo, so make sure it wasn't a typo in the shell history
+	if !*yes {
+		return fmt.Errorf("this deletes every user, item and setting in %d, run again with --yes to go ahead (export it first to keep a backup)", guildID)
+	}
+
+	err = database.ResetGuild(config.Current.MongoURI, guildID)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Reset %d\n", guildID)
+	return nil
+}
+
+// mary-bot migrate
+func migrate(args []string) (error) {
+	if len(args) != 0 {
+		return usageError("migrate doesn't take any arguments")
+	}
+	guildIDs, err := database.Migrate(config.Current.MongoURI)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Migrated %d guilds\n", len(guildIDs))
+	return nil
+}
+
+// mary-bot export [--format json|csv] [--out file] [guild]
+func export(args []string) (error) {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard) // Errors are printed by Run
+	format := flags.String("format", "json", "json or csv")
+	out := flags.String("out", "", "file to write, stdout if not set")
+	positional, err := parse(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageError("export needs a guild")
+	}
+	guildID, err := id("guild", positional[0])
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := database.Export(config.Current.MongoURI, guildID, "")
+	if err != nil {
+		return err
+	}
+	var data []byte
+	switch *format {
+		case "json":
+			data, err = snapshot.JSON()
+		case "csv":
+			data, err = snapshot.CSV()
+		default:
+			return usageError(fmt.Sprintf("format must be json or csv, not %q", *format))
+	}
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	err = ioutil.WriteFile(*out, data, 0644)
+	if err != nil {
+		return err
+	}
+	// Stdout might be piped, so the summary only goes out when writing to a file
+	fmt.Printf("Exported %d users from %d to %s\n", len(snapshot.Users), guildID, *out)
+	return nil
+}
@@ -2,12 +2,12 @@ package commands
 
 import (
 	"context"
-	"fmt"
-	"os"
 	"strconv"
 	"strings"
+	"mary-bot/config"
+	"mary-bot/logging"
+	"mary-bot/metrics"
 	"github.com/bwmarrin/discordgo"
-	//"github.com/joho/godotenv"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options" 
 	"go.mongodb.org/mongo-driver/bson"
@@ -15,27 +15,11 @@ import (
 
 // Helper function to allow for commands by only me (the creator of the bot)
 func IsOwner(userID int) (bool) {
-	// Load owner user id from env vars
-	// err := godotenv.Load(".env")
-	// if err != nil {
-	// 	fmt.Printf("Error loading environment variables! %s\n", err)
-	// 	return false
-	// }
-
-	OWNER_ID := os.Getenv("OWNER_ID")
-	if OWNER_ID == "" {
-		fmt.Println("Owner ID not found!")
+	if config.Current.OwnerID == 0 {
+		logging.Warn("Owner ID not found!")
 		return false
 	}
-
-	ownerID, err2 := strconv.Atoi(OWNER_ID)
-	if err2 != nil {
-		fmt.Println("Owner ID is not a valid integer!")
-		return false
-	} else if ownerID == userID {
-		return true
-	}
-	return false
+	return config.Current.OwnerID == userID
 }
 
 func DeleteMessages(session *discordgo.Session, message *discordgo.MessageCreate, userID int, amount int) (string) {
@@ -67,15 +51,17 @@ func Bankrupt(mongoURI string, guildID int, userID int, pingedUserID int) (strin
 		return "Apologies, this command is not available to you."
 	}
 
-	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI))
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
 	if err != nil {
-		fmt.Printf("Error occurred creating MongoDB client! %s\n", err)
+		logging.Error("Error occurred creating MongoDB client!", "guild", guildID, "user", userID, "err", err)
+		metrics.ErrorsTotal.Inc("database")
 		return "Error occurred creating MongoDB client! " + strings.Title(err.Error())
 	}
 	ctx := context.Background()
 	err = client.Connect(ctx)
 	if err != nil {
-		fmt.Printf("Error occurred connecting to MongoDB! %s\n", err)
+		logging.Error("Error occurred connecting to MongoDB!", "guild", guildID, "user", userID, "err", err)
+		metrics.ErrorsTotal.Inc("database")
 		return "Error occurred connecting to MongoDB! " + strings.Title(err.Error())
 	}
 	defer client.Disconnect(ctx)
@@ -89,9 +75,13 @@ func Bankrupt(mongoURI string, guildID int, userID int, pingedUserID int) (strin
 	var result bson.M
 	err = collection.FindOneAndUpdate(ctx, filter, update).Decode(&result)
 	if err != nil {
-		fmt.Printf("Error occurred while updating database! %s\n", err)
+		logging.Warn("Error occurred while updating database!", "guild", guildID, "user", pingedUserID, "err", err)
 		return "That person is not currently playing the game!"
 	}
+	if balance, ok := result["balance"].(int64); ok {
+		metrics.CoinsBurned.Add("bankrupt", float64(balance))
+	}
+	logging.Info("Bankrupted user", "guild", guildID, "user", userID, "target", pingedUserID)
 	// ping user with <@!user_id> to get their name
 	return "<@!" + strconv.Itoa(pingedUserID) + ">, you are now bankrupt!"
 }
\ No newline at end of file
@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"sync"
+)
+
+// discordgo runs every handler in its own goroutine, so a user spamming `mary gamble 100`
+// could pass the balance check several times before any bet is taken
+// Commands that change a user's data take this lock first, so each user's run one at a time
+
+// A user in a guild, since the same user can play in more than one
+type userKey struct {
+	guildID int
+	userID  int
+}
+
+// Users with a command running
+var (
+	busyUsers     = map[userKey]bool{}
+	busyUsersLock sync.Mutex
+)
+
+// Marks the user as running a command, returning false if they already are
+// Doesn't wait, so a second command can be turned away straight away instead of piling up
+// Call the returned function when the command is done
+func LockUser(guildID int, userID int) (func(), bool) {
+	key := userKey{guildID, userID}
+
+	busyUsersLock.Lock()
+	defer busyUsersLock.Unlock()
+	if busyUsers[key] {
+		return nil, false
+	}
+	busyUsers[key] = true
+
+	return func() {
+		busyUsersLock.Lock()
+		defer busyUsersLock.Unlock()
+		delete(busyUsers, key) // Deleted rather than set to false, so the map only holds running commands
+	}, true
+}
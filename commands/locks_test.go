@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestLockUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		first   userKey
+		second  userKey
+		wantTwo bool // Whether the second lock is granted while the first is held
+	}{
+		{"same user", userKey{1, 10}, userKey{1, 10}, false},
+		{"different user", userKey{1, 10}, userKey{1, 11}, true},
+		{"same user in another guild", userKey{1, 10}, userKey{2, 10}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			unlock, ok := LockUser(test.first.guildID, test.first.userID)
+			if !ok {
+				t.Fatalf("first LockUser was turned away")
+			}
+			unlockSecond, ok := LockUser(test.second.guildID, test.second.userID)
+			if ok != test.wantTwo {
+				t.Errorf("second LockUser = %v, want %v", ok, test.wantTwo)
+			}
+			if ok {
+				unlockSecond()
+			}
+			unlock()
+
+			// Once unlocked, the user can run commands again and nothing is left behind
+			unlock, ok = LockUser(test.first.guildID, test.first.userID)
+			if !ok {
+				t.Errorf("LockUser after unlock was turned away")
+			} else {
+				unlock()
+			}
+			if len(busyUsers) != 0 {
+				t.Errorf("busyUsers = %v, want empty", busyUsers)
+			}
+		})
+	}
+}
+
+func TestLockUserConcurrent(t *testing.T) {
+	// Only one of many commands sent at once gets to run
+	var wg sync.WaitGroup
+	var granted sync.Map
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, ok := LockUser(1, 10); ok {
+				granted.Store(i, true)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	count := 0
+	granted.Range(func(_, _ interface{}) bool {
+		count++
+		return true
+	})
+	if count != 1 {
+		t.Errorf("%d commands got the lock, want 1", count)
+	}
+	busyUsers = map[userKey]bool{}
+}
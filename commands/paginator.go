@@ -0,0 +1,309 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"mary-bot/logging"
+	"mary-bot/metrics"
+	"github.com/bwmarrin/discordgo"
+)
+
+// How long a paginated message keeps its buttons after the last time someone used them
+const paginatorTimeout = 2 * time.Minute
+
+// Custom IDs for the paginator buttons and the jump-to-page popup
+const (
+	paginatorFirst = "paginator_first"
+	paginatorPrev  = "paginator_prev"
+	paginatorNext  = "paginator_next"
+	paginatorLast  = "paginator_last"
+	paginatorJump  = "paginator_jump"
+	paginatorJumpModal = "paginator_jump_modal:" // Followed by the message ID
+	paginatorJumpInput = "paginator_jump_page"
+)
+
+// Gets a page by its index, or returns an error message if it couldn't
+type PageLoader func(page int) (string, *discordgo.MessageEmbed)
+
+// A message with pages that can be flipped through with buttons
+type Paginator struct {
+	Load      PageLoader
+	PageCount int
+	Current   int
+	OwnerID   string // Only the user who ran the command can turn the pages
+	ChannelID string
+	MessageID string
+	lastUsed  time.Time
+	timer     *time.Timer
+}
+
+// Paginators that still have their buttons, by message ID
+var (
+	paginators     = map[string]*Paginator{}
+	paginatorsLock sync.Mutex
+)
+
+// Helper to split a list of fields into embeds of at most pageSize fields each
+// Every page is a copy of template with its own fields
+func PaginateFields(template discordgo.MessageEmbed, fields []*discordgo.MessageEmbedField, pageSize int) ([]*discordgo.MessageEmbed) {
+	pages := []*discordgo.MessageEmbed{}
+	for start := 0; start < len(fields) || start == 0; start += pageSize {
+		end := start + pageSize
+		if end > len(fields) {
+			end = len(fields)
+		}
+		page := template
+		page.Fields = fields[start:end]
+		pages = append(pages, &page)
+	}
+	return pages
+}
+
+// Sends the pages starting from currentPage, with buttons to move between them if there's more than one
+func SendPaginator(session *discordgo.Session, channelID string, ownerID string, pages []*discordgo.MessageEmbed, currentPage int) (error) {
+	return SendPageLoader(session, channelID, ownerID, len(pages), currentPage, func(page int) (string, *discordgo.MessageEmbed) {
+		return "", pages[page]
+	})
+}
+
+// Like SendPaginator, but each page is only fetched when it's turned to
+// Useful when the pages come from the database and there could be a lot of them
+func SendPageLoader(session *discordgo.Session, channelID string, ownerID string, pageCount int, currentPage int, load PageLoader) (error) {
+	if pageCount <= 0 {
+		return fmt.Errorf("nothing to show")
+	}
+
+	// Check if the currentPage is out of bounds
+	if currentPage < 0 {
+		currentPage = 0
+	} else if currentPage >= pageCount {
+		currentPage = pageCount - 1
+	}
+
+	paginator := &Paginator{
+		Load: load,
+		PageCount: pageCount,
+		Current: currentPage,
+		OwnerID: ownerID,
+		ChannelID: channelID,
+	}
+	res, embed := paginator.page(currentPage)
+	if res != "" {
+		session.ChannelMessageSend(channelID, res)
+		return nil
+	}
+
+	// One page doesn't need any buttons
+	if pageCount == 1 {
+		_, err := session.ChannelMessageSendEmbed(channelID, embed)
+		return err
+	}
+
+	sent, err := session.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{embed},
+		Components: paginator.buttons(currentPage, false),
+	})
+	if err != nil {
+		return err
+	}
+	paginator.MessageID = sent.ID
+	paginator.lastUsed = time.Now()
+
+	paginatorsLock.Lock()
+	defer paginatorsLock.Unlock()
+
+	// Take the buttons away once nobody has used them for a while
+	paginator.timer = time.AfterFunc(paginatorTimeout, func() {
+		paginatorsLock.Lock()
+		// The buttons were used while this was waiting for the lock, so the timer has already been reset
+		if time.Since(paginator.lastUsed) < paginatorTimeout {
+			paginatorsLock.Unlock()
+			return
+		}
+		delete(paginators, paginator.MessageID)
+		current := paginator.Current
+		paginatorsLock.Unlock()
+
+		components := paginator.buttons(current, true)
+		session.ChannelMessageEditComplex(&discordgo.MessageEdit{
+			ID: paginator.MessageID,
+			Channel: paginator.ChannelID,
+			Components: components,
+		})
+	})
+	paginators[sent.ID] = paginator
+	return nil
+}
+
+// Handler for the paginator buttons and the jump-to-page popup
+// Needs to be added with session.AddHandler
+func HandlePaginator(session *discordgo.Session, interaction *discordgo.InteractionCreate) {
+	messageID := ""
+	switch interaction.Type {
+	case discordgo.InteractionMessageComponent:
+		if !strings.HasPrefix(interaction.MessageComponentData().CustomID, "paginator_") {
+			return
+		}
+		messageID = interaction.Message.ID
+	case discordgo.InteractionModalSubmit:
+		if !strings.HasPrefix(interaction.ModalSubmitData().CustomID, paginatorJumpModal) {
+			return
+		}
+		messageID = strings.TrimPrefix(interaction.ModalSubmitData().CustomID, paginatorJumpModal)
+	default:
+		return
+	}
+
+	// Only hold the lock to find the paginator and change its page, loading the page and replying can take a while
+	paginatorsLock.Lock()
+	paginator, ok := paginators[messageID]
+	isOwner := ok && interactionUserID(interaction) == paginator.OwnerID
+	if isOwner {
+		// Someone's still using it, so reset the timeout
+		paginator.lastUsed = time.Now()
+		paginator.timer.Reset(paginatorTimeout)
+	}
+	paginatorsLock.Unlock()
+
+	if !ok {
+		respondEphemeral(session, interaction, "These buttons have expired! Run the command again.")
+		return
+	}
+	if !isOwner {
+		respondEphemeral(session, interaction, "Only the person who ran the command can turn the pages!")
+		return
+	}
+
+	// Work out which page to turn to from the one it's on
+	turn := func(current int) (int) { return current }
+	if interaction.Type == discordgo.InteractionModalSubmit {
+		text := interaction.ModalSubmitData().Components[0].(*discordgo.ActionsRow).Components[0].(*discordgo.TextInput).Value
+		page, err := strconv.Atoi(strings.TrimSpace(text))
+		if err != nil || page < 1 || page > paginator.PageCount {
+			respondEphemeral(session, interaction, fmt.Sprintf("Please enter a page number from 1 to %d!", paginator.PageCount))
+			return
+		}
+		turn = func(current int) (int) { return page - 1 }
+	} else {
+		switch interaction.MessageComponentData().CustomID {
+		case paginatorFirst:
+			turn = func(current int) (int) { return 0 }
+		case paginatorPrev:
+			turn = func(current int) (int) { return current - 1 }
+		case paginatorNext:
+			turn = func(current int) (int) { return current + 1 }
+		case paginatorLast:
+			turn = func(current int) (int) { return paginator.PageCount - 1 }
+		case paginatorJump:
+			// Ask which page to go to
+			err := session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseModal,
+				Data: &discordgo.InteractionResponseData{
+					CustomID: paginatorJumpModal + messageID,
+					Title: "Go to page",
+					Components: []discordgo.MessageComponent{
+						discordgo.ActionsRow{
+							Components: []discordgo.MessageComponent{
+								discordgo.TextInput{
+									CustomID: paginatorJumpInput,
+									Label: fmt.Sprintf("Page (1-%d)", paginator.PageCount),
+									Style: discordgo.TextInputShort,
+									Required: true,
+									MaxLength: 4,
+								},
+							},
+						},
+					},
+				},
+			})
+			if err != nil {
+				logging.Error("Error occurred while opening page popup!", "channel", interaction.ChannelID, "err", err)
+				metrics.ErrorsTotal.Inc("discord")
+			}
+			return
+		}
+	}
+
+	paginatorsLock.Lock()
+	current := turn(paginator.Current)
+	// Check if the page is out of bounds
+	if current < 0 {
+		current = 0
+	} else if current >= paginator.PageCount {
+		current = paginator.PageCount - 1
+	}
+	paginator.Current = current
+	paginatorsLock.Unlock()
+
+	res, embed := paginator.page(current)
+	if res != "" {
+		respondEphemeral(session, interaction, res)
+		return
+	}
+	err := session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Components: paginator.buttons(current, false),
+		},
+	})
+	if err != nil {
+		logging.Error("Error occurred while turning page!", "channel", interaction.ChannelID, "err", err)
+		metrics.ErrorsTotal.Inc("discord")
+	}
+}
+
+// A page with its page number in the footer
+func (paginator *Paginator) page(current int) (string, *discordgo.MessageEmbed) {
+	res, embed := paginator.Load(current)
+	if res != "" {
+		return res, nil
+	}
+	page := *embed
+	page.Footer = &discordgo.MessageEmbedFooter{
+		Text: fmt.Sprintf("Page %d of %d", current+1, paginator.PageCount),
+	}
+	return "", &page
+}
+
+// The row of buttons under the message when it's on the current page, all greyed out once it has expired
+func (paginator *Paginator) buttons(current int, expired bool) ([]discordgo.MessageComponent) {
+	atStart := current == 0
+	atEnd := current == paginator.PageCount-1
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{Label: "⏮", Style: discordgo.SecondaryButton, CustomID: paginatorFirst, Disabled: expired || atStart},
+				discordgo.Button{Label: "◀", Style: discordgo.PrimaryButton, CustomID: paginatorPrev, Disabled: expired || atStart},
+				discordgo.Button{Label: "Go to page", Style: discordgo.SecondaryButton, CustomID: paginatorJump, Disabled: expired},
+				discordgo.Button{Label: "▶", Style: discordgo.PrimaryButton, CustomID: paginatorNext, Disabled: expired || atEnd},
+				discordgo.Button{Label: "⏭", Style: discordgo.SecondaryButton, CustomID: paginatorLast, Disabled: expired || atEnd},
+			},
+		},
+	}
+}
+
+// Helper to reply to an interaction with a message only the user can see
+func respondEphemeral(session *discordgo.Session, interaction *discordgo.InteractionCreate, content string) {
+	session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// Helper to get the ID of the user who clicked, whether it was in a server or a DM
+func interactionUserID(interaction *discordgo.InteractionCreate) (string) {
+	if interaction.Member != nil && interaction.Member.User != nil {
+		return interaction.Member.User.ID
+	}
+	if interaction.User != nil {
+		return interaction.User.ID
+	}
+	return ""
+}
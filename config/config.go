@@ -0,0 +1,342 @@
+// Package config loads Mary's settings once at startup and shares them with every other package
+// Settings come from, in order of priority: environment variables, a .env file, then an optional YAML or TOML config file
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"mary-bot/logging"
+)
+
+// Every setting, typed
+// Keys in the config file are the lowercase names in the comments, e.g. cooldowns.daily
+// Environment variables are the same keys in uppercase with dots replaced by underscores, e.g. COOLDOWNS_DAILY
+type Config struct {
+	Token           string // token
+	MongoURI        string // mongo_uri
+	OwnerID         int    // owner_id
+	Status          string // status, shown as "Playing <status>"
+	SellBackPercent int    // sell_back_percent, of the shop price (capped at the base price) paid out when selling items back
+	LogLevel        string // log.level
+	LogFormat       string // log.format
+	HTTPAddr        string // http_addr, serves /healthz, /readyz and /metrics
+	ShopPageSize    int    // shop_page_size, items per shop page
+	StartingBalance int    // starting_balance, coins new players start with
+	Currency        Currency
+	Cooldowns       Cooldowns
+	Payouts         Payouts
+}
+
+// What the server calls its coins
+type Currency struct {
+	Name  string // currency.name
+	Emoji string // currency.emoji
+}
+
+// How long users have to wait between commands
+type Cooldowns struct {
+	Daily  time.Duration // cooldowns.daily
+	Beg    time.Duration // cooldowns.beg
+	Rob    time.Duration // cooldowns.rob
+	Gamble time.Duration // cooldowns.gamble, shared by gamble, lottery and slots
+	Trivia time.Duration // cooldowns.trivia
+	Use    time.Duration // cooldowns.use
+}
+
+// How many coins commands pay out or cost
+type Payouts struct {
+	Daily      int // payouts.daily
+	BegMin     int // payouts.beg_min
+	BegMax     int // payouts.beg_max
+	LotteryBet int // payouts.lottery_bet
+	SlotsBet   int // payouts.slots_bet
+	TriviaEasy   int // payouts.trivia_easy
+	TriviaMedium int // payouts.trivia_medium
+	TriviaHard   int // payouts.trivia_hard
+	RobMinPercent int // payouts.rob_min_percent, of the victim's balance taken on a successful rob
+	RobMaxPercent int // payouts.rob_max_percent
+}
+
+// The settings in use, filled in by Load at startup
+var Current = Default()
+
+// The settings Mary uses when nothing else is set
+func Default() (Config) {
+	return Config{
+		Status:          "with her sister Eve",
+		SellBackPercent: 50,
+		LogLevel:        "info",
+		ShopPageSize:    3,
+		Currency: Currency{
+			Name: "coins",
+		},
+		Cooldowns: Cooldowns{
+			Daily:  24 * time.Hour,
+			Beg:    time.Minute,
+			Rob:    5 * time.Minute,
+			Gamble: 10 * time.Second,
+			Trivia: 5 * time.Second,
+			Use:    time.Minute,
+		},
+		Payouts: Payouts{
+			Daily:      100,
+			BegMin:     1,
+			BegMax:     10,
+			LotteryBet: 100,
+			SlotsBet:   10,
+			TriviaEasy:   50,
+			TriviaMedium: 100,
+			TriviaHard:   200,
+			RobMinPercent: 5,
+			RobMaxPercent: 15,
+		},
+	}
+}
+
+// A setting and how to parse it into the config
+// Guild settings can also be changed per server with `mary config set`
+type setting struct {
+	key    string
+	guild  bool
+	parse  func(config *Config, value string) (error)
+	format func(config *Config) (string)
+}
+
+// Define the settings
+var settings = []setting{
+	stringSetting("token", false, func(c *Config) *string { return &c.Token }),
+	stringSetting("mongo_uri", false, func(c *Config) *string { return &c.MongoURI }),
+	intSetting("owner_id", false, func(c *Config) *int { return &c.OwnerID }),
+	stringSetting("status", false, func(c *Config) *string { return &c.Status }),
+	intSetting("sell_back_percent", false, func(c *Config) *int { return &c.SellBackPercent }),
+	stringSetting("log.level", false, func(c *Config) *string { return &c.LogLevel }),
+	stringSetting("log.format", false, func(c *Config) *string { return &c.LogFormat }),
+	stringSetting("http_addr", false, func(c *Config) *string { return &c.HTTPAddr }),
+	intSetting("shop_page_size", false, func(c *Config) *int { return &c.ShopPageSize }),
+	intSetting("starting_balance", true, func(c *Config) *int { return &c.StartingBalance }),
+	stringSetting("currency.name", true, func(c *Config) *string { return &c.Currency.Name }),
+	stringSetting("currency.emoji", true, func(c *Config) *string { return &c.Currency.Emoji }),
+	durationSetting("cooldowns.daily", true, func(c *Config) *time.Duration { return &c.Cooldowns.Daily }),
+	durationSetting("cooldowns.beg", true, func(c *Config) *time.Duration { return &c.Cooldowns.Beg }),
+	durationSetting("cooldowns.rob", true, func(c *Config) *time.Duration { return &c.Cooldowns.Rob }),
+	durationSetting("cooldowns.gamble", true, func(c *Config) *time.Duration { return &c.Cooldowns.Gamble }),
+	durationSetting("cooldowns.trivia", true, func(c *Config) *time.Duration { return &c.Cooldowns.Trivia }),
+	durationSetting("cooldowns.use", true, func(c *Config) *time.Duration { return &c.Cooldowns.Use }),
+	intSetting("payouts.daily", true, func(c *Config) *int { return &c.Payouts.Daily }),
+	intSetting("payouts.beg_min", true, func(c *Config) *int { return &c.Payouts.BegMin }),
+	intSetting("payouts.beg_max", true, func(c *Config) *int { return &c.Payouts.BegMax }),
+	intSetting("payouts.lottery_bet", true, func(c *Config) *int { return &c.Payouts.LotteryBet }),
+	intSetting("payouts.slots_bet", true, func(c *Config) *int { return &c.Payouts.SlotsBet }),
+	intSetting("payouts.trivia_easy", true, func(c *Config) *int { return &c.Payouts.TriviaEasy }),
+	intSetting("payouts.trivia_medium", true, func(c *Config) *int { return &c.Payouts.TriviaMedium }),
+	intSetting("payouts.trivia_hard", true, func(c *Config) *int { return &c.Payouts.TriviaHard }),
+	intSetting("payouts.rob_min_percent", true, func(c *Config) *int { return &c.Payouts.RobMinPercent }),
+	intSetting("payouts.rob_max_percent", true, func(c *Config) *int { return &c.Payouts.RobMaxPercent }),
+}
+
+// Helper to get the environment variable for a setting, e.g. cooldowns.daily -> COOLDOWNS_DAILY
+func envName(key string) (string) {
+	return strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// Loads the settings into Current
+// path is the YAML or TOML config file, which is optional, so an empty path skips it
+// The .env file is also optional, and never overrides variables that are already set
+func Load(path string) (error) {
+	config := Default()
+	errs := ValidationError{}
+
+	if path != "" {
+		values, err := readConfigFile(path)
+		if err != nil {
+			return err
+		}
+		for _, setting := range settings {
+			if value, ok := values[setting.key]; ok {
+				if err := setting.parse(&config, value); err != nil {
+					errs = append(errs, fmt.Sprintf("%s in %s %s", setting.key, path, err))
+				}
+			}
+			delete(values, setting.key)
+		}
+		for key := range values {
+			errs = append(errs, fmt.Sprintf("%s in %s is not a setting", key, path))
+		}
+	}
+
+	err := loadDotEnv(".env")
+	if err != nil {
+		return err
+	}
+	for _, setting := range settings {
+		if value := os.Getenv(envName(setting.key)); value != "" {
+			if err := setting.parse(&config, value); err != nil {
+				errs = append(errs, fmt.Sprintf("%s %s", envName(setting.key), err))
+			}
+		}
+	}
+	// METRICS_ADDR still works for setups from before the health checks
+	if METRICS_ADDR := os.Getenv("METRICS_ADDR"); METRICS_ADDR != "" && config.HTTPAddr == "" {
+		config.HTTPAddr = METRICS_ADDR
+	}
+
+	errs = append(errs, config.validate()...)
+	if len(errs) > 0 {
+		return errs
+	}
+	Current = config
+	return nil
+}
+
+// Checks the settings only serving needs, so the command line works without a token
+func RequireToken() (error) {
+	if Current.Token == "" {
+		return ValidationError{"token is required"}
+	}
+	return nil
+}
+
+// Every problem found with the settings, so they can all be fixed at once
+type ValidationError []string
+
+func (e ValidationError) Error() (string) {
+	return "invalid configuration: " + strings.Join(e, "; ")
+}
+
+// The settings servers can change for themselves, in the order they're shown
+func GuildKeys() ([]string) {
+	keys := []string{}
+	for _, setting := range settings {
+		if setting.guild {
+			keys = append(keys, setting.key)
+		}
+	}
+	return keys
+}
+
+// Changes a guild setting, e.g. Set("cooldowns.daily", "12h")
+// Returns an error if key isn't a guild setting or the value doesn't parse or make sense
+func (c *Config) Set(key string, value string) (error) {
+	for _, setting := range settings {
+		if setting.key != key || !setting.guild {
+			continue
+		}
+		changed := *c
+		err := setting.parse(&changed, value)
+		if err != nil {
+			return fmt.Errorf("%s %w", key, err)
+		}
+		if errs := changed.validate(); len(errs) > 0 {
+			return fmt.Errorf("%s", strings.Join(errs, "; "))
+		}
+		*c = changed
+		return nil
+	}
+	return fmt.Errorf("%s is not a server setting", key)
+}
+
+// The value of a guild setting as it would be written in `mary config set`, e.g. "12h"
+func (c Config) Get(key string) (string) {
+	for _, setting := range settings {
+		if setting.key == key {
+			return setting.format(&c)
+		}
+	}
+	return ""
+}
+
+// Not a command
+// Checks that required settings are there and the rest make sense
+func (c Config) validate() (ValidationError) {
+	errs := ValidationError{}
+	// The token is only needed to serve, so it's checked there instead, see RequireToken()
+	if c.MongoURI == "" {
+		errs = append(errs, "mongo_uri is required")
+	}
+	// Any higher and buying today's deals (20% off) and selling them straight back would make coins
+	if c.SellBackPercent < 0 || c.SellBackPercent > 80 {
+		errs = append(errs, "sell_back_percent must be between 0 and 80")
+	}
+	if _, ok := logging.ParseLevel(c.LogLevel); !ok {
+		errs = append(errs, "log.level must be one of debug, info, warn or error")
+	}
+	if c.LogFormat != "" && strings.ToLower(c.LogFormat) != "json" {
+		errs = append(errs, "log.format must be json or left unset")
+	}
+	// Discord allows 25 fields per embed
+	if c.ShopPageSize < 1 || c.ShopPageSize > 25 {
+		errs = append(errs, "shop_page_size must be between 1 and 25")
+	}
+	if c.Cooldowns.Daily < 0 || c.Cooldowns.Beg < 0 || c.Cooldowns.Rob < 0 || c.Cooldowns.Gamble < 0 || c.Cooldowns.Trivia < 0 || c.Cooldowns.Use < 0 {
+		errs = append(errs, "cooldowns can't be negative")
+	}
+	if c.StartingBalance < 0 {
+		errs = append(errs, "starting_balance can't be negative")
+	}
+	if strings.TrimSpace(c.Currency.Name) == "" || len(c.Currency.Name) > 32 {
+		errs = append(errs, "currency.name must be between 1 and 32 characters")
+	}
+	if len(c.Currency.Emoji) > 64 {
+		errs = append(errs, "currency.emoji is too long")
+	}
+	if c.Payouts.Daily < 0 || c.Payouts.LotteryBet < 0 || c.Payouts.SlotsBet < 0 || c.Payouts.TriviaEasy < 0 || c.Payouts.TriviaMedium < 0 || c.Payouts.TriviaHard < 0 {
+		errs = append(errs, "payouts can't be negative")
+	}
+	if c.Payouts.RobMinPercent < 0 || c.Payouts.RobMaxPercent > 100 || c.Payouts.RobMaxPercent < c.Payouts.RobMinPercent {
+		errs = append(errs, "payouts.rob_min_percent and payouts.rob_max_percent must be between 0 and 100, min first")
+	}
+	if c.Payouts.BegMin < 0 || c.Payouts.BegMax < c.Payouts.BegMin {
+		errs = append(errs, "payouts.beg_min must be at least 0 and at most payouts.beg_max")
+	}
+	return errs
+}
+
+// Not a command
+// Helpers to define each type of setting from its field
+func stringSetting(key string, guild bool, field func(*Config) *string) (setting) {
+	return setting{key, guild,
+		func(config *Config, value string) (error) {
+			*field(config) = value
+			return nil
+		},
+		func(config *Config) (string) {
+			return *field(config)
+		},
+	}
+}
+
+func intSetting(key string, guild bool, field func(*Config) *int) (setting) {
+	return setting{key, guild,
+		func(config *Config, value string) (error) {
+			num, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("must be a whole number, got %q", value)
+			}
+			*field(config) = num
+			return nil
+		},
+		func(config *Config) (string) {
+			return strconv.Itoa(*field(config))
+		},
+	}
+}
+
+// Durations are written like 24h, 5m or 10s
+func durationSetting(key string, guild bool, field func(*Config) *time.Duration) (setting) {
+	return setting{key, guild,
+		func(config *Config, value string) (error) {
+			duration, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("must be a duration like 24h, 5m or 10s, got %q", value)
+			}
+			*field(config) = duration
+			return nil
+		},
+		func(config *Config) (string) {
+			return field(config).String()
+		},
+	}
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	valid := Default()
+	valid.MongoURI = "mongodb://localhost:27017"
+
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		want   int // Number of problems found
+	}{
+		{"defaults", func(c *Config) {}, 0},
+		{"no mongo_uri", func(c *Config) { c.MongoURI = "" }, 1},
+		{"sell back too high", func(c *Config) { c.SellBackPercent = 81 }, 1},
+		{"sell back at the cap", func(c *Config) { c.SellBackPercent = 80 }, 0},
+		{"negative sell back", func(c *Config) { c.SellBackPercent = -1 }, 1},
+		{"unknown log level", func(c *Config) { c.LogLevel = "loud" }, 1},
+		{"log format", func(c *Config) { c.LogFormat = "JSON" }, 0},
+		{"unknown log format", func(c *Config) { c.LogFormat = "text" }, 1},
+		{"empty shop page", func(c *Config) { c.ShopPageSize = 0 }, 1},
+		{"too many fields", func(c *Config) { c.ShopPageSize = 26 }, 1},
+		{"negative cooldown", func(c *Config) { c.Cooldowns.Rob = -time.Second }, 1},
+		{"blank currency", func(c *Config) { c.Currency.Name = "  " }, 1},
+		{"negative payout", func(c *Config) { c.Payouts.Daily = -1 }, 1},
+		{"rob range backwards", func(c *Config) { c.Payouts.RobMinPercent, c.Payouts.RobMaxPercent = 20, 10 }, 1},
+		{"beg range backwards", func(c *Config) { c.Payouts.BegMin, c.Payouts.BegMax = 10, 1 }, 1},
+		{"several", func(c *Config) { c.MongoURI, c.StartingBalance = "", -5 }, 2},
+	}
+	for _, test := range tests {
+		config := valid
+		test.change(&config)
+		if errs := config.validate(); len(errs) != test.want {
+			t.Errorf("%s: validate() = %q, want %d problems", test.name, errs, test.want)
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		want    string // What Get returns afterwards
+		wantErr bool
+	}{
+		{"cooldowns.daily", "12h", "12h0m0s", false},
+		{"payouts.daily", "250", "250", false},
+		{"currency.name", "gems", "gems", false},
+		{"cooldowns.daily", "tomorrow", "", true},
+		{"payouts.daily", "lots", "", true},
+		{"payouts.daily", "-1", "", true},
+		{"payouts.beg_min", "20", "", true}, // More than payouts.beg_max
+		{"token", "secret", "", true},       // Not a server setting
+		{"nonexistent", "1", "", true},
+	}
+	for _, test := range tests {
+		config := Default()
+		config.MongoURI = "mongodb://localhost:27017"
+		before := config
+		err := config.Set(test.key, test.value)
+		if (err != nil) != test.wantErr {
+			t.Errorf("Set(%q, %q) = %v, want error: %v", test.key, test.value, err, test.wantErr)
+			continue
+		}
+		if err != nil && config != before {
+			t.Errorf("Set(%q, %q) changed the config after failing", test.key, test.value)
+		}
+		if err == nil && config.Get(test.key) != test.want {
+			t.Errorf("Get(%q) = %q after setting it to %q, want %q", test.key, config.Get(test.key), test.value, test.want)
+		}
+	}
+}
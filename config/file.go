@@ -0,0 +1,157 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"gopkg.in/yaml.v3"
+)
+
+// Config files looked for in the working directory when CONFIG_FILE isn't set
+var defaultConfigFiles = []string{"mary.yaml", "mary.yml", "mary.toml"}
+
+// Helper to find the config file, "" if there isn't one
+func FindConfigFile() (string) {
+	if CONFIG_FILE := os.Getenv("CONFIG_FILE"); CONFIG_FILE != "" {
+		return CONFIG_FILE
+	}
+	for _, path := range defaultConfigFiles {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// Not a command
+// Reads a YAML or TOML config file into setting keys and values, e.g. "cooldowns.daily" -> "24h"
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file %s: %w", path, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml":
+			var doc map[string]interface{}
+			err = yaml.Unmarshal(data, &doc)
+			if err != nil {
+				return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+			}
+			values := map[string]string{}
+			flatten("", doc, values)
+			return values, nil
+
+		case ".toml":
+			values, err := parseTOML(string(data))
+			if err != nil {
+				return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+			}
+			return values, nil
+	}
+	return nil, fmt.Errorf("config file %s must end in .yaml, .yml or .toml", path)
+}
+
+// Helper to turn nested YAML sections into dotted keys, e.g. cooldowns: {daily: 24h} -> cooldowns.daily
+func flatten(prefix string, doc map[string]interface{}, values map[string]string) {
+	for key, value := range doc {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if section, ok := value.(map[string]interface{}); ok {
+			flatten(key, section, values)
+		} else {
+			values[key] = fmt.Sprint(value)
+		}
+	}
+}
+
+// Not a command
+// Parses the part of TOML that Mary's settings need: [sections] and key = value lines with strings, numbers or booleans
+func parseTOML(data string) (map[string]string, error) {
+	values := map[string]string{}
+	section := ""
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			end := strings.Index(line, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("line %d: section is missing a ]", lineNum)
+			}
+			section = strings.TrimSpace(line[1:end])
+			continue
+		}
+		key, value, err := parseAssignment(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		if section != "" {
+			key = section + "." + key
+		}
+		values[key] = value
+	}
+	return values, scanner.Err()
+}
+
+// Not a command
+// Sets the variables in a .env file that aren't already set in the environment
+// A missing .env file is fine, since hosts usually set the variables themselves
+func loadDotEnv(path string) (error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, err := parseAssignment(strings.TrimPrefix(line, "export "))
+		if err != nil {
+			return fmt.Errorf("%s line %d: %w", path, lineNum, err)
+		}
+		if _, set := os.LookupEnv(key); !set {
+			os.Setenv(key, value)
+		}
+	}
+	return scanner.Err()
+}
+
+// Helper to parse a KEY = "value" line, shared by .env and TOML files
+// Values can be quoted or bare, and anything after a # outside the quotes is a comment
+func parseAssignment(line string) (string, string, error) {
+	key, value, found := strings.Cut(line, "=")
+	if !found {
+		return "", "", errors.New("expected key = value")
+	}
+	key = strings.TrimSpace(key)
+	value = strings.TrimSpace(value)
+	if key == "" {
+		return "", "", errors.New("missing key before =")
+	}
+
+	if value != "" && (value[0] == '"' || value[0] == '\'') {
+		end := strings.IndexByte(value[1:], value[0])
+		if end == -1 {
+			return "", "", fmt.Errorf("%s is missing a closing quote", key)
+		}
+		return key, value[1:end+1], nil
+	}
+	if comment := strings.Index(value, "#"); comment != -1 {
+		value = strings.TrimSpace(value[:comment])
+	}
+	return key, value, nil
+}
@@ -0,0 +1,182 @@
+package database
+
+import (
+	"context"
+	"strconv"
+	"time"
+	"mary-bot/logging"
+	"mary-bot/metrics"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Economy events that can unlock achievements
+const (
+	eventMarried    = "married"
+	eventRob        = "rob"
+	eventLotteryWin = "lottery_win"
+	eventItem       = "item" // The user got an item from the shop, another user, the market or a trade
+	eventDaily      = "daily"
+)
+
+type Achievement struct {
+	ID          string
+	Emoji       string
+	Name        string
+	Description string
+	Event       string          // Only checked when this event happens
+	Unlocked    func(User) bool // Whether the user has earned it, given their document after the event
+}
+
+// An achievement a user has unlocked, stored in their "achievements" array
+type UnlockedAchievement struct {
+	ID         string    `bson:"id"`
+	UnlockedAt time.Time `bson:"unlocked_at"`
+}
+
+// Define the achievements
+var achievements = []Achievement{
+	{"married", "💍", "Happily Ever After", "Get married with a ring.", eventMarried, func(user User) bool {
+		return user.MarriedTo != 0
+	}},
+	{"master_thief", "🦹", "Master Thief", "Rob someone successfully 10 times.", eventRob, func(user User) bool {
+		return user.RobSuccesses >= 10
+	}},
+	{"jackpot", "🎰", "Jackpot", "Win 5X on the lottery.", eventLotteryWin, func(user User) bool {
+		return true
+	}},
+	{"car_owner", "🚗", "Road Trip", "Own a car.", eventItem, func(user User) bool {
+		for _, item := range user.Inventory {
+			if item.Name == "car" && item.Quantity > 0 {
+				return true
+			}
+		}
+		return false
+	}},
+	{"dedicated", "📅", "Dedicated", "Claim your daily 30 days in a row.", eventDaily, func(user User) bool {
+		return user.DailyStreak >= 30
+	}},
+}
+
+// An achievement a user just unlocked
+type Unlock struct {
+	UserID      int
+	Achievement Achievement
+}
+
+// An achievement and whether the user has it, for the achievements page
+type AchievementProgress struct {
+	Achievement Achievement
+	Unlocked    bool
+	UnlockedAt  time.Time
+}
+
+// Not a command
+// Checks the achievements that listen for event and unlocks any the user has now earned
+// Returns the ones that were unlocked, if any
+// Errors are only logged because an achievement failing shouldn't undo what the user just did
+func checkAchievements(ctx context.Context, userCollection *mongo.Collection, guildID int, userID int, event string) ([]Unlock) {
+	var user User
+	err := userCollection.FindOne(ctx, bson.D{
+		{Key: "user_id", Value: userID},
+		{Key: "guild_id", Value: guildID},
+	}).Decode(&user)
+	if err != nil {
+		logging.Error("Error occurred while checking achievements!", "guild", guildID, "user", userID, "event", event, "err", err)
+		metrics.ErrorsTotal.Inc("achievements")
+		return nil
+	}
+
+	unlocks := []Unlock{}
+	for _, achievement := range achievements {
+		if achievement.Event != event || !achievement.Unlocked(user) {
+			continue
+		}
+
+		// Only pushes the achievement if the user doesn't already have it
+		result, err := userCollection.UpdateOne(
+			ctx,
+			bson.D{
+				{Key: "user_id", Value: userID},
+				{Key: "guild_id", Value: guildID},
+				{Key: "achievements.id", Value: bson.D{{Key: "$ne", Value: achievement.ID}}},
+			},
+			bson.D{
+				{Key: "$push", Value: bson.D{
+					{Key: "achievements", Value: UnlockedAchievement{ID: achievement.ID, UnlockedAt: time.Now()}},
+				}},
+			},
+		)
+		if err != nil {
+			logging.Error("Error occurred while unlocking achievement!", "guild", guildID, "user", userID, "achievement", achievement.ID, "err", err)
+			metrics.ErrorsTotal.Inc("achievements")
+			continue
+		}
+		if result.ModifiedCount > 0 {
+			unlocks = append(unlocks, Unlock{UserID: userID, Achievement: achievement})
+		}
+	}
+	return unlocks
+}
+
+// Helper to get the emojis of a user's unlocked achievements for their profile
+func badges(unlocked []UnlockedAchievement) ([]string) {
+	has := map[string]bool{}
+	for _, achievement := range unlocked {
+		has[achievement.ID] = true
+	}
+	emojis := []string{}
+	for _, achievement := range achievements {
+		if has[achievement.ID] {
+			emojis = append(emojis, achievement.Emoji)
+		}
+	}
+	return emojis
+}
+
+// mary achievements
+// Every achievement in order, with whether the user has unlocked it
+func Achievements(mongoURI string, guildID int, guildName string, userID int, userName string) ([]AchievementProgress, error) {
+	// Connect to MongoDB
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return nil, dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return nil, dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	// Check if user exists in database
+	err = IsPlaying(ctx, client, guildID, guildName, userID, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	var user User
+	err = client.Database(strconv.Itoa(guildID)).Collection("Users").FindOne(ctx, bson.D{
+		{Key: "user_id", Value: userID},
+		{Key: "guild_id", Value: guildID},
+	}).Decode(&user)
+	if err != nil {
+		return nil, dbError("finding user in database", err)
+	}
+	unlockedAt := map[string]time.Time{}
+	for _, achievement := range user.Achievements {
+		unlockedAt[achievement.ID] = achievement.UnlockedAt
+	}
+
+	progress := []AchievementProgress{}
+	for _, achievement := range achievements {
+		at, ok := unlockedAt[achievement.ID]
+		progress = append(progress, AchievementProgress{Achievement: achievement, Unlocked: ok, UnlockedAt: at})
+	}
+	return progress, nil
+}
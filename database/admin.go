@@ -0,0 +1,121 @@
+package database
+
+import (
+	"context"
+	"strconv"
+	"time"
+	"mary-bot/logging"
+	"mary-bot/metrics"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Offline administration for the command line, see the cli package
+// None of these check permissions, whoever has the MongoDB URI can already do anything
+
+// mary-bot user show [guild] [user]
+func FindUser(mongoURI string, guildID int, userID int) (User, error) {
+	// Connect to MongoDB
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return User{}, dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return User{}, dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	var user User
+	err = client.Database(strconv.Itoa(guildID)).Collection("Users").FindOne(ctx, bson.D{
+		{Key: "user_id", Value: userID},
+		{Key: "guild_id", Value: guildID},
+	}).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		return User{}, ErrNotPlaying
+	} else if err != nil {
+		return User{}, dbError("finding user in database", err)
+	}
+	return user, nil
+}
+
+// mary-bot user set-balance [guild] [user] [balance]
+// Returns the balance the user had before
+func SetBalance(mongoURI string, guildID int, userID int, balance int64) (int64, error) {
+	if balance < 0 {
+		return 0, ErrNotPositive
+	}
+
+	// Connect to MongoDB
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return 0, dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return 0, dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	var previous User
+	err = client.Database(strconv.Itoa(guildID)).Collection("Users").FindOneAndUpdate(
+		ctx,
+		bson.D{
+			{Key: "user_id", Value: userID},
+			{Key: "guild_id", Value: guildID},
+		},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "balance", Value: balance}}}},
+	).Decode(&previous)
+	if err == mongo.ErrNoDocuments {
+		return 0, ErrNotPlaying
+	} else if err != nil {
+		return 0, dbError("updating database", err)
+	}
+
+	// Count the difference like any other way coins come and go
+	if balance > previous.Balance {
+		metrics.CoinsMinted.Add("admin", float64(balance - previous.Balance))
+	} else {
+		metrics.CoinsBurned.Add("admin", float64(previous.Balance - balance))
+	}
+	logging.Info("Set balance from the command line", "guild", guildID, "user", userID, "previous", previous.Balance, "balance", balance)
+	return previous.Balance, nil
+}
+
+// mary-bot guild reset [guild]
+// Drops the guild's whole database: users, settings, prices, the market and everything else
+func ResetGuild(mongoURI string, guildID int) (error) {
+	// Connect to MongoDB
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	err = client.Database(strconv.Itoa(guildID)).Drop(ctx)
+	if err != nil {
+		return dbError("dropping database", err)
+	}
+	logging.Warn("Reset guild from the command line", "guild", guildID)
+	return nil
+}
@@ -0,0 +1,22 @@
+package database
+
+// Things worth announcing that happened along the way, like achievements and level ups
+// Embedded in the result of every command that can cause them
+type Announcements struct {
+	Unlocks  []Unlock
+	LevelUps []LevelUp
+}
+
+// Not a command
+// Adds achievements that were just unlocked
+func (announcements *Announcements) unlocked(unlocks []Unlock) {
+	announcements.Unlocks = append(announcements.Unlocks, unlocks...)
+}
+
+// Not a command
+// Adds a level up, if there was one
+func (announcements *Announcements) levelledUp(levelUp *LevelUp) {
+	if levelUp != nil {
+		announcements.LevelUps = append(announcements.LevelUps, *levelUp)
+	}
+}
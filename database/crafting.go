@@ -0,0 +1,203 @@
+package database
+
+import (
+	"context"
+	"strconv"
+	"time"
+	"mary-bot/metrics"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type Ingredient struct {
+	Name     string // Inventory name of the item, e.g. "gun"
+	Quantity int
+}
+
+type Recipe struct {
+	Product     string // Inventory name of the crafted item
+	Quantity    int    // How many are made per craft
+	Ingredients []Ingredient
+}
+
+// Items that can't be bought and only come from crafting
+// Price is what they're worth when sold back (at 50% like everything else)
+var craftedItems = []ShopItem{
+	{"🎯 Crossbow", 3000, "A bow with gun parts bolted on. Its bolts go straight through shields!", 8, 0},
+	{"🎁 Giftbox", 600, "Ten chocolates wrapped up nicely. Give it to someone special!", 0, 0},
+}
+
+// Define the crafting recipes
+var recipes = []Recipe{
+	{"crossbow", 1, []Ingredient{{"bow", 1}, {"gun", 1}}},
+	{"giftbox", 1, []Ingredient{{"chocolate", 10}}},
+}
+
+// Finds the recipe for a product by its inventory name
+func findRecipe(product string) (Recipe, bool) {
+	for _, recipe := range recipes {
+		if recipe.Product == itemKey(product) {
+			return recipe, true
+		}
+	}
+	return Recipe{}, false
+}
+
+// What was crafted, and what it used up
+type CraftResult struct {
+	Product  string // Inventory name of the crafted item
+	Quantity int
+	Used     []Ingredient
+	Announcements
+}
+
+// Helper to turn an inventory name into its display name, e.g. "gun" -> "🔫 Gun"
+func ItemDisplayName(name string) (string) {
+	shopItem, ok := FindShopItem(name)
+	if !ok {
+		return name
+	}
+	return shopItem.Name
+}
+
+// mary recipes
+func Recipes() ([]Recipe) {
+	return recipes
+}
+
+// mary craft [item] [amount]
+func Craft(mongoURI string, guildID int, guildName string, userID int, userName string, item string, amount int) (CraftResult, error) {
+	// Check if the recipe exists
+	recipe, ok := findRecipe(item)
+	if !ok {
+		return CraftResult{}, ErrNoRecipe
+	}
+	if amount < 1 {
+		return CraftResult{}, ErrNotPositive
+	}
+
+	// Connect to MongoDB
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return CraftResult{}, dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return CraftResult{}, dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	// Check if user exists in database
+	err = IsPlaying(ctx, client, guildID, guildName, userID, userName)
+	if err != nil {
+		return CraftResult{}, err
+	}
+
+	// Jailed users are locked out of the economy until they're released or pay bail
+	err = IsJailed(ctx, client, guildID, userID)
+	if err != nil {
+		return CraftResult{}, err
+	}
+
+	userCollection := client.Database(strconv.Itoa(guildID)).Collection("Users")
+	userFilter := bson.D{
+		{Key: "user_id", Value: userID},
+		{Key: "guild_id", Value: guildID},
+	}
+
+	// Make sure the product has a slot in the inventory so that it can be incremented in the same update
+	// This will not run if the user already has the item in their inventory because of the $not operator
+	_, err = userCollection.UpdateOne(
+		ctx,
+		append(userFilter, bson.E{Key: "inventory", Value: bson.D{
+			{Key: "$not", Value: bson.D{
+				{Key: "$elemMatch", Value: bson.D{
+					{Key: "name", Value: recipe.Product},
+				}},
+			}},
+		}}),
+		bson.D{
+			{Key: "$push", Value: bson.D{
+				{Key: "inventory", Value: Item{Name: recipe.Product, Quantity: 0}},
+			}},
+		},
+	)
+	if err != nil {
+		return CraftResult{}, dbError("updating database", err)
+	}
+
+	// Consume the ingredients and grant the product in one update
+	// The filter only matches if the user has enough of every ingredient, so either everything happens or nothing does
+	hasIngredients := bson.A{}
+	decrements := bson.D{}
+	arrayFilters := []interface{}{}
+	needed := []Ingredient{}
+	for i, ingredient := range recipe.Ingredients {
+		hasIngredients = append(hasIngredients, bson.D{
+			{Key: "$elemMatch", Value: bson.D{
+				{Key: "name", Value: ingredient.Name},
+				{Key: "quantity", Value: bson.D{{Key: "$gte", Value: ingredient.Quantity * amount}}},
+				{Key: "broken", Value: bson.D{{Key: "$ne", Value: true}}}, // Broken items have to be repaired before they can be crafted with
+			}},
+		})
+		decrements = append(decrements, bson.E{Key: "inventory.$[i" + strconv.Itoa(i) + "].quantity", Value: -ingredient.Quantity * amount})
+		arrayFilters = append(arrayFilters, bson.D{{Key: "i" + strconv.Itoa(i) + ".name", Value: ingredient.Name}})
+		needed = append(needed, Ingredient{Name: ingredient.Name, Quantity: ingredient.Quantity * amount})
+	}
+	decrements = append(decrements, bson.E{Key: "inventory.$[product].quantity", Value: recipe.Quantity * amount})
+	arrayFilters = append(arrayFilters, bson.D{{Key: "product.name", Value: recipe.Product}})
+
+	result, err := userCollection.UpdateOne(
+		ctx,
+		append(userFilter, bson.E{Key: "inventory", Value: bson.D{{Key: "$all", Value: hasIngredients}}}),
+		bson.D{
+			{Key: "$inc", Value: decrements},
+		},
+		options.Update().SetArrayFilters(options.ArrayFilters{Filters: arrayFilters}),
+	)
+	if err != nil {
+		return CraftResult{}, dbError("updating database", err)
+	}
+
+	// Clear out anything that's been used up (including the empty product slot if crafting failed)
+	_, err = userCollection.UpdateOne(
+		ctx,
+		userFilter,
+		bson.D{
+			{Key: "$pull", Value: bson.D{
+				{Key: "inventory", Value: bson.D{
+					{Key: "quantity", Value: bson.D{{Key: "$lte", Value: 0}}},
+				}},
+			}},
+		},
+	)
+	if err != nil {
+		return CraftResult{}, dbError("updating database", err)
+	}
+
+	if result.MatchedCount == 0 {
+		// Say which ingredient is broken, if that's why
+		var user User // User struct defined in items.go
+		err = userCollection.FindOne(ctx, userFilter).Decode(&user)
+		if err != nil {
+			return CraftResult{}, dbError("finding user in database", err)
+		}
+		for _, ingredient := range recipe.Ingredients {
+			for _, item := range user.Inventory {
+				if item.Name == ingredient.Name && item.Broken {
+					return CraftResult{}, ErrItemBroken{Item: item.Name}
+				}
+			}
+		}
+		return CraftResult{}, ErrMissingIngredients{Needed: needed}
+	}
+	crafted := CraftResult{Product: recipe.Product, Quantity: recipe.Quantity * amount, Used: needed}
+	crafted.levelledUp(grantXP(ctx, userCollection, guildID, userID, xpPerAction))
+	return crafted, nil
+}
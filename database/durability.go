@@ -0,0 +1,177 @@
+package database
+
+import (
+	"context"
+	"strconv"
+	"time"
+	"mary-bot/metrics"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// A full repair costs a quarter of the item's shop price
+const repairCostDivisor = 4
+
+// Not a command
+// The durability of the item in hand, items that have never been used start out new
+func itemDurability(item Item) (int) {
+	shopItem, _ := FindShopItem(item.Name)
+	if item.Broken {
+		return 0
+	} else if item.Durability == 0 {
+		return shopItem.MaxDurability
+	}
+	return item.Durability
+}
+
+// Not a command
+// Whether the item in hand has been used since it was new or last repaired
+// Only the one in hand keeps track of its durability, so a worn one can't change hands without coming out as new
+func itemWorn(item Item) (bool) {
+	shopItem, _ := FindShopItem(item.Name)
+	return itemDurability(item) < shopItem.MaxDurability
+}
+
+// Not a command
+// If the item in hand is broken but the user has spares, throw the broken one away and pull out a new one
+func swapBrokenItem(inventory []Item, index int) ([]Item) {
+	if inventory[index].Broken && inventory[index].Quantity > 1 {
+		shopItem, _ := FindShopItem(inventory[index].Name)
+		inventory[index].Quantity -= 1
+		inventory[index].Durability = shopItem.MaxDurability
+		inventory[index].Broken = false
+	}
+	return inventory
+}
+
+// Not a command
+// Uses up one use of the item at index
+// Single-use items lose one from their quantity, durable items lose durability and break when it runs out
+func wearItem(inventory []Item, index int) ([]Item) {
+	shopItem, _ := FindShopItem(inventory[index].Name)
+	if shopItem.MaxDurability == 0 {
+		inventory[index].Quantity -= 1
+		if inventory[index].Quantity <= 0 { // If the user has no more of the item, remove it from their inventory
+			inventory = append(inventory[:index], inventory[index+1:]...)
+		}
+		return inventory
+	}
+
+	inventory[index].Durability = itemDurability(inventory[index]) - 1
+	if inventory[index].Durability <= 0 {
+		if inventory[index].Quantity > 1 {
+			// Throw the broken one away and pull out a spare
+			inventory[index].Quantity -= 1
+			inventory[index].Durability = shopItem.MaxDurability
+		} else {
+			// The last one stays in the inventory until it's repaired
+			inventory[index].Durability = 0
+			inventory[index].Broken = true
+		}
+	}
+	return inventory
+}
+
+// mary repair [item]
+// Returns what the repair cost (or would have cost, if they couldn't pay it)
+func Repair(mongoURI string, guildID int, guildName string, userID int, userName string, item string) (int64, error) {
+	// Connect to MongoDB
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return 0, dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return 0, dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	// Check if user exists in database
+	err = IsPlaying(ctx, client, guildID, guildName, userID, userName)
+	if err != nil {
+		return 0, err
+	}
+
+	// Jailed users are locked out of the economy until they're released or pay bail
+	err = IsJailed(ctx, client, guildID, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Check if the item exists and can be repaired
+	shopItem, ok := FindShopItem(item)
+	if !ok {
+		return 0, ErrNoSuchItem
+	}
+	if shopItem.MaxDurability == 0 {
+		return 0, ErrCantRepair
+	}
+
+	// Get user from database
+	userCollection := client.Database(strconv.Itoa(guildID)).Collection("Users")
+	filter := bson.M{"guild_id": guildID, "user_id": userID}
+	var user User // User struct defined in items.go
+	err = userCollection.FindOne(ctx, filter).Decode(&user)
+	if err != nil {
+		return 0, dbError("finding user in database", err)
+	}
+
+	// Check if user has the item in their inventory
+	itemIndex := -1
+	for index, it := range user.Inventory {
+		if it.Name == item {
+			itemIndex = index
+		}
+	}
+	if itemIndex == -1 {
+		return 0, ErrItemNotFound
+	}
+
+	// The cost scales with how worn down the item is
+	durability := itemDurability(user.Inventory[itemIndex])
+	if durability == shopItem.MaxDurability {
+		return 0, ErrNoRepairNeeded
+	}
+	cost := int64(shopItem.Price * (shopItem.MaxDurability - durability) / (shopItem.MaxDurability * repairCostDivisor))
+	if cost < 1 {
+		cost = 1
+	}
+	if user.Balance < cost {
+		return 0, ErrCantAfford{Cost: cost, Balance: user.Balance}
+	}
+
+	// Restore the item and charge the user, as long as they still have the coins
+	result, err := userCollection.UpdateOne(
+		ctx,
+		bson.D{
+			{Key: "user_id", Value: userID},
+			{Key: "guild_id", Value: guildID},
+			{Key: "balance", Value: bson.D{{Key: "$gte", Value: cost}}},
+			{Key: "inventory.name", Value: item},
+		},
+		bson.D{
+			{Key: "$inc", Value: bson.D{
+				{Key: "balance", Value: -cost},
+			}},
+			{Key: "$set", Value: bson.D{
+				{Key: "inventory.$.durability", Value: shopItem.MaxDurability},
+				{Key: "inventory.$.broken", Value: false},
+			}},
+		},
+	)
+	if err != nil {
+		return 0, dbError("updating database", err)
+	} else if result.MatchedCount == 0 {
+		// Their balance dropped since it was checked
+		return cost, ErrInsufficientFunds
+	}
+	metrics.CoinsBurned.Add("repair", float64(cost))
+
+	return cost, nil
+}
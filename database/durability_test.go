@@ -0,0 +1,71 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWearItem(t *testing.T) {
+	tests := []struct {
+		name      string
+		inventory []Item
+		index     int
+		want      []Item
+	}{
+		{"single-use", []Item{{Name: "chocolate", Quantity: 2}}, 0,
+			[]Item{{Name: "chocolate", Quantity: 1}}},
+		{"last single-use", []Item{{Name: "ring", Quantity: 1}, {Name: "chocolate", Quantity: 1}}, 1,
+			[]Item{{Name: "ring", Quantity: 1}}},
+		{"new", []Item{{Name: "gun", Quantity: 1}}, 0,
+			[]Item{{Name: "gun", Quantity: 1, Durability: 4}}},
+		{"worn", []Item{{Name: "bow", Quantity: 1, Durability: 2}}, 0,
+			[]Item{{Name: "bow", Quantity: 1, Durability: 1}}},
+		{"breaks with a spare", []Item{{Name: "bow", Quantity: 2, Durability: 1}}, 0,
+			[]Item{{Name: "bow", Quantity: 1, Durability: 3}}},
+		{"breaks", []Item{{Name: "bow", Quantity: 1, Durability: 1}}, 0,
+			[]Item{{Name: "bow", Quantity: 1, Durability: 0, Broken: true}}},
+	}
+	for _, test := range tests {
+		if got := wearItem(test.inventory, test.index); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: wearItem() = %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestSwapBrokenItem(t *testing.T) {
+	tests := []struct {
+		name      string
+		inventory []Item
+		want      []Item
+	}{
+		{"not broken", []Item{{Name: "gun", Quantity: 2, Durability: 3}},
+			[]Item{{Name: "gun", Quantity: 2, Durability: 3}}},
+		{"no spare", []Item{{Name: "gun", Quantity: 1, Broken: true}},
+			[]Item{{Name: "gun", Quantity: 1, Broken: true}}},
+		{"spare", []Item{{Name: "gun", Quantity: 3, Broken: true}},
+			[]Item{{Name: "gun", Quantity: 2, Durability: 5}}},
+	}
+	for _, test := range tests {
+		if got := swapBrokenItem(test.inventory, 0); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: swapBrokenItem() = %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestItemWorn(t *testing.T) {
+	tests := []struct {
+		item Item
+		want bool
+	}{
+		{Item{Name: "gun", Quantity: 1}, false},
+		{Item{Name: "gun", Quantity: 1, Durability: 5}, false},
+		{Item{Name: "gun", Quantity: 1, Durability: 4}, true},
+		{Item{Name: "gun", Quantity: 1, Broken: true}, true},
+		{Item{Name: "chocolate", Quantity: 3}, false},
+	}
+	for _, test := range tests {
+		if got := itemWorn(test.item); got != test.want {
+			t.Errorf("itemWorn(%+v) = %v, want %v", test.item, got, test.want)
+		}
+	}
+}
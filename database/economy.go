@@ -2,145 +2,136 @@ package database
 
 import (
 	"context"
-	"fmt"
 	"math/rand"
 	"strconv"
-	"strings"
 	"time"
+	"mary-bot/config"
+	"mary-bot/metrics"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options" 
 	"go.mongodb.org/mongo-driver/bson"
 )
 
-// Not a command
-// A helper function accessible everywhere that checks if the user is in the database
-// If they're not, it adds them to the database
-func IsPlaying(ctx context.Context, client *mongo.Client, guildID int, guildName string, userID int, userName string) (string) {
-	// If database for server doesn't exist, create it
-	serverDatabase := client.Database(strconv.Itoa(guildID))
-	userCollection := serverDatabase.Collection("Users")
-
-	// Check if user exists in database
-	collectionResult, err := userCollection.FindOne(
-		ctx,
-		bson.D{
-			{Key: "user_id", Value: userID},
-			{Key: "guild_id", Value: guildID},
-		},
-	).DecodeBytes()
-	_ = collectionResult // Unused variable
-	if err != nil {
-		// If user doesn't exist, create them
-		if err == mongo.ErrNoDocuments {
-			// Insert user into database
-			result, err := userCollection.InsertOne(
-				ctx,
-				bson.D{
-					{Key: "user_id", Value: userID},
-					{Key: "user_name", Value: userName},
-					{Key: "guild_id", Value: guildID},
-					{Key: "guild_name", Value: guildName},
-					{Key: "balance", Value: int64(0)}, // Enter balance as int64 value
-					{Key: "last_daily", Value: time.Now().AddDate(0, 0, -1)},
-					{Key: "last_beg", Value: time.Now().AddDate(0, 0, -1)},
-					{Key: "last_rob", Value: time.Now().AddDate(0, 0, -1)},
-					{Key: "last_gamble", Value: time.Now().AddDate(0, 0, -1)},
-					{Key: "last_trivia", Value: time.Now().AddDate(0, 0, -1)},
-				},
-			)
-			if err != nil {
-				return "Error occurred while inserting to database! " + strings.Title(err.Error())
-			}
-			fmt.Printf("Inserted user %s into database with ID %s\n", userName, result.InsertedID)
-		} else {
-			return "Error occurred while selecting from database! " + strings.Title(err.Error())
-		}
-	}
-	return ""
+// Everything shown on a user's profile
+type Profile struct {
+	UserName   string
+	ServerName string
+	Balance    int64
+	NetWorth   int64 // Balance plus what their items are worth
+	Rank       int   // Position on the server's net worth leaderboard
+	Players    int   // Number of users on that leaderboard
+	Level      int
+	Spouse     string // "" if they're not married
+	Badges     []string // Emojis of their unlocked achievements
+	Timers     []Timer
+	JailedFor  time.Duration // 0 if they're not in jail
 }
 
 // mary profile
-// This is not integrated into Economy because it returns multiple values
-func GetProfile(mongoURI string, guildID int, guildName string, userID int, userName string) (string, int64, string, int, string) {
+// This is not integrated into Economy because it returns a whole profile
+func GetProfile(mongoURI string, guildID int, guildName string, userID int, userName string) (Profile, error) {
 	// Connect to MongoDB
-	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI))
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
 	if err != nil {
-		fmt.Printf("Error occurred creating MongoDB client! %s\n", err)
-		return "Error occurred creating MongoDB client! " + strings.Title(err.Error()), 0, "", 0, ""
+		return Profile{}, dbError("creating MongoDB client", err)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
 	defer cancel() // Fix for memory leak
 	err = client.Connect(ctx)
 	if err != nil {
-		fmt.Printf("Error occurred while connecting to database! %s\n", err)
-		return "Error occurred while connecting to database! " + strings.Title(err.Error()), 0, "", 0, ""
+		return Profile{}, dbError("connecting to database", err)
 	}
 
 	// Disconnect from database
 	defer client.Disconnect(ctx) // Occurs as last line of main() function
 
 	// Check if user is playing
-	res := IsPlaying(ctx, client, guildID, guildName, userID, userName)
-	if res != "" {
-		return res, 0, "", 0, ""
+	err = IsPlaying(ctx, client, guildID, guildName, userID, userName)
+	if err != nil {
+		return Profile{}, err
 	}
 
 	// Find user in database
 	serverDatabase := client.Database(strconv.Itoa(guildID))
 	userCollection := serverDatabase.Collection("Users")
-	collectionResult, err := userCollection.FindOne(
+	var user User
+	err = userCollection.FindOne(
 		ctx,
 		bson.D{
 			{Key: "user_id", Value: userID},
 			{Key: "guild_id", Value: guildID},
 		},
-	).DecodeBytes()
-
-	// This is where the actual profile command starts
-	// UserID and GuildID are already known
-	user := collectionResult.Lookup("user_name").StringValue()
+	).Decode(&user)
+	if err != nil {
+		return Profile{}, dbError("selecting from database", err)
+	}
+	settings, err := guildSettings(ctx, serverDatabase)
+	if err != nil {
+		return Profile{}, err
+	}
 
-	bal := collectionResult.Lookup("balance").Int64()
-	serverName := collectionResult.Lookup("guild_name").StringValue()
-	lastDaily := collectionResult.Lookup("last_daily").DateTime()
-	marriedTo := collectionResult.Lookup("married_to").Int64()
-	spouse := "None"
+	profile := Profile{
+		UserName: user.UserName,
+		ServerName: user.GuildName,
+		Balance: user.Balance,
+		Level: user.Level,
+		Badges: badges(user.Achievements),
+		Timers: userTimers(user, settings),
+		JailedFor: remaining(user.JailedUntil, 0), // Counts down to their release
+	}
 
 	// Find the userName of the user they're married to
-	if marriedTo != 0 {
-		collectionResult, err = userCollection.FindOne(
+	if user.MarriedTo != 0 {
+		var spouse User
+		err = userCollection.FindOne(
 			ctx,
 			bson.D{
-				{Key: "user_id", Value: marriedTo},
+				{Key: "user_id", Value: user.MarriedTo},
 				{Key: "guild_id", Value: guildID},
 			},
-		).DecodeBytes()
+		).Decode(&spouse)
 		if err != nil {
-			return "Error occurred while selecting from database! " + strings.Title(err.Error()), 0, "", 0, ""
+			return Profile{}, dbError("selecting from database", err)
 		}
-		spouse = collectionResult.Lookup("user_name").StringValue()
+		profile.Spouse = spouse.UserName
 	}
 
-	// Calculate the duration since lastDaily
-	durationSinceLastDaily := time.Since(time.Unix(lastDaily/1000, 0))
-
-	// Calculate the duration until nextDaily
-	durationUntilNextDaily := time.Hour - durationSinceLastDaily
-
-	// Convert the remaining duration to hours 
-	hoursUntilNextDaily := int(durationUntilNextDaily.Hours())
-
-	// If hoursUntilNextDaily is negative, set it to 0
-	if hoursUntilNextDaily < 0 {
-		hoursUntilNextDaily = 0
+	// Net worth and rank come from the net worth leaderboard
+	ranking, _ := findRanking("networth")
+	entry, err := leaderboardRank(ctx, userCollection, guildID, userID, ranking)
+	if err != nil {
+		return Profile{}, dbError("working out rank", err)
+	} else if entry == nil {
+		return Profile{}, ErrNotPlaying
 	}
+	players, err := userCollection.CountDocuments(ctx, bson.D{{Key: "guild_id", Value: guildID}})
+	if err != nil {
+		return Profile{}, dbError("selecting from database", err)
+	}
+	profile.NetWorth = entry.Score
+	profile.Rank = entry.Rank
+	profile.Players = int(players)
+
+	return profile, nil
+}
 
-	return user, bal, serverName, hoursUntilNextDaily, spouse
+// What happened after an economy command
+// Only the fields that make sense for the operation are filled in
+type EconomyResult struct {
+	Operation string // "bal", "daily", "beg", "gamble", "lottery", "slots" or "start"
+	UserID    int
+	Balance   int64 // The user's balance, for "bal"
+	Amount    int64 // Coins received from daily or beg, or paid out when a gamble is won
+	Bet       int64 // Coins put down on a gamble
+	Won       bool
+	Streak    int   // Days in a row the daily has been claimed
+	Created   bool  // The user was added by this command, for "start"
+	Announcements
 }
 
 // mary bal
-func bal(ctx context.Context, userCollection *mongo.Collection, guildID int, userID int, balance int) (string) {
+func bal(ctx context.Context, userCollection *mongo.Collection, guildID int, userID int, balance int) (EconomyResult, error) {
 	collectionResult, err := userCollection.FindOne(
 		ctx,
 		bson.D{
@@ -149,20 +140,18 @@ func bal(ctx context.Context, userCollection *mongo.Collection, guildID int, use
 		},
 	).DecodeBytes()
 	if err != nil {
-		fmt.Printf("Error occurred while selecting from database! %s\n", err)
-		return "Error occurred while selecting from database! " + strings.Title(err.Error())
+		return EconomyResult{}, dbError("selecting from database", err)
 	}
 	user := collectionResult.Lookup("user_name").StringValue()
 	bal := collectionResult.Lookup("balance").Int64()
 	if user == "" && bal == 0 {
-		return "That person is not currently playing the game!"
-	} else {
-		return "<@" + strconv.Itoa(userID) + ">, you have " + strconv.Itoa(int(bal)) + " coins."
+		return EconomyResult{}, ErrNotPlaying
 	}
+	return EconomyResult{Operation: "bal", UserID: userID, Balance: bal}, nil
 }
 
 // mary daily
-func daily(ctx context.Context, userCollection *mongo.Collection, guildID int, userID int, balance int) (string) {
+func daily(ctx context.Context, userCollection *mongo.Collection, guildID int, userID int, balance int, settings config.Config) (EconomyResult, error) {
 	// Check if daily has reset
 	collectionResult, err := userCollection.FindOne(
 		ctx,
@@ -172,16 +161,19 @@ func daily(ctx context.Context, userCollection *mongo.Collection, guildID int, u
 		},
 	).DecodeBytes()
 	if err != nil {
-		fmt.Printf("Error occurred while selecting from database! %s\n", err)
-		return "Error occurred while selecting from database! " + strings.Title(err.Error())
+		return EconomyResult{}, dbError("selecting from database", err)
+	}
+	lastDaily := collectionResult.Lookup("last_daily").Time()
+	if time.Since(lastDaily) < settings.Cooldowns.Daily {
+		return EconomyResult{}, ErrCooldown{Action: "daily", Remaining: remaining(lastDaily, settings.Cooldowns.Daily)}
 	}
-	lastDaily := collectionResult.Lookup("last_daily").DateTime()
-	if time.Now().Unix() - lastDaily/1000 < 86400 {	
-		waitTime := int(86400 - (time.Now().Unix() - lastDaily/1000))
-		hours := waitTime / 3600
-		minutes := (waitTime % 3600) / 60
-		seconds := waitTime % 60
-		return "<@" + strconv.Itoa(userID) + ">, you have already claimed your daily! Please wait " + strconv.Itoa(hours) + " hours, " + strconv.Itoa(minutes) + " minutes, and " + strconv.Itoa(seconds) + " seconds before claiming again."
+
+	// Claiming again within two days of the last one keeps the streak going, otherwise it starts over
+	streak := int32(1)
+	if time.Since(lastDaily) < 2 * settings.Cooldowns.Daily {
+		if previous, ok := collectionResult.Lookup("daily_streak").AsInt32OK(); ok {
+			streak = previous + 1
+		}
 	}
 	
 	result := userCollection.FindOneAndUpdate(
@@ -196,18 +188,22 @@ func daily(ctx context.Context, userCollection *mongo.Collection, guildID int, u
 			}},
 			{Key: "$set", Value: bson.D{
 				{Key: "last_daily", Value: time.Now()},
+				{Key: "daily_streak", Value: streak},
 			}},
 		},
 	)
 	if result.Err() != nil {
-		fmt.Printf("Error occurred while inserting to database! %s\n", result.Err().Error())
-		return "Error occurred while inserting to database! " + strings.Title(result.Err().Error())
-	} 
-	return "<@" + strconv.Itoa(userID) + ">, you have received your daily " + strconv.Itoa(balance) + " coins!"
+		return EconomyResult{}, dbError("inserting to database", result.Err())
 	}
+	metrics.CoinsMinted.Add("daily", float64(balance))
+	res := EconomyResult{Operation: "daily", UserID: userID, Amount: int64(balance), Streak: int(streak)}
+	res.unlocked(checkAchievements(ctx, userCollection, guildID, userID, eventDaily))
+	res.levelledUp(grantXP(ctx, userCollection, guildID, userID, xpPerAction))
+	return res, nil
+}
 
 // mary beg
-func beg(ctx context.Context, userCollection *mongo.Collection, guildID int, userID int, balance int) (string) {
+func beg(ctx context.Context, userCollection *mongo.Collection, guildID int, userID int, balance int, settings config.Config) (EconomyResult, error) {
 	// Check if beg has reset
 	collectionResult, err := userCollection.FindOne(
 		ctx,
@@ -217,14 +213,12 @@ func beg(ctx context.Context, userCollection *mongo.Collection, guildID int, use
 		},
 	).DecodeBytes()
 	if err != nil {
-		fmt.Printf("Error occurred while selecting from database! %s\n", err)
-		return "Error occurred while selecting from database! " + strings.Title(err.Error())
+		return EconomyResult{}, dbError("selecting from database", err)
 	}
-	lastBeg := collectionResult.Lookup("last_beg").DateTime()
-	// Wait one minute before begging again
-	if time.Now().Unix() - lastBeg/1000 < 60 {
-		waitTime := int(60 - (time.Now().Unix() - lastBeg/1000))
-		return "<@" + strconv.Itoa(userID) + ">, you have already begged! Please wait " + strconv.Itoa(waitTime) + " seconds before begging again."
+	lastBeg := collectionResult.Lookup("last_beg").Time()
+	// Wait for the beg cooldown before begging again
+	if time.Since(lastBeg) < settings.Cooldowns.Beg {
+		return EconomyResult{}, ErrCooldown{Action: "beg", Remaining: remaining(lastBeg, settings.Cooldowns.Beg)}
 	}
 	
 	result := userCollection.FindOneAndUpdate(
@@ -243,106 +237,91 @@ func beg(ctx context.Context, userCollection *mongo.Collection, guildID int, use
 		},
 	)
 	if result.Err() != nil {
-		fmt.Printf("Error occurred while inserting to database! %s\n", result.Err().Error())
-		return "Error occurred while inserting to database! " + strings.Title(result.Err().Error())
-	} 
-	return "<@" + strconv.Itoa(userID) + ">, you have received " + strconv.Itoa(balance) + " coins!"
+		return EconomyResult{}, dbError("inserting to database", result.Err())
+	}
+	metrics.CoinsMinted.Add("beg", float64(balance))
+	res := EconomyResult{Operation: "beg", UserID: userID, Amount: int64(balance)}
+	res.levelledUp(grantXP(ctx, userCollection, guildID, userID, xpPerAction))
+	return res, nil
 }
 
-func Economy(mongoURI string, guildID int, guildName string, userID int, userName string, operation string, balance int) (string) {
+func Economy(mongoURI string, guildID int, guildName string, userID int, userName string, operation string, balance int) (EconomyResult, error) {
 	// Return error if balance is negative
 	if balance < 0 {
-		return "Balance cannot be negative!"
+		return EconomyResult{}, ErrNotPositive
 	}
 
 	// Connect to MongoDB
-	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI))
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
 	if err != nil {
-		fmt.Printf("Error occurred creating MongoDB client! %s\n", err)
-		return "Error occurred creating MongoDB client! " + strings.Title(err.Error())
+		return EconomyResult{}, dbError("creating MongoDB client", err)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
 	defer cancel() // Fix for memory leak
 	err = client.Connect(ctx)
 	if err != nil {
-		fmt.Printf("Error occurred while connecting to database! %s\n", err)
-		return "Error occurred while connecting to database! " + strings.Title(err.Error())
+		return EconomyResult{}, dbError("connecting to database", err)
 	}
 
 	// Disconnect from database
 	defer client.Disconnect(ctx) // Occurs as last line of main() function
 
 	// Check if user exists in database
-	res := IsPlaying(ctx, client, guildID, guildName, userID, userName)
-	if res != "" {
-		return res
+	created, err := register(ctx, client, guildID, guildName, userID, userName)
+	if err != nil {
+		return EconomyResult{}, err
+	}
+
+	// Jailed users are locked out of the economy until they're released or pay bail
+	// They can still check balances
+	if operation != "bal" && operation != "start" {
+		err = IsJailed(ctx, client, guildID, userID)
+		if err != nil {
+			return EconomyResult{}, err
+		}
 	}
 
 	// If database for server doesn't exist, create it
 	serverDatabase := client.Database(strconv.Itoa(guildID))
 	userCollection := serverDatabase.Collection("Users")
 
+	// Payouts and cooldowns can be changed per server
+	settings, err := guildSettings(ctx, serverDatabase)
+	if err != nil {
+		return EconomyResult{}, err
+	}
+
 	switch operation {
 		case "bal":
-			res := bal(ctx, userCollection, guildID, userID, balance)
-			return res
+			return bal(ctx, userCollection, guildID, userID, balance)
 		
 		case "daily":
-			res := daily(ctx, userCollection, guildID, userID, balance)
-			return res
+			return daily(ctx, userCollection, guildID, userID, settings.Payouts.Daily, settings)
 		
 		case "beg":
-			// Generate random value between 1 and 10
+			// Generate random value between the server's minimum and maximum
 			rand.Seed(time.Now().UnixNano())
-			balance = rand.Intn(10) + 1
-			res := beg(ctx, userCollection, guildID, userID, balance)
-			return res
+			balance = settings.Payouts.BegMin + rand.Intn(settings.Payouts.BegMax - settings.Payouts.BegMin + 1)
+			return beg(ctx, userCollection, guildID, userID, balance, settings)
 		
 		case "gamble":
-			res := Gamble(ctx, userCollection, guildID, userID, balance)
-			return res
+			return Gamble(ctx, userCollection, guildID, userID, balance, settings)
 		
 		case "lottery":
-			res := Lottery(ctx, userCollection, guildID, userID, balance)
-			return res
+			return Lottery(ctx, userCollection, guildID, userID, settings.Payouts.LotteryBet, settings)
 		
 		case "slots":
-			res := Slots(ctx, userCollection, guildID, userID, balance)
-			return res
+			return Slots(ctx, userCollection, guildID, userID, settings.Payouts.SlotsBet, settings)
 		
-		case "insert":
-			opts := options.Update().SetUpsert(true)
-			collectionResult, err := userCollection.UpdateOne(
-				ctx,
-				bson.D{
-					{Key: "user_id", Value: userID},
-					{Key: "guild_id", Value: guildID},
-				},
-				bson.D{
-					{Key: "$set", Value: bson.D{
-						{Key: "user_id", Value: userID},
-						{Key: "user_name", Value: userName},
-						{Key: "guild_id", Value: guildID},
-						{Key: "guild_name", Value: guildName},
-						{Key: "balance", Value: int64(0)}, // Enter balance as int64 value
-						{Key: "last_daily", Value: time.Now().AddDate(0, 0, -1)},
-						{Key: "last_beg", Value: time.Now().AddDate(0, 0, -1)},
-						{Key: "last_rob", Value: time.Now().AddDate(0, 0, -1)},
-						{Key: "last_gamble", Value: time.Now().AddDate(0, 0, -1)},
-						{Key: "last_trivia", Value: time.Now().AddDate(0, 0, -1)},
-					}},
-				},
-				opts,
-			)
-			if err != nil {
-				fmt.Printf("Error occurred while inserting to database! %s\n", err)
-				return "Error occurred while inserting to database! " + strings.Title(err.Error())
-			} 
-			fmt.Println(collectionResult)
-			return "Inserted user into database!"
+		case "start":
+			// Registering already happened above, so this just says whether it was new
+			res, err := bal(ctx, userCollection, guildID, userID, balance)
+			res.Operation = "start"
+			res.Created = created
+			return res, err
 		
 		default: 
-			return "I'm sorry, I dont recognize that command."
+			return EconomyResult{}, ErrUnknownCommand
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,233 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"mary-bot/logging"
+	"mary-bot/metrics"
+)
+
+// Reasons a command can't go through
+// These never reach users as they are, the replies package turns them into messages
+var (
+	ErrNotPlaying        = errors.New("user isn't playing")
+	ErrInsufficientFunds = errors.New("not enough coins")
+	ErrItemNotFound      = errors.New("item isn't in the inventory")
+	ErrNotEnoughItems    = errors.New("not enough of the item")
+	ErrEmptyInventory    = errors.New("inventory is empty")
+	ErrNoSuchItem        = errors.New("item doesn't exist")
+	ErrNotPositive       = errors.New("amount isn't positive")
+	ErrSelfTarget        = errors.New("can't target yourself")
+	ErrUnknownCommand    = errors.New("unknown command")
+
+	// Marriage
+	ErrAlreadyMarried     = errors.New("already married")
+	ErrTargetMarried      = errors.New("target is married to someone else")
+	ErrNotMarried         = errors.New("not married")
+	ErrNotMarriedToTarget = errors.New("not married to target")
+
+	// Robbing and jail
+	ErrTooPoor   = errors.New("target is too poor to rob")
+	ErrNotJailed = errors.New("not in jail")
+
+	// Repairing and crafting
+	ErrCantRepair      = errors.New("item can't be repaired")
+	ErrNoRepairNeeded  = errors.New("item doesn't need repairing")
+	ErrNoRecipe        = errors.New("no recipe for item")
+
+	// Market
+	ErrMarketEmpty          = errors.New("market is empty")
+	ErrListingNotFound      = errors.New("listing not found")
+	ErrAuctionNotFound      = errors.New("auction not found")
+	ErrAlreadyHighestBidder = errors.New("already the highest bidder")
+	ErrBidRace              = errors.New("someone else bid first")
+	ErrCantCancel           = errors.New("listing can't be cancelled")
+
+	// Trades
+	ErrAlreadyTrading = errors.New("already in a trade")
+	ErrNoTrade        = errors.New("no open trade")
+	ErrTradeChanged   = errors.New("trade changed before it went through")
+
+	// Market purchases and trades run in transactions, which a standalone mongod doesn't support
+	ErrNoTransactions = errors.New("database doesn't support transactions")
+
+	// Global leaderboard
+	ErrAlreadyGlobal = errors.New("already on the global leaderboard")
+	ErrNotGlobal     = errors.New("not on the global leaderboard")
+
+	ErrNoSuchRanking      = errors.New("ranking doesn't exist")
+	ErrTriviaUnavailable  = errors.New("couldn't get a trivia question")
+	ErrNoSuchSetting      = errors.New("setting doesn't exist")
+)
+
+// Returned when a command is used again before its cooldown is up
+type ErrCooldown struct {
+	Action    string // "daily", "beg", "rob", "gamble", "trivia" or "use"
+	Remaining time.Duration
+}
+
+func (err ErrCooldown) Error() (string) {
+	return fmt.Sprintf("%s is on cooldown for %s", err.Action, err.Remaining)
+}
+
+// Returned when a jailed user tries to use the economy
+type ErrJailed struct {
+	Remaining time.Duration
+}
+
+func (err ErrJailed) Error() (string) {
+	return fmt.Sprintf("in jail for %s", err.Remaining)
+}
+
+// Returned when the item in hand is broken and there's no spare
+type ErrItemBroken struct {
+	Item string
+}
+
+func (err ErrItemBroken) Error() (string) {
+	return err.Item + " is broken"
+}
+
+// Returned when the last of an item would change hands while it's worn down
+// The spares can still be sold, traded or given, but the one in hand has to be repaired first
+type ErrItemWorn struct {
+	Item string
+}
+
+func (err ErrItemWorn) Error() (string) {
+	return err.Item + " is worn down"
+}
+
+// Returned when something costs more than the user has
+// errors.Is(err, ErrInsufficientFunds) is true for these too
+type ErrCantAfford struct {
+	Cost    int64
+	Balance int64
+}
+
+func (err ErrCantAfford) Error() (string) {
+	return fmt.Sprintf("costs %d but only has %d", err.Cost, err.Balance)
+}
+
+func (err ErrCantAfford) Unwrap() (error) {
+	return ErrInsufficientFunds
+}
+
+// Returned when an item needs a higher level than whoever would end up with it
+type ErrLevelTooLow struct {
+	Level  int
+	UserID int // Who would get the item when it isn't the user who ran the command, e.g. in give or trade
+}
+
+func (err ErrLevelTooLow) Error() (string) {
+	return fmt.Sprintf("needs level %d", err.Level)
+}
+
+// Returned when crafting without enough of every ingredient
+type ErrMissingIngredients struct {
+	Needed []Ingredient
+}
+
+func (err ErrMissingIngredients) Error() (string) {
+	return "missing ingredients"
+}
+
+// Returned when an auction is too short or too long
+type ErrAuctionLength struct {
+	Max time.Duration
+}
+
+func (err ErrAuctionLength) Error() (string) {
+	return fmt.Sprintf("auctions can last up to %s", err.Max)
+}
+
+// Returned when buying an auction or bidding on a fixed-price listing
+type ErrWrongListingType struct {
+	ListingID int
+	Auction   bool // Whether the listing is an auction
+}
+
+func (err ErrWrongListingType) Error() (string) {
+	return fmt.Sprintf("listing #%d is the wrong type", err.ListingID)
+}
+
+// Returned when buying or bidding on your own listing
+type ErrOwnListing struct {
+	ListingID int
+	Auction   bool
+}
+
+func (err ErrOwnListing) Error() (string) {
+	return fmt.Sprintf("listing #%d is your own", err.ListingID)
+}
+
+// Returned when a bid doesn't beat the highest one
+type ErrBidTooLow struct {
+	Minimum int64
+}
+
+func (err ErrBidTooLow) Error() (string) {
+	return fmt.Sprintf("bid must be at least %d", err.Minimum)
+}
+
+// Returned when the other user is already in a trade
+type ErrTargetTrading struct {
+	UserID int
+}
+
+func (err ErrTargetTrading) Error() (string) {
+	return fmt.Sprintf("user %d is already trading", err.UserID)
+}
+
+// Returned when one side of a trade no longer has what they offered
+type ErrTradeShort struct {
+	UserName string
+	Item     Item  // Empty if it's coins they're short of
+	Coins    int64
+}
+
+func (err ErrTradeShort) Error() (string) {
+	return err.UserName + " no longer has their offer"
+}
+
+// Returned when removing a level reward that doesn't exist
+type ErrNoLevelRole struct {
+	Level int
+}
+
+func (err ErrNoLevelRole) Error() (string) {
+	return fmt.Sprintf("no reward for level %d", err.Level)
+}
+
+// Returned when a server setting is given a value that doesn't parse or make sense
+type ErrInvalidSetting struct {
+	Key    string
+	Reason string
+}
+
+func (err ErrInvalidSetting) Error() (string) {
+	return err.Reason
+}
+
+// Wraps an error from MongoDB with what was being done at the time
+type ErrDatabase struct {
+	Op  string // e.g. "updating database"
+	Err error
+}
+
+func (err ErrDatabase) Error() (string) {
+	return err.Op + ": " + err.Err.Error()
+}
+
+func (err ErrDatabase) Unwrap() (error) {
+	return err.Err
+}
+
+// Not a command
+// Logs a MongoDB error and wraps it so it can be shown to the user
+func dbError(op string, err error) (error) {
+	logging.Error("Error occurred while " + op + "!", "op", op, "err", err)
+	metrics.ErrorsTotal.Inc("database")
+	return ErrDatabase{Op: op, Err: err}
+}
@@ -0,0 +1,376 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"mary-bot/logging"
+	"mary-bot/metrics"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// The version of the snapshot format written by Export
+// Bump it when the format changes, and keep reading the old ones in ParseSnapshot
+const snapshotVersion = 1
+
+// A backup of a guild's economy, from `mary export`
+type Snapshot struct {
+	Version    int            `json:"version"`
+	GuildID    int            `json:"guild_id"`
+	GuildName  string         `json:"guild_name"`
+	ExportedAt time.Time      `json:"exported_at"`
+	Users      []SnapshotUser `json:"users"`
+}
+
+// A user as they're written in a snapshot
+// Only what's needed to restore the economy: cooldowns, levels and stats start over
+type SnapshotUser struct {
+	UserID    int            `json:"user_id"`
+	UserName  string         `json:"user_name"`
+	Balance   int64          `json:"balance"`
+	MarriedTo int            `json:"married_to,omitempty"`
+	Inventory []SnapshotItem `json:"inventory"`
+}
+
+type SnapshotItem struct {
+	Name       string `json:"name"`
+	Quantity   int    `json:"quantity"`
+	Durability int    `json:"durability,omitempty"`
+	Broken     bool   `json:"broken,omitempty"`
+}
+
+// What an import did, or would do on a dry run
+type ImportResult struct {
+	DryRun  bool
+	Created int // Users that weren't in the guild yet
+	Updated int // Users whose balance, inventory and marriage were replaced
+	Unmarried int // Users outside the snapshot who were married to someone in it, and are now single
+}
+
+// Returned when a snapshot can't be imported, with every problem found
+type ErrInvalidSnapshot struct {
+	Problems []string
+}
+
+func (err ErrInvalidSnapshot) Error() (string) {
+	return "invalid snapshot: " + strings.Join(err.Problems, "; ")
+}
+
+// mary export -> every user in the guild with their balance, inventory and marriage
+func Export(mongoURI string, guildID int, guildName string) (Snapshot, error) {
+	// Connect to MongoDB
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return Snapshot{}, dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return Snapshot{}, dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	userCollection := client.Database(strconv.Itoa(guildID)).Collection("Users")
+	cursor, err := userCollection.Find(
+		ctx,
+		bson.D{{Key: "guild_id", Value: guildID}},
+		options.Find().SetSort(bson.D{{Key: "user_id", Value: 1}}),
+	)
+	if err != nil {
+		return Snapshot{}, dbError("selecting from database", err)
+	}
+	var users []User
+	err = cursor.All(ctx, &users)
+	if err != nil {
+		return Snapshot{}, dbError("decoding result", err)
+	}
+
+	// The command line doesn't know the guild's name, but every user has it
+	if guildName == "" && len(users) > 0 {
+		guildName = users[0].GuildName
+	}
+
+	snapshot := Snapshot{
+		Version: snapshotVersion,
+		GuildID: guildID,
+		GuildName: guildName,
+		ExportedAt: time.Now().UTC(),
+		Users: []SnapshotUser{},
+	}
+	for _, user := range users {
+		exported := SnapshotUser{
+			UserID: user.UserID,
+			UserName: user.UserName,
+			Balance: user.Balance,
+			MarriedTo: user.MarriedTo,
+			Inventory: []SnapshotItem{},
+		}
+		for _, item := range user.Inventory {
+			// Used up items stay in the inventory with a quantity of 0
+			if item.Quantity > 0 {
+				exported.Inventory = append(exported.Inventory, SnapshotItem{item.Name, item.Quantity, item.Durability, item.Broken})
+			}
+		}
+		snapshot.Users = append(snapshot.Users, exported)
+	}
+	logging.Info("Exported guild", "guild", guildID, "users", len(snapshot.Users))
+	return snapshot, nil
+}
+
+// Writes a snapshot as indented JSON, which keeps everything
+func (snapshot Snapshot) JSON() ([]byte, error) {
+	return json.MarshalIndent(snapshot, "", "  ")
+}
+
+// Writes a snapshot as CSV, one user per row, for spreadsheets
+// Inventories are written as name:quantity pairs separated by semicolons, e.g. "gun:1;chocolate:12"
+// Durability and broken items aren't kept, so use JSON for backups
+func (snapshot Snapshot) CSV() ([]byte, error) {
+	var out bytes.Buffer
+	writer := csv.NewWriter(&out)
+	writer.Write([]string{"user_id", "user_name", "balance", "married_to", "inventory"})
+	for _, user := range snapshot.Users {
+		items := []string{}
+		for _, item := range user.Inventory {
+			items = append(items, item.Name + ":" + strconv.Itoa(item.Quantity))
+		}
+		writer.Write([]string{
+			strconv.Itoa(user.UserID),
+			user.UserName,
+			strconv.FormatInt(user.Balance, 10),
+			strconv.Itoa(user.MarriedTo),
+			strings.Join(items, ";"),
+		})
+	}
+	writer.Flush()
+	return out.Bytes(), writer.Error()
+}
+
+// Reads a snapshot written by JSON() or CSV(), by file name, e.g. "economy.json"
+// The snapshot is validated, so anything returned without an error is safe to import
+func ParseSnapshot(fileName string, data []byte) (Snapshot, error) {
+	var snapshot Snapshot
+	var err error
+	switch {
+		case strings.HasSuffix(strings.ToLower(fileName), ".json"):
+			decoder := json.NewDecoder(bytes.NewReader(data))
+			decoder.DisallowUnknownFields() // Catches typos in hand-edited files
+			err = decoder.Decode(&snapshot)
+		case strings.HasSuffix(strings.ToLower(fileName), ".csv"):
+			snapshot, err = parseSnapshotCSV(data)
+		default:
+			return Snapshot{}, ErrInvalidSnapshot{Problems: []string{"the file must be .json or .csv"}}
+	}
+	if err != nil {
+		return Snapshot{}, ErrInvalidSnapshot{Problems: []string{err.Error()}}
+	}
+	return snapshot, snapshot.Validate()
+}
+
+// Not a command
+// Reads the rows written by CSV() back into a snapshot
+func parseSnapshotCSV(data []byte) (Snapshot, error) {
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	header := []string{"user_id", "user_name", "balance", "married_to", "inventory"}
+	if len(rows) == 0 || strings.Join(rows[0], ",") != strings.Join(header, ",") {
+		return Snapshot{}, fmt.Errorf("the first row must be %s", strings.Join(header, ","))
+	}
+
+	// CSV files don't say which version wrote them, so they're read as the current one
+	snapshot := Snapshot{Version: snapshotVersion, Users: []SnapshotUser{}}
+	for i, row := range rows[1:] {
+		line := i + 2
+		userID, err := strconv.Atoi(row[0])
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("row %d: user_id must be a number", line)
+		}
+		balance, err := strconv.ParseInt(row[2], 10, 64)
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("row %d: balance must be a number", line)
+		}
+		marriedTo, err := strconv.Atoi(row[3])
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("row %d: married_to must be a number", line)
+		}
+		user := SnapshotUser{UserID: userID, UserName: row[1], Balance: balance, MarriedTo: marriedTo, Inventory: []SnapshotItem{}}
+		for _, pair := range strings.Split(row[4], ";") {
+			if pair == "" {
+				continue
+			}
+			name, quantity, found := strings.Cut(pair, ":")
+			amount, err := strconv.Atoi(quantity)
+			if !found || err != nil {
+				return Snapshot{}, fmt.Errorf("row %d: inventory must be name:quantity pairs, got %q", line, pair)
+			}
+			user.Inventory = append(user.Inventory, SnapshotItem{Name: name, Quantity: amount})
+		}
+		snapshot.Users = append(snapshot.Users, user)
+	}
+	return snapshot, nil
+}
+
+// Checks that a snapshot can be imported as it is
+// Returns ErrInvalidSnapshot listing every problem, so a file can be fixed in one go
+func (snapshot Snapshot) Validate() (error) {
+	problems := []string{}
+	if snapshot.Version < 1 || snapshot.Version > snapshotVersion {
+		problems = append(problems, fmt.Sprintf("version %d isn't supported", snapshot.Version))
+	}
+
+	users := map[int]SnapshotUser{}
+	for _, user := range snapshot.Users {
+		if user.UserID <= 0 {
+			problems = append(problems, fmt.Sprintf("user_id %d isn't a Discord user ID", user.UserID))
+		}
+		if _, ok := users[user.UserID]; ok {
+			problems = append(problems, fmt.Sprintf("user %d is in the snapshot twice", user.UserID))
+		}
+		users[user.UserID] = user
+		if user.Balance < 0 {
+			problems = append(problems, fmt.Sprintf("user %d has a negative balance", user.UserID))
+		}
+		for _, item := range user.Inventory {
+			if _, ok := FindShopItem(item.Name); !ok || itemKey(item.Name) != item.Name {
+				problems = append(problems, fmt.Sprintf("user %d has %q, which isn't an item", user.UserID, item.Name))
+			}
+			if item.Quantity <= 0 || item.Durability < 0 {
+				problems = append(problems, fmt.Sprintf("user %d has an invalid quantity or durability of %q", user.UserID, item.Name))
+			}
+		}
+	}
+
+	// Marriages go both ways and have to be between users in the snapshot
+	for _, user := range snapshot.Users {
+		if user.MarriedTo == 0 {
+			continue
+		}
+		if spouse, ok := users[user.MarriedTo]; !ok || spouse.MarriedTo != user.UserID {
+			problems = append(problems, fmt.Sprintf("user %d is married to %d, but not the other way around", user.UserID, user.MarriedTo))
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return ErrInvalidSnapshot{Problems: problems}
+	}
+	return nil
+}
+
+// mary import [dry-run] -> restores a snapshot into a guild, which doesn't have to be the one it came from
+// Users in the snapshot get its balance, inventory and marriage, users that aren't in it are left alone,
+// except that anyone still married to an imported user is divorced, since the snapshot has their spouse married to someone else (or nobody)
+// A dry run only counts what would change
+func Import(mongoURI string, guildID int, guildName string, snapshot Snapshot, dryRun bool) (ImportResult, error) {
+	err := snapshot.Validate()
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	// Connect to MongoDB
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return ImportResult{}, dbError("creating MongoDB client", err)
+	}
+
+	// Big guilds can take a while, so this gets longer than the usual 10 secs
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return ImportResult{}, dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	userCollection := client.Database(strconv.Itoa(guildID)).Collection("Users")
+
+	// Find out which users already exist, to count them
+	userIDs := bson.A{}
+	for _, user := range snapshot.Users {
+		userIDs = append(userIDs, user.UserID)
+	}
+	cursor, err := userCollection.Find(
+		ctx,
+		bson.D{{Key: "guild_id", Value: guildID}, {Key: "user_id", Value: bson.D{{Key: "$in", Value: userIDs}}}},
+		options.Find().SetProjection(bson.D{{Key: "user_id", Value: 1}}),
+	)
+	if err != nil {
+		return ImportResult{}, dbError("selecting from database", err)
+	}
+	var existing []User
+	err = cursor.All(ctx, &existing)
+	if err != nil {
+		return ImportResult{}, dbError("decoding result", err)
+	}
+
+	// Users outside the snapshot who are married to someone in it
+	leftBehind := bson.D{
+		{Key: "guild_id", Value: guildID},
+		{Key: "user_id", Value: bson.D{{Key: "$nin", Value: userIDs}}},
+		{Key: "married_to", Value: bson.D{{Key: "$in", Value: userIDs}}},
+	}
+	unmarried, err := userCollection.CountDocuments(ctx, leftBehind)
+	if err != nil {
+		return ImportResult{}, dbError("selecting from database", err)
+	}
+
+	result := ImportResult{DryRun: dryRun, Updated: len(existing), Created: len(snapshot.Users) - len(existing), Unmarried: int(unmarried)}
+	if dryRun || len(snapshot.Users) == 0 {
+		return result, nil
+	}
+
+	// The import might create the guild's first users, which need the unique index before they're inserted
+	err = userIndexes(ctx, userCollection)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	writes := []mongo.WriteModel{}
+	for _, user := range snapshot.Users {
+		inventory := []Item{}
+		for _, item := range user.Inventory {
+			inventory = append(inventory, Item{item.Name, item.Quantity, item.Durability, item.Broken})
+		}
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.D{{Key: "user_id", Value: user.UserID}, {Key: "guild_id", Value: guildID}}).
+			SetUpdate(bson.D{
+				{Key: "$set", Value: bson.D{
+					{Key: "user_name", Value: user.UserName},
+					{Key: "balance", Value: user.Balance},
+					{Key: "married_to", Value: user.MarriedTo},
+					{Key: "inventory", Value: inventory},
+				}},
+				// New users get the same fields as everyone else
+				{Key: "$setOnInsert", Value: withoutFields(newUser(guildName, user.UserName, 0), "user_name", "balance", "married_to", "inventory")},
+			}).
+			SetUpsert(true))
+	}
+	_, err = userCollection.BulkWrite(ctx, writes)
+	if err != nil {
+		return ImportResult{}, dbError("updating database", err)
+	}
+
+	// Marriages go both ways, so nobody can be left married to someone who's now married to someone else
+	_, err = userCollection.UpdateMany(ctx, leftBehind, bson.D{{Key: "$set", Value: bson.D{{Key: "married_to", Value: 0}}}})
+	if err != nil {
+		return ImportResult{}, dbError("updating database", err)
+	}
+	logging.Info("Imported guild", "guild", guildID, "from_guild", snapshot.GuildID, "created", result.Created, "updated", result.Updated, "unmarried", result.Unmarried)
+	return result, nil
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseSnapshot(t *testing.T) {
+	want := Snapshot{Version: 1, Users: []SnapshotUser{
+		{UserID: 1, UserName: "alice", Balance: 500, MarriedTo: 2, Inventory: []SnapshotItem{{Name: "gun", Quantity: 1}, {Name: "chocolate", Quantity: 12}}},
+		{UserID: 2, UserName: "bob", MarriedTo: 1, Inventory: []SnapshotItem{}},
+	}}
+
+	// Both formats read back what they wrote
+	data, err := want.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ParseSnapshot("economy.json", data); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseSnapshot(JSON()) = %+v, %v, want %+v", got, err, want)
+	}
+	data, err = want.CSV()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ParseSnapshot("ECONOMY.CSV", data); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseSnapshot(CSV()) = %+v, %v, want %+v", got, err, want)
+	}
+
+	tests := []struct {
+		name     string
+		fileName string
+		data     string
+	}{
+		{"wrong extension", "economy.txt", `{"version": 1}`},
+		{"bad JSON", "economy.json", `{"version": `},
+		{"unknown field", "economy.json", `{"version": 1, "users": [], "extra": true}`},
+		{"wrong header", "economy.csv", "id,name\n"},
+		{"bad balance", "economy.csv", "user_id,user_name,balance,married_to,inventory\n1,alice,lots,0,\n"},
+		{"bad inventory", "economy.csv", "user_id,user_name,balance,married_to,inventory\n1,alice,5,0,gun\n"},
+		{"invalid", "economy.json", `{"version": 2, "users": []}`},
+	}
+	for _, test := range tests {
+		var invalid ErrInvalidSnapshot
+		if _, err := ParseSnapshot(test.fileName, []byte(test.data)); !errors.As(err, &invalid) {
+			t.Errorf("%s: ParseSnapshot() = %v, want ErrInvalidSnapshot", test.name, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		users []SnapshotUser
+		want  int // Number of problems found
+	}{
+		{"empty", []SnapshotUser{}, 0},
+		{"married", []SnapshotUser{{UserID: 1, MarriedTo: 2}, {UserID: 2, MarriedTo: 1}}, 0},
+		{"items", []SnapshotUser{{UserID: 1, Inventory: []SnapshotItem{{Name: "gun", Quantity: 2, Durability: 3}}}}, 0},
+		{"bad user ID", []SnapshotUser{{UserID: 0}}, 1},
+		{"twice", []SnapshotUser{{UserID: 1}, {UserID: 1}}, 1},
+		{"negative balance", []SnapshotUser{{UserID: 1, Balance: -1}}, 1},
+		{"unknown item", []SnapshotUser{{UserID: 1, Inventory: []SnapshotItem{{Name: "sword", Quantity: 1}}}}, 1},
+		{"display name", []SnapshotUser{{UserID: 1, Inventory: []SnapshotItem{{Name: "🔫 Gun", Quantity: 1}}}}, 1},
+		{"no quantity", []SnapshotUser{{UserID: 1, Inventory: []SnapshotItem{{Name: "gun", Quantity: 0}}}}, 1},
+		{"one-sided marriage", []SnapshotUser{{UserID: 1, MarriedTo: 2}, {UserID: 2}}, 1},
+		{"spouse missing", []SnapshotUser{{UserID: 1, MarriedTo: 3}}, 1},
+	}
+	for _, test := range tests {
+		err := Snapshot{Version: 1, Users: test.users}.Validate()
+		var invalid ErrInvalidSnapshot
+		got := 0
+		if errors.As(err, &invalid) {
+			got = len(invalid.Problems)
+		} else if err != nil {
+			t.Errorf("%s: Validate() = %v, want ErrInvalidSnapshot", test.name, err)
+		}
+		if got != test.want {
+			t.Errorf("%s: Validate() found %d problems (%v), want %d", test.name, got, err, test.want)
+		}
+	}
+
+	if err := (Snapshot{Version: 0}).Validate(); err == nil {
+		t.Errorf("Validate() accepted version 0")
+	}
+}
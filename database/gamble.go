@@ -2,19 +2,47 @@ package database
 
 import (
 	"context"
-	"fmt"
 	"math/rand"
-	"strconv"
-	"strings"
 	"time"
+	"mary-bot/config"
+	"mary-bot/metrics"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options" 
 	"go.mongodb.org/mongo-driver/bson"
 	commands "mary-bot/commands"
 )
 
+// Not a command
+// Takes the bet and starts the gamble cooldown
+// The balance check is part of the update, so two gambles at once can't both spend the same coins
+func placeBet(ctx context.Context, userCollection *mongo.Collection, guildID int, userID int, bet int, userBalance int64) (error) {
+	result, err := userCollection.UpdateOne(
+		ctx,
+		bson.D{
+			{Key: "user_id", Value: userID},
+			{Key: "guild_id", Value: guildID},
+			{Key: "balance", Value: bson.D{{Key: "$gte", Value: bet}}},
+		},
+		bson.D{
+			{Key: "$inc", Value: bson.D{
+				{Key: "balance", Value: -bet},
+				{Key: "gamble_profit", Value: -bet},
+			}},
+			{Key: "$set", Value: bson.D{
+				{Key: "last_gamble", Value: time.Now()}, // Starts the cooldown
+			}},
+		},
+	)
+	if err != nil {
+		return dbError("updating database", err)
+	}
+	// Something else spent the coins since the balance was checked
+	if result.MatchedCount == 0 {
+		return ErrCantAfford{Cost: int64(bet), Balance: userBalance}
+	}
+	return nil
+}
 
-func Gamble(ctx context.Context, userCollection *mongo.Collection, guildID int, userID int, balance int) (string) {
+func Gamble(ctx context.Context, userCollection *mongo.Collection, guildID int, userID int, balance int, settings config.Config) (EconomyResult, error) {
 	// Get last_gamble from database
 	collectionResult, err := userCollection.FindOne(
 		ctx,
@@ -24,46 +52,35 @@ func Gamble(ctx context.Context, userCollection *mongo.Collection, guildID int,
 		},
 	).DecodeBytes()
 	if err != nil {
-		fmt.Printf("Error occurred while selecting from database! %s\n", err)
-		return "Error occurred while selecting from database! " + strings.Title(err.Error())
+		return EconomyResult{}, dbError("selecting from database", err)
 	}
 
 	lastGamble := collectionResult.Lookup("last_gamble").DateTime()
-	// Wait ten seconds before gambling again
-	if time.Now().Unix() - lastGamble/1000 < 10 && commands.IsOwner(userID) == false {
-		return "<@" + strconv.Itoa(userID) + ">, you must wait 10 seconds before gambling again!"
+	// Wait for the gamble cooldown before gambling again
+	if time.Now().Unix() - lastGamble/1000 < int64(settings.Cooldowns.Gamble.Seconds()) && commands.IsOwner(userID) == false {
+		return EconomyResult{}, ErrCooldown{Action: "gamble", Remaining: remaining(time.UnixMilli(lastGamble), settings.Cooldowns.Gamble)}
 	}
 
 	// Check if user has enough to gamble
 	userBalance := collectionResult.Lookup("balance").Int64()
 	if userBalance < int64(balance) {
-		return "<@" + strconv.Itoa(userID) + ">, you don't have enough coins to gamble that much!"
+		return EconomyResult{}, ErrCantAfford{Cost: int64(balance), Balance: userBalance}
 	}
 
 	// Subtract balance from user
-	result := userCollection.FindOneAndUpdate(
-		ctx,
-		bson.D{
-			{Key: "user_id", Value: userID},
-			{Key: "guild_id", Value: guildID},
-		},
-		bson.D{
-			{Key: "$inc", Value: bson.D{
-				{Key: "balance", Value: -balance},
-			}},
-		},
-		options.FindOneAndUpdate().SetUpsert(true),
-	)
-	if result.Err() != nil {
-		fmt.Printf("Error occurred while updating database! %s\n", result.Err())
-		return "Error occurred while updating database! " + strings.Title(result.Err().Error())
+	err = placeBet(ctx, userCollection, guildID, userID, balance, userBalance)
+	if err != nil {
+		return EconomyResult{}, err
 	}
+	metrics.CoinsBurned.Add("gamble", float64(balance))
+	res := EconomyResult{Operation: "gamble", UserID: userID, Bet: int64(balance)}
+	res.levelledUp(grantXP(ctx, userCollection, guildID, userID, xpPerAction))
 
 	// Roll dice 
 	dice := rand.Intn(100) + 1
 	if dice <= 50 {
 		// Lose
-		return "<@" + strconv.Itoa(userID) + ">, you lose. -" + strconv.Itoa(balance) + " coins."
+		return res, nil
 	} else if dice <= 80 {
 		// Win - 30% chance
 		result := userCollection.FindOneAndUpdate(
@@ -75,22 +92,24 @@ func Gamble(ctx context.Context, userCollection *mongo.Collection, guildID int,
 			bson.D{
 				{Key: "$inc", Value: bson.D{
 					{Key: "balance", Value: balance * 2},
+					{Key: "gamble_profit", Value: balance * 2},
 				}},
 			},
-			options.FindOneAndUpdate().SetUpsert(true),
 		)
 		if result.Err() != nil {
-			fmt.Printf("Error occurred while updating database! %s\n", result.Err())
-			return "Error occurred while updating database! " + strings.Title(result.Err().Error())
+			return EconomyResult{}, dbError("updating database", result.Err())
 		}
-		return "<@" + strconv.Itoa(userID) + ">, you win! +" + strconv.Itoa(balance * 2) + " coins!"
+		res.Won = true
+		res.Amount = int64(balance * 2)
+		metrics.CoinsMinted.Add("gamble", float64(res.Amount))
+		return res, nil
 	} else {
 		// Lose
-		return "<@" + strconv.Itoa(userID) + ">, you lose. -" + strconv.Itoa(balance) + " coins."
+		return res, nil
 	}
 }
 
-func Lottery(ctx context.Context, userCollection *mongo.Collection, guildID int, userID int, balance int) (string) {
+func Lottery(ctx context.Context, userCollection *mongo.Collection, guildID int, userID int, balance int, settings config.Config) (EconomyResult, error) {
 	// Get last_gamble from database
 	collectionResult, err := userCollection.FindOne(
 		ctx,
@@ -100,46 +119,35 @@ func Lottery(ctx context.Context, userCollection *mongo.Collection, guildID int,
 		},
 	).DecodeBytes()
 	if err != nil {
-		fmt.Printf("Error occurred while selecting from database! %s\n", err)
-		return "Error occurred while selecting from database! " + strings.Title(err.Error())
+		return EconomyResult{}, dbError("selecting from database", err)
 	}
 
 	lastGamble := collectionResult.Lookup("last_gamble").DateTime()
-	// Wait ten seconds before gambling again
-	if time.Now().Unix() - lastGamble/1000 < 10 && commands.IsOwner(userID) == false {
-		return "<@" + strconv.Itoa(userID) + ">, you must wait 10 seconds before gambling again!"
+	// Wait for the gamble cooldown before gambling again
+	if time.Now().Unix() - lastGamble/1000 < int64(settings.Cooldowns.Gamble.Seconds()) && commands.IsOwner(userID) == false {
+		return EconomyResult{}, ErrCooldown{Action: "gamble", Remaining: remaining(time.UnixMilli(lastGamble), settings.Cooldowns.Gamble)}
 	}
 
 	// Check if user has enough to gamble
 	userBalance := collectionResult.Lookup("balance").Int64()
 	if userBalance < int64(balance) {
-		return "<@" + strconv.Itoa(userID) + ">, you don't have enough coins to gamble that much!"
+		return EconomyResult{}, ErrCantAfford{Cost: int64(balance), Balance: userBalance}
 	}
 
 	// Subtract balance from user
-	result := userCollection.FindOneAndUpdate(
-		ctx,
-		bson.D{
-			{Key: "user_id", Value: userID},
-			{Key: "guild_id", Value: guildID},
-		},
-		bson.D{
-			{Key: "$inc", Value: bson.D{
-				{Key: "balance", Value: -balance},
-			}},
-		},
-		options.FindOneAndUpdate().SetUpsert(true),
-	)
-	if result.Err() != nil {
-		fmt.Printf("Error occurred while updating database! %s\n", result.Err())
-		return "Error occurred while updating database! " + strings.Title(result.Err().Error())
+	err = placeBet(ctx, userCollection, guildID, userID, balance, userBalance)
+	if err != nil {
+		return EconomyResult{}, err
 	}
+	metrics.CoinsBurned.Add("lottery", float64(balance))
+	res := EconomyResult{Operation: "lottery", UserID: userID, Bet: int64(balance)}
+	res.levelledUp(grantXP(ctx, userCollection, guildID, userID, xpPerAction))
 
 	// Roll dice 
 	dice := rand.Intn(100) + 1
 	if dice <= 60 {
 		// Lose
-		return "<@" + strconv.Itoa(userID) + ">, you lose. -" + strconv.Itoa(balance) + " coins."
+		return res, nil
 	} else if dice <= 70 || dice > 90 {
 		// Win - 20% chance but 5X the payout
 		result := userCollection.FindOneAndUpdate(
@@ -151,22 +159,25 @@ func Lottery(ctx context.Context, userCollection *mongo.Collection, guildID int,
 			bson.D{
 				{Key: "$inc", Value: bson.D{
 					{Key: "balance", Value: balance * 5},
+					{Key: "gamble_profit", Value: balance * 5},
 				}},
 			},
-			options.FindOneAndUpdate().SetUpsert(true),
 		)
 		if result.Err() != nil {
-			fmt.Printf("Error occurred while updating database! %s\n", result.Err())
-			return "Error occurred while updating database! " + strings.Title(result.Err().Error())
+			return EconomyResult{}, dbError("updating database", result.Err())
 		}
-		return "<@" + strconv.Itoa(userID) + ">, you win! +" + strconv.Itoa(balance * 5) + " coins!"
+		res.Won = true
+		res.Amount = int64(balance * 5)
+		metrics.CoinsMinted.Add("lottery", float64(res.Amount))
+		res.unlocked(checkAchievements(ctx, userCollection, guildID, userID, eventLotteryWin))
+		return res, nil
 	} else {
 		// Lose
-		return "<@" + strconv.Itoa(userID) + ">, you lose. -" + strconv.Itoa(balance) + " coins."
+		return res, nil
 	}
 }
 
-func Slots(ctx context.Context, userCollection *mongo.Collection, guildID int, userID int, balance int) (string) {
+func Slots(ctx context.Context, userCollection *mongo.Collection, guildID int, userID int, balance int, settings config.Config) (EconomyResult, error) {
 	// Get last_gamble from database
 	collectionResult, err := userCollection.FindOne(
 		ctx,
@@ -176,46 +187,35 @@ func Slots(ctx context.Context, userCollection *mongo.Collection, guildID int, u
 		},
 	).DecodeBytes()
 	if err != nil {
-		fmt.Printf("Error occurred while selecting from database! %s\n", err)
-		return "Error occurred while selecting from database! " + strings.Title(err.Error())
+		return EconomyResult{}, dbError("selecting from database", err)
 	}
 
 	lastGamble := collectionResult.Lookup("last_gamble").DateTime()
-	// Wait ten seconds before gambling again
-	if time.Now().Unix() - lastGamble/1000 < 10 && commands.IsOwner(userID) == false {
-		return "<@" + strconv.Itoa(userID) + ">, you must wait 10 seconds before gambling again!"
+	// Wait for the gamble cooldown before gambling again
+	if time.Now().Unix() - lastGamble/1000 < int64(settings.Cooldowns.Gamble.Seconds()) && commands.IsOwner(userID) == false {
+		return EconomyResult{}, ErrCooldown{Action: "gamble", Remaining: remaining(time.UnixMilli(lastGamble), settings.Cooldowns.Gamble)}
 	}
 
 	// Check if user has enough to gamble
 	userBalance := collectionResult.Lookup("balance").Int64()
 	if userBalance < int64(balance) {
-		return "<@" + strconv.Itoa(userID) + ">, you don't have enough coins to gamble that much!"
+		return EconomyResult{}, ErrCantAfford{Cost: int64(balance), Balance: userBalance}
 	}
 	
 	// Subtract balance from user
-	result := userCollection.FindOneAndUpdate(
-		ctx,
-		bson.D{
-			{Key: "user_id", Value: userID},
-			{Key: "guild_id", Value: guildID},
-		},
-		bson.D{
-			{Key: "$inc", Value: bson.D{
-				{Key: "balance", Value: -balance},
-			}},
-		},
-		options.FindOneAndUpdate().SetUpsert(true),
-	)
-	if result.Err() != nil {
-		fmt.Printf("Error occurred while updating database! %s\n", result.Err())
-		return "Error occurred while updating database! " + strings.Title(result.Err().Error())
+	err = placeBet(ctx, userCollection, guildID, userID, balance, userBalance)
+	if err != nil {
+		return EconomyResult{}, err
 	}
+	metrics.CoinsBurned.Add("slots", float64(balance))
+	res := EconomyResult{Operation: "slots", UserID: userID, Bet: int64(balance)}
+	res.levelledUp(grantXP(ctx, userCollection, guildID, userID, xpPerAction))
 
 	// Roll dice 
 	dice := rand.Intn(100) + 1
 	if dice <= 40 {
 		// Lose
-		return "<@" + strconv.Itoa(userID) + ">, you lose. -" + strconv.Itoa(balance) + " coins."
+		return res, nil
 	} else if dice <= 70 || dice > 90 {
 		// Win - 40% chance and 2X payout, but can only win 20 coins at a time
 		result := userCollection.FindOneAndUpdate(
@@ -227,17 +227,19 @@ func Slots(ctx context.Context, userCollection *mongo.Collection, guildID int, u
 			bson.D{
 				{Key: "$inc", Value: bson.D{
 					{Key: "balance", Value: balance * 2},
+					{Key: "gamble_profit", Value: balance * 2},
 				}},
 			},
-			options.FindOneAndUpdate().SetUpsert(true),
 		)
 		if result.Err() != nil {
-			fmt.Printf("Error occurred while updating database! %s\n", result.Err())
-			return "Error occurred while updating database! " + strings.Title(result.Err().Error())
+			return EconomyResult{}, dbError("updating database", result.Err())
 		}
-		return "<@" + strconv.Itoa(userID) + ">, you win! +" + strconv.Itoa(balance * 2) + " coins!"
+		res.Won = true
+		res.Amount = int64(balance * 2)
+		metrics.CoinsMinted.Add("slots", float64(res.Amount))
+		return res, nil
 	} else {
 		// Lose
-		return "<@" + strconv.Itoa(userID) + ">, you lose. -" + strconv.Itoa(balance) + " coins."
+		return res, nil
 	}
 }
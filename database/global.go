@@ -0,0 +1,258 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+	"mary-bot/metrics"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Players who opted in to the global leaderboard are kept in their own database
+// Their coins and items stay in each guild's database, this is only used to add them up
+const globalDatabase = "Global"
+
+type GlobalPlayer struct {
+	UserID   int       `bson:"user_id"`
+	UserName string    `bson:"user_name"`
+	JoinedAt time.Time `bson:"joined_at"`
+}
+
+// A player's stats added up across every server they play in
+type GlobalProfile struct {
+	UserName string
+	Servers  int
+	Scores   map[string]int64 // Keyed by ranking name, e.g. "networth"
+}
+
+// mary global join
+func GlobalJoin(mongoURI string, userID int, userName string) (error) {
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	// Only inserts if they haven't joined yet, otherwise just keeps their name up to date
+	playerCollection := client.Database(globalDatabase).Collection("Players")
+	result, err := playerCollection.UpdateOne(
+		ctx,
+		bson.D{{Key: "user_id", Value: userID}},
+		bson.D{
+			{Key: "$set", Value: bson.D{{Key: "user_name", Value: userName}}},
+			{Key: "$setOnInsert", Value: bson.D{{Key: "joined_at", Value: time.Now()}}},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return dbError("updating database", err)
+	}
+	if result.UpsertedCount == 0 {
+		return ErrAlreadyGlobal
+	}
+	return nil
+}
+
+// mary global leave
+func GlobalLeave(mongoURI string, userID int) (error) {
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	playerCollection := client.Database(globalDatabase).Collection("Players")
+	result, err := playerCollection.DeleteOne(ctx, bson.D{{Key: "user_id", Value: userID}})
+	if err != nil {
+		return dbError("updating database", err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotGlobal
+	}
+	return nil
+}
+
+// Not a command
+// Adds up every ranking's score for the given users across the given guilds
+// Also returns how many of the guilds each user plays in
+func globalTotals(ctx context.Context, client *mongo.Client, guildIDs []int, userIDs []int) (map[int]map[string]int64, map[int]int, error) {
+	scores := bson.D{}
+	for _, ranking := range rankings {
+		scores = append(scores, bson.E{Key: ranking.Name, Value: bson.D{{Key: "$toLong", Value: ranking.score}}})
+	}
+
+	totals := map[int]map[string]int64{}
+	servers := map[int]int{}
+	for _, guildID := range guildIDs {
+		userCollection := client.Database(strconv.Itoa(guildID)).Collection("Users")
+		cursor, err := userCollection.Aggregate(ctx, mongo.Pipeline{
+			{{Key: "$match", Value: bson.D{
+				{Key: "guild_id", Value: guildID},
+				{Key: "user_id", Value: bson.D{{Key: "$in", Value: userIDs}}},
+			}}},
+			{{Key: "$project", Value: append(bson.D{{Key: "user_id", Value: 1}}, scores...)}},
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		var results []bson.M
+		err = cursor.All(ctx, &results)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, result := range results {
+			userID := int(result["user_id"].(int64))
+			if totals[userID] == nil {
+				totals[userID] = map[string]int64{}
+			}
+			for _, ranking := range rankings {
+				totals[userID][ranking.Name] += result[ranking.Name].(int64)
+			}
+			servers[userID]++
+		}
+	}
+	return totals, servers, nil
+}
+
+// mary top global [ranking]
+// Ranks everyone who opted in by their stats added up across guildIDs
+func GlobalLeaderboard(mongoURI string, guildIDs []int, userID int, rankingName string, page int, pageSize int) (LeaderboardPage, error) {
+	ranking, ok := findRanking(rankingName)
+	if !ok {
+		return LeaderboardPage{}, ErrNoSuchRanking
+	}
+
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return LeaderboardPage{}, dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second) // Longer timeout because every server is checked
+	defer cancel()
+	err = client.Connect(ctx)
+	if err != nil {
+		return LeaderboardPage{}, dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx)
+
+	// Get everyone who opted in
+	cursor, err := client.Database(globalDatabase).Collection("Players").Find(ctx, bson.D{})
+	if err != nil {
+		return LeaderboardPage{}, dbError("selecting from database", err)
+	}
+	var players []GlobalPlayer
+	err = cursor.All(ctx, &players)
+	if err != nil {
+		return LeaderboardPage{}, dbError("decoding result", err)
+	}
+	userIDs := []int{}
+	names := map[int]string{}
+	for _, player := range players {
+		userIDs = append(userIDs, player.UserID)
+		names[player.UserID] = player.UserName
+	}
+
+	totals, _, err := globalTotals(ctx, client, guildIDs, userIDs)
+	if err != nil {
+		return LeaderboardPage{}, dbError("selecting from database", err)
+	}
+
+	// Sort the players the same way the server leaderboards do, ties broken by user ID
+	entries := []LeaderboardEntry{}
+	for playerID, total := range totals {
+		entries = append(entries, LeaderboardEntry{UserID: playerID, Name: names[playerID], Score: total[ranking.Name]})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Score != entries[j].Score {
+			return entries[i].Score > entries[j].Score
+		}
+		return entries[i].UserID < entries[j].UserID
+	})
+
+	leaderboard := LeaderboardPage{Ranking: ranking, Total: len(entries)}
+	for i := range entries {
+		entries[i].Rank = i + 1
+		if entries[i].UserID == userID {
+			caller := entries[i]
+			leaderboard.Caller = &caller
+		}
+	}
+	if page < 0 {
+		page = 0
+	}
+	start, end := page * pageSize, page * pageSize + pageSize
+	if start > len(entries) {
+		start = len(entries)
+	}
+	if end > len(entries) {
+		end = len(entries)
+	}
+	leaderboard.Entries = entries[start:end]
+	return leaderboard, nil
+}
+
+// mary profile global
+func GetGlobalProfile(mongoURI string, guildIDs []int, userID int) (GlobalProfile, error) {
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return GlobalProfile{}, dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second) // Longer timeout because every server is checked
+	defer cancel()
+	err = client.Connect(ctx)
+	if err != nil {
+		return GlobalProfile{}, dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx)
+
+	// Global profiles are opt-in too
+	var player GlobalPlayer
+	err = client.Database(globalDatabase).Collection("Players").FindOne(ctx, bson.D{{Key: "user_id", Value: userID}}).Decode(&player)
+	if err == mongo.ErrNoDocuments {
+		return GlobalProfile{}, ErrNotGlobal
+	} else if err != nil {
+		return GlobalProfile{}, dbError("selecting from database", err)
+	}
+
+	totals, servers, err := globalTotals(ctx, client, guildIDs, []int{userID})
+	if err != nil {
+		return GlobalProfile{}, dbError("selecting from database", err)
+	}
+	scores := totals[userID]
+	if scores == nil {
+		scores = map[string]int64{}
+	}
+	return GlobalProfile{UserName: player.UserName, Servers: servers[userID], Scores: scores}, nil
+}
+
+// Helper to list the rankings in the order they should be shown
+func Rankings() ([]Ranking) {
+	return rankings
+}
@@ -0,0 +1,205 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"mary-bot/logging"
+	"mary-bot/metrics"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// The code MongoDB gives commands a standalone server can't run, like transactions
+const illegalOperation = 20
+
+// A reason for backing out of a transaction that gets passed back to the caller
+type transactionAbort struct {
+	err error
+}
+
+func (abort transactionAbort) Error() (string) {
+	return abort.err.Error()
+}
+
+// Not a command
+// Runs fn in a MongoDB transaction so that every update in it either all happens or none of it does
+// Returns nil on success, the abort reason if fn backed out, or the database error otherwise
+// Transactions need a replica set (Atlas clusters always are), a standalone mongod gets ErrNoTransactions
+func runTransaction(ctx context.Context, client *mongo.Client, fn func(sessCtx mongo.SessionContext) error) (error) {
+	session, err := client.StartSession()
+	if err != nil {
+		return dbError("starting database session", err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessCtx)
+	})
+	var serverErr mongo.ServerError
+	if abort, ok := err.(transactionAbort); ok {
+		return abort.err
+	} else if errors.As(err, &serverErr) && serverErr.HasErrorCodeWithMessage(illegalOperation, "replica set") {
+		// "Transaction numbers are only allowed on a replica set member or mongos"
+		logging.Error("MongoDB doesn't support transactions, run it as a replica set to use the market and trades!", "err", err)
+		metrics.ErrorsTotal.Inc("database")
+		return ErrNoTransactions
+	} else if err != nil {
+		return dbError("updating database", err)
+	}
+	return nil
+}
+
+// Not a command
+// Adds quantity of item to the user's inventory, creating the slot if they don't have one yet
+func addToInventory(ctx context.Context, userCollection *mongo.Collection, guildID int, userID int, item string, quantity int) (error) {
+	// If the user already has the item, increment the quantity
+	result, err := userCollection.UpdateOne(
+		ctx,
+		bson.D{
+			{Key: "user_id", Value: userID},
+			{Key: "guild_id", Value: guildID},
+			{Key: "inventory", Value: bson.D{
+				{Key: "$elemMatch", Value: bson.D{
+					{Key: "name", Value: item},
+				}},
+			}},
+		},
+		bson.D{
+			{Key: "$inc", Value: bson.D{
+				{Key: "inventory.$.quantity", Value: quantity},
+			}},
+		},
+	)
+	if err != nil || result.MatchedCount > 0 {
+		return err
+	}
+
+	// Otherwise, add the item to their inventory
+	_, err = userCollection.UpdateOne(
+		ctx,
+		bson.D{
+			{Key: "user_id", Value: userID},
+			{Key: "guild_id", Value: guildID},
+		},
+		bson.D{
+			{Key: "$push", Value: bson.D{
+				{Key: "inventory", Value: Item{Name: item, Quantity: quantity}},
+			}},
+		},
+	)
+	return err
+}
+
+// Not a command
+// Takes quantity of item out of the user's inventory, removing the slot if it runs out
+// The spares are taken before the one in hand, so the items taken are always as good as new
+// Returns false if the user doesn't have enough of the item, or would have to give up the one in hand while it's worn (see itemWorn)
+func takeFromInventory(ctx context.Context, userCollection *mongo.Collection, guildID int, userID int, item string, quantity int) (bool, error) {
+	shopItem, _ := FindShopItem(item)
+
+	// Only matches if the user has enough of the item, and has spares or an unworn one in hand
+	result, err := userCollection.UpdateOne(
+		ctx,
+		bson.D{
+			{Key: "user_id", Value: userID},
+			{Key: "guild_id", Value: guildID},
+			{Key: "inventory", Value: bson.D{
+				{Key: "$elemMatch", Value: bson.D{
+					{Key: "name", Value: item},
+					{Key: "quantity", Value: bson.D{{Key: "$gte", Value: quantity}}},
+					{Key: "$or", Value: bson.A{
+						bson.D{{Key: "quantity", Value: bson.D{{Key: "$gt", Value: quantity}}}},
+						bson.D{
+							{Key: "broken", Value: bson.D{{Key: "$ne", Value: true}}},
+							// Durability 0 means it's never been used
+							{Key: "durability", Value: bson.D{{Key: "$not", Value: bson.D{
+								{Key: "$gt", Value: 0},
+								{Key: "$lt", Value: shopItem.MaxDurability},
+							}}}},
+						},
+					}},
+				}},
+			}},
+		},
+		bson.D{
+			{Key: "$inc", Value: bson.D{
+				{Key: "inventory.$.quantity", Value: -quantity},
+			}},
+		},
+	)
+	if err != nil {
+		return false, err
+	} else if result.MatchedCount == 0 {
+		return false, nil
+	}
+
+	// Remove the item if there's none left
+	_, err = userCollection.UpdateOne(
+		ctx,
+		bson.D{
+			{Key: "user_id", Value: userID},
+			{Key: "guild_id", Value: guildID},
+		},
+		bson.D{
+			{Key: "$pull", Value: bson.D{
+				{Key: "inventory", Value: bson.D{
+					{Key: "name", Value: item},
+					{Key: "quantity", Value: bson.D{{Key: "$lte", Value: 0}}},
+				}},
+			}},
+		},
+	)
+	return err == nil, err
+}
+
+// Not a command
+// Adds amount to the user's balance, or takes it away if amount is negative
+// Returns false if the user doesn't have enough to take away
+func adjustBalance(ctx context.Context, userCollection *mongo.Collection, guildID int, userID int, amount int64) (bool, error) {
+	filter := bson.D{
+		{Key: "user_id", Value: userID},
+		{Key: "guild_id", Value: guildID},
+	}
+	if amount < 0 {
+		filter = append(filter, bson.E{Key: "balance", Value: bson.D{{Key: "$gte", Value: -amount}}})
+	}
+	result, err := userCollection.UpdateOne(
+		ctx,
+		filter,
+		bson.D{
+			{Key: "$inc", Value: bson.D{
+				{Key: "balance", Value: amount},
+			}},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// Not a command
+// Moves coins from one user to another, only if the payer still has enough
+// Returns false if the payer doesn't have enough, or ErrNotPlaying if the payee has no document
+// Works outside a transaction too, since the payer gets their coins back if paying the payee fails
+func transferCoins(ctx context.Context, userCollection *mongo.Collection, guildID int, fromUserID int, toUserID int, amount int64) (bool, error) {
+	ok, err := adjustBalance(ctx, userCollection, guildID, fromUserID, -amount)
+	if err != nil || !ok {
+		return false, err
+	}
+	ok, err = adjustBalance(ctx, userCollection, guildID, toUserID, amount)
+	if err == nil && ok {
+		return true, nil
+	}
+
+	// Refund the payer so the coins don't disappear
+	_, refundErr := adjustBalance(ctx, userCollection, guildID, fromUserID, amount)
+	if refundErr != nil {
+		logging.Error("Error occurred while refunding a transfer!", "guild", guildID, "user", fromUserID, "amount", amount, "err", refundErr)
+		metrics.ErrorsTotal.Inc("database")
+	}
+	if err != nil {
+		return false, err
+	}
+	return false, ErrNotPlaying
+}
@@ -2,32 +2,56 @@ package database
 
 import (
 	"context"
-	"fmt"
 	"strconv"
 	"sort"
 	"strings"
 	"regexp" // For removing emojis
 	"time"
+	"mary-bot/config"
+	"mary-bot/logging"
+	"mary-bot/metrics"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/bson"
-	"github.com/bwmarrin/discordgo"
 )
 
 type ShopItem struct {
     Name        string
     Price       int
     Description string
+    MaxDurability int // Number of uses before the item breaks, 0 means it's used up in one go
+    MinLevel    int // Level needed to buy it from the shop
 }
 
 // Define the items for sale
 var items = []ShopItem{ // Global variables don't use :=, they use =
-	{"🔫 Gun", 2000, "It's a gun... what do you expect?"},
-	{"🚗 Car", 50000, "Run people over with this car!"},
-	{"🍫 Chocolate", 50, "It won't help against the zombies, but everyone loves chocolate!"},
-	{"💍 Ring", 1000, "Congratulations! Who's the lucky person?"},
-	{"🏹 Bow", 400, "It might not be as strong as a gun, but it's cheaper!"},
-	{"🛡️ Shield", 5000, "Protect yourself from the attackers!"},
+	{"🔫 Gun", 2000, "It's a gun... what do you expect?", 5, 3},
+	{"🚗 Car", 50000, "Run people over with this car!", 20, 10},
+	{"🍫 Chocolate", 50, "It won't help against the zombies, but everyone loves chocolate!", 0, 0},
+	{"💍 Ring", 1000, "Congratulations! Who's the lucky person?", 0, 0},
+	{"🏹 Bow", 400, "It might not be as strong as a gun, but it's cheaper!", 3, 0},
+	{"🛡️ Shield", 5000, "Protect yourself from the attackers!", 3, 5},
+}
+
+// The name an item is stored under in the inventory (e.g. "gun" for "🔫 Gun", "giftbox" for "gift box")
+func itemKey(name string) (string) {
+	pattern := regexp.MustCompile("[^a-zA-Z0-9]+")
+	return strings.ToLower(pattern.ReplaceAllString(name, ""))
+}
+
+// Finds a shop or crafted item by its inventory name
+func FindShopItem(name string) (ShopItem, bool) {
+	for i := range items {
+		if itemKey(items[i].Name) == itemKey(name) {
+			return items[i], true
+		}
+	}
+	for i := range craftedItems { // Crafted items are defined in crafting.go
+		if itemKey(craftedItems[i].Name) == itemKey(name) {
+			return craftedItems[i], true
+		}
+	}
+	return ShopItem{}, false
 }
 
 // Lookup table for emojis
@@ -38,99 +62,139 @@ var emojiLookup = map[string]string{
 	"Ring": "💍",
 	"Bow": "🏹",
 	"Shield": "🛡️",
+	"Crossbow": "🎯",
+	"Giftbox": "🎁",
 }
 
 type User struct {
 	UserID   int    `bson:"user_id"`
+	UserName string `bson:"user_name"`
 	GuildID  int    `bson:"guild_id"`
+	GuildName string `bson:"guild_name"`
 	Balance  int64  `bson:"balance"`
+	LastDaily time.Time `bson:"last_daily"`
+	LastBeg  time.Time `bson:"last_beg"`
+	LastGamble time.Time `bson:"last_gamble"`
+	LastTrivia time.Time `bson:"last_trivia"`
 	LastUse  time.Time `bson:"last_use"`
+	LastRob  time.Time `bson:"last_rob"`
+	JailedUntil time.Time `bson:"jailed_until"`
 	MarriedTo int `bson:"married_to"`
+	TriviaWins int `bson:"trivia_wins"`
+	GambleProfit int64 `bson:"gamble_profit"` // Coins won minus coins lost on gamble, lottery and slots
+	RobSuccesses int `bson:"rob_successes"`
+	DailyStreak int `bson:"daily_streak"` // Days in a row the daily has been claimed
+	Achievements []UnlockedAchievement `bson:"achievements"`
+	XP int64 `bson:"xp"`
+	Level int `bson:"level"`
 	Inventory []Item `bson:"inventory"`
+	SchemaVersion int `bson:"schema_version"` // See migrations.go
 }
 
 type Item struct {
 	Name     string `bson:"name"`
 	Quantity int    `bson:"quantity"`
+	Durability int  `bson:"durability"` // Uses left on the one in hand, 0 until it's first used
+	Broken   bool   `bson:"broken"`
+}
+
+// An item as it's shown in the shop
+type ShopListing struct {
+	Item    ShopItem
+	Price   int   // Today's price in the guild, after any deal
+	Deal    bool  // Whether it's one of today's deals
+	History []int // Recent prices, oldest first
+}
+
+// What was bought, sold or given
+type ItemResult struct {
+	Item     string
+	Amount   int
+	Coins    int   // Coins paid or received
+	TargetID int   // Who the item was given to
+	Announcements
+}
+
+// An item in a user's inventory along with what it's needed to display it
+type InventoryItem struct {
+	Item
+	Emoji         string
+	Durability    int // Uses left on the one in hand
+	MaxDurability int // 0 for items that don't wear down
 }
 
-// No return value because we are using the session to add reactions to the message
-func Shop(session *discordgo.Session, message *discordgo.MessageCreate, pageSize int, currentPage int) {
+// Returns every item in the shop, cheapest first
+func Shop(mongoURI string, guildID int) ([]ShopListing, error) {
+	// Connect to MongoDB to get the guild's current prices
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return nil, dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return nil, dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	prices, err := loadPrices(ctx, client.Database(strconv.Itoa(guildID)).Collection("Prices"))
+	if err != nil {
+		return nil, dbError("selecting from database", err)
+	}
+	deals := dailyDeals(guildID)
+
 	// Sort items by price
 	sort.Slice(items, func(i, j int) bool {
 		return items[i].Price < items[j].Price
 	})
 
-	// Check if the currentPage is out of bounds
-	if currentPage < 0 {
-		currentPage = 0
-	} else if currentPage >= len(items)/pageSize {
-		currentPage = len(items) / pageSize - 1
-	}
-
-    // Create a function to get the items for the current page
-	// Make sure it displays the correct number of items and doesn't go out of bounds
-    getPageItems := func() []ShopItem {
-        start := currentPage * pageSize
-        end := start + pageSize
-        if end > len(items) {
-            end = len(items)
-        }
-        return items[start:end]
-    }
-
-	// Create the embed
-    embed := &discordgo.MessageEmbed{
-        Title: "Shop",
-        Color: 0xffc0cb,
-        Footer: &discordgo.MessageEmbedFooter{
-            Text: fmt.Sprintf("Page %d of %d", currentPage+1, len(items)/pageSize),
-        },
-    }
-
-    // Add the items to the embed
-    // Add a field for each item on the page
-	pageItems := getPageItems()
-    for i := range pageItems {
-        item := pageItems[i]
-        field := &discordgo.MessageEmbedField{
-            Name: fmt.Sprintf("%s", item.Name),
-			Value: fmt.Sprintf("Price: %d coins\n%s", item.Price, item.Description),
-            Inline: false,
-        }
-        embed.Fields = append(embed.Fields, field)
-    }
-
-	// Send the embed
-	_, err := session.ChannelMessageSendEmbed(message.ChannelID, embed)
-	if err != nil {
-		return
+	// Add a listing for each item
+	var listings []ShopListing
+	for i := range items {
+		item := items[i]
+		itemPrice := prices[itemKey(item.Name)]
+		listings = append(listings, ShopListing{
+			Item: item,
+			Price: finalPrice(itemPrice, item, deals),
+			Deal: deals[itemKey(item.Name)],
+			History: itemPrice.History,
+		})
 	}
+
+	return listings, nil
 }
 
-func Buy(mongoURI string, guildID int, guildName string, userID int, userName string, item string, amount int) (string) {
+func Buy(mongoURI string, guildID int, guildName string, userID int, userName string, item string, amount int) (ItemResult, error) {
 	// Connect to MongoDB
-	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI))
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
 	if err != nil {
-		fmt.Printf("Error occurred creating MongoDB client! %s\n", err)
-		return "Error occurred creating MongoDB client! " + strings.Title(err.Error())
+		return ItemResult{}, dbError("creating MongoDB client", err)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
 	defer cancel() // Fix for memory leak
 	err = client.Connect(ctx)
 	if err != nil {
-		fmt.Printf("Error occurred while connecting to database! %s\n", err)
-		return "Error occurred while connecting to database! " + strings.Title(err.Error())
+		return ItemResult{}, dbError("connecting to database", err)
 	}
 
 	// Disconnect from database
 	defer client.Disconnect(ctx) // Occurs as last line of main() function
 
 	// Check if user exists in database
-	res := IsPlaying(ctx, client, guildID, guildName, userID, userName)
-	if res != "" {
-		return res
+	err = IsPlaying(ctx, client, guildID, guildName, userID, userName)
+	if err != nil {
+		return ItemResult{}, err
+	}
+
+	// Jailed users are locked out of the economy until they're released or pay bail
+	err = IsJailed(ctx, client, guildID, userID)
+	if err != nil {
+		return ItemResult{}, err
 	}
 
 	// If database for server doesn't exist, create it
@@ -146,46 +210,59 @@ func Buy(mongoURI string, guildID int, guildName string, userID int, userName st
 		},
 	).DecodeBytes()
 	if err != nil {
-		fmt.Printf("Error occurred while selecting from database! %s\n", err)
-		return "Error occurred while selecting from database! " + strings.Title(err.Error())
+		return ItemResult{}, dbError("selecting from database", err)
 	}
 
 	// Get user balance
 	balance, err := collectionResult.LookupErr("balance")
 	if err != nil {
-		fmt.Printf("Error occurred while getting user balance! %s\n", err)
-		return "Error occurred while getting user balance! " + strings.Title(err.Error())
+		return ItemResult{}, dbError("getting user balance", err)
 	}
 
-	// Get price of item specified from items
-	itemPrice := 0
+	// Get the item specified from items
+	shopItemIndex := -1
 	for i := range items {
 		// Ignore the first character of the item name because it is a unicode emoji
 		// Second character is a space
 		pattern := regexp.MustCompile("[^a-zA-Z0-9]+")
 		if strings.ToLower(pattern.ReplaceAllString(items[i].Name, "")) == item {
-			itemPrice = items[i].Price
+			shopItemIndex = i
 			break
 		}
 	}
 
 	// Check if item exists
-	if itemPrice == 0 {
-		return "That item doesn't exist!"
+	if shopItemIndex == -1 {
+		return ItemResult{}, ErrNoSuchItem
 	}
 
+	// Some items can only be bought once the user has levelled up enough
+	if userLevel(collectionResult) < items[shopItemIndex].MinLevel {
+		return ItemResult{}, ErrLevelTooLow{Level: items[shopItemIndex].MinLevel}
+	}
+
+	// Get the guild's current price for the item
+	// Each one bought raises the price of the next, so the order costs more than the listed price times the amount
+	priceCollection := serverDatabase.Collection("Prices")
+	storedItemPrice, err := storedPrice(ctx, priceCollection, items[shopItemIndex])
+	if err != nil {
+		return ItemResult{}, dbError("selecting from database", err)
+	}
+	cost := purchaseCost(storedItemPrice.current(items[shopItemIndex].Price), amount, dailyDeals(guildID)[item])
+
 	// Check if user has enough money
-	if balance.Int64() < int64(itemPrice) * int64(amount) {
-		return "You don't have enough money to buy this item!"
+	if balance.Int64() < cost {
+		return ItemResult{}, ErrCantAfford{Cost: cost, Balance: balance.Int64()}
 	}
 
 	// Check if the user already has this item in their inventory (in which case we +1)
 	// Otherwise, we add the item to their inventory and update their balance
-	_, err = userCollection.UpdateOne(
+	existing, err := userCollection.UpdateOne(
 		ctx,
 		bson.D{
 			{Key: "user_id", Value: userID},
 			{Key: "guild_id", Value: guildID},
+			{Key: "balance", Value: bson.D{{Key: "$gte", Value: cost}}}, // Checked again in case it was spent since
 			{Key: "inventory", Value: bson.D{
 				{Key: "$elemMatch", Value: bson.D{ // Check if the user has the item in their inventory
 					{Key: "name", Value: item}, // If they do, update the quantity
@@ -197,22 +274,22 @@ func Buy(mongoURI string, guildID int, guildName string, userID int, userName st
 				{Key: "inventory.$.quantity", Value: amount}, // Increment the quantity by the amount specified
 			}},
 			{Key: "$inc", Value: bson.D{ // Remember that $dec is not a thing
-				{Key: "balance", Value: -itemPrice * amount}, // Decrement the balance by the price of the item * the amount specified
+				{Key: "balance", Value: -cost}, // Decrement the balance by the cost of the order
 			}},
 		},
 	)
 	if err != nil {
-		fmt.Printf("Error occurred while updating database! %s\n", err)
-		return "Error occurred while updating database! " + strings.Title(err.Error())
+		return ItemResult{}, dbError("updating database", err)
 	}
 
 	// If the user doesn't have the item in their inventory, add it
 	// This will not run if the user already has the item in their inventory because of the $not operator
-	_, err = userCollection.UpdateOne(
+	added, err := userCollection.UpdateOne(
 		ctx,
 		bson.D{
 			{Key: "user_id", Value: userID},
 			{Key: "guild_id", Value: guildID},
+			{Key: "balance", Value: bson.D{{Key: "$gte", Value: cost}}}, // Checked again in case it was spent since
 			{Key: "inventory", Value: bson.D{
 				{Key: "$not", Value: bson.D{ // Check if the user doesn't have the item in their inventory
 					{Key: "$elemMatch", Value: bson.D{ 
@@ -229,41 +306,59 @@ func Buy(mongoURI string, guildID int, guildName string, userID int, userName st
 				}},
 			}},
 			{Key: "$inc", Value: bson.D{
-				{Key: "balance", Value: -itemPrice * amount}, // Decrement the balance by the price of the item * the amount specified
+				{Key: "balance", Value: -cost}, // Decrement the balance by the cost of the order
 			}},
 		},
 	)
 	if err != nil {
-		fmt.Printf("Error occurred while updating database! %s\n", err)
-		return "Error occurred while updating database! " + strings.Title(err.Error())
+		return ItemResult{}, dbError("updating database", err)
+	}
+	// Neither update matched, so another command spent the coins since the balance was checked
+	if existing.MatchedCount + added.MatchedCount == 0 {
+		return ItemResult{}, ErrCantAfford{Cost: cost, Balance: balance.Int64()}
+	}
+
+	// Buying pushes the price up for everyone else
+	err = recordPurchase(ctx, priceCollection, items[shopItemIndex], amount)
+	if err != nil {
+		logging.Error("Error occurred while updating prices!", "guild", guildID, "item", item, "err", err)
+		metrics.ErrorsTotal.Inc("database")
 	}
 
-	return "You have successfully bought " + strconv.Itoa(amount) + "X " + item + " for " + strconv.Itoa(itemPrice * amount) + " coins!"
+	metrics.CoinsBurned.Add("buy", float64(cost))
+	result := ItemResult{Item: item, Amount: amount, Coins: int(cost)}
+	result.unlocked(checkAchievements(ctx, userCollection, guildID, userID, eventItem))
+	result.levelledUp(grantXP(ctx, userCollection, guildID, userID, xpPerAction))
+	return result, nil
 }
 
-func Sell(mongoURI string, guildID int, guildName string, userID int, userName string, item string, amount int) (string) {
+func Sell(mongoURI string, guildID int, guildName string, userID int, userName string, item string, amount int) (ItemResult, error) {
 	// Connect to MongoDB
-	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI))
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
 	if err != nil {
-		fmt.Printf("Error occurred creating MongoDB client! %s\n", err)
-		return "Error occurred creating MongoDB client! " + strings.Title(err.Error())
+		return ItemResult{}, dbError("creating MongoDB client", err)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
 	defer cancel() // Fix for memory leak
 	err = client.Connect(ctx)
 	if err != nil {
-		fmt.Printf("Error occurred while connecting to database! %s\n", err)
-		return "Error occurred while connecting to database! " + strings.Title(err.Error())
+		return ItemResult{}, dbError("connecting to database", err)
 	}
 
 	// Disconnect from database
 	defer client.Disconnect(ctx) // Occurs as last line of main() function
 
 	// Check if user exists in database
-	res := IsPlaying(ctx, client, guildID, guildName, userID, userName)
-	if res != "" {
-		return res
+	err = IsPlaying(ctx, client, guildID, guildName, userID, userName)
+	if err != nil {
+		return ItemResult{}, err
+	}
+
+	// Jailed users are locked out of the economy until they're released or pay bail
+	err = IsJailed(ctx, client, guildID, userID)
+	if err != nil {
+		return ItemResult{}, err
 	}
 
 	// Get user from database
@@ -272,30 +367,26 @@ func Sell(mongoURI string, guildID int, guildName string, userID int, userName s
 	var user User // User struct defined in database.go
 	err = userCollection.FindOne(ctx, filter).Decode(&user)
 	if err != nil {
-		fmt.Printf("Error occurred while finding user in database! %s\n", err)
-		return "Error occurred while finding user in database! " + strings.Title(err.Error())
+		return ItemResult{}, dbError("finding user in database", err)
 	}
 
 	// Check if user has an inventory
 	if len(user.Inventory) == 0 {
-		return "You do not have any items in your inventory!"
+		return ItemResult{}, ErrEmptyInventory
 	}
 
-	// Get price of item specified from items
-	itemPrice := 0
-	for i := range items {
-		// Ignore the first character of the item name because it is a unicode emoji
-		// Second character is a space
-		pattern := regexp.MustCompile(`^\p{So}|\s`)
-		if strings.ToLower(pattern.ReplaceAllString(items[i].Name, "")) == item {
-			itemPrice = items[i].Price
-			break
-		}
+	// Get price of item specified from items (or crafted items)
+	shopItem, ok := FindShopItem(item)
+	if !ok {
+		return ItemResult{}, ErrNoSuchItem
 	}
+	item = itemKey(shopItem.Name)
 
-	// Check if item exists
-	if itemPrice == 0 {
-		return "That item doesn't exist!"
+	// Get the guild's current price for the item
+	priceCollection := client.Database(strconv.Itoa(guildID)).Collection("Prices")
+	storedItemPrice, err := storedPrice(ctx, priceCollection, shopItem)
+	if err != nil {
+		return ItemResult{}, dbError("selecting from database", err)
 	}
 
 	// Check the quantity of the item the user currently has
@@ -309,64 +400,66 @@ func Sell(mongoURI string, guildID int, guildName string, userID int, userName s
 
 	// If the user doesn't have enough, return
 	if itemAmount < amount {
-		return "You don't have enough of that item to sell!"
+		return ItemResult{}, ErrNotEnoughItems
 	}
 
-	// Set the sell price to 50% of the item's price
-	itemPrice = itemPrice / 2
+	// The shop won't buy back broken items, and the one in hand can't go while it's worn
+	for i := range user.Inventory {
+		if user.Inventory[i].Name == item && user.Inventory[i].Broken && amount >= itemAmount {
+			return ItemResult{}, ErrItemBroken{Item: item}
+		} else if user.Inventory[i].Name == item && itemWorn(user.Inventory[i]) && amount >= itemAmount {
+			return ItemResult{}, ErrItemWorn{Item: item}
+		}
+	}
 
-	// Otherwise, let the user sell the item and update their balance
-	_, err = userCollection.UpdateOne(
-		ctx,
-		bson.D{
-			{Key: "user_id", Value: userID},
-			{Key: "guild_id", Value: guildID},
-			{Key: "inventory", Value: bson.D{
-				{Key: "$elemMatch", Value: bson.D{ // Check if the user has the item in their inventory
-					{Key: "name", Value: item}, // If they do, update the quantity
-				}},
-			}},
-		},
-		bson.D{
-			{Key: "$inc", Value: bson.D{ // Remember that $dec is not a thing
-				{Key: "inventory.$.quantity", Value: -amount}, // Decrement the quantity by the amount specified
-			}},
-			{Key: "$inc", Value: bson.D{
-				{Key: "balance", Value: itemPrice * amount}, // Increment the balance by the price of the item * the amount specified
-			}},
-		},
-	)
+	// Pay a percentage of the item's price without today's deal, which falls with each one sold and never counts above the base price
+	value := saleValue(storedItemPrice.current(shopItem.Price), shopItem.Price, amount, config.Current.SellBackPercent)
+
+	// Take the items first, as long as the user still has them, then pay for them
+	ok, err = takeFromInventory(ctx, userCollection, guildID, userID, item, amount)
+	if err != nil {
+		return ItemResult{}, dbError("updating database", err)
+	} else if !ok {
+		// A trade or market listing took some of them since they were checked
+		return ItemResult{}, ErrNotEnoughItems
+	}
+	_, err = adjustBalance(ctx, userCollection, guildID, userID, value)
+	if err != nil {
+		return ItemResult{}, dbError("updating database", err)
+	}
+
+	// Selling brings the price back down for everyone else
+	err = recordSale(ctx, priceCollection, shopItem, amount)
 	if err != nil {
-		fmt.Printf("Error occurred while updating database! %s\n", err)
-		return "Error occurred while updating database! " + strings.Title(err.Error())
+		logging.Error("Error occurred while updating prices!", "guild", guildID, "item", item, "err", err)
+		metrics.ErrorsTotal.Inc("database")
 	}
 
-	return "You have successfully sold " + strconv.Itoa(amount) + "X " + item + " for " + strconv.Itoa(itemPrice * amount) + " coins!"
+	metrics.CoinsMinted.Add("sell", float64(value))
+	return ItemResult{Item: item, Amount: amount, Coins: int(value)}, nil
 }
 
-func Inventory(mongoURI string, guildID int, guildName string, userID int, userName string) (string, *discordgo.MessageEmbed) {
+func Inventory(mongoURI string, guildID int, guildName string, userID int, userName string) ([]InventoryItem, error) {
 	// Connect to MongoDB
-	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI))
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
 	if err != nil {
-		fmt.Printf("Error occurred creating MongoDB client! %s\n", err)
-		return "Error occurred creating MongoDB client! " + strings.Title(err.Error()), nil
+		return nil, dbError("creating MongoDB client", err)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
 	defer cancel() // Fix for memory leak
 	err = client.Connect(ctx)
 	if err != nil {
-		fmt.Printf("Error occurred while connecting to database! %s\n", err)
-		return "Error occurred while connecting to database! " + strings.Title(err.Error()), nil
+		return nil, dbError("connecting to database", err)
 	}
 
 	// Disconnect from database
 	defer client.Disconnect(ctx) // Occurs as last line of main() function
 
 	// Check if user exists in database
-	res := IsPlaying(ctx, client, guildID, guildName, userID, userName)
-	if res != "" {
-		return res, nil
+	err = IsPlaying(ctx, client, guildID, guildName, userID, userName)
+	if err != nil {
+		return nil, err
 	}
 
 	// Get user from database
@@ -375,72 +468,69 @@ func Inventory(mongoURI string, guildID int, guildName string, userID int, userN
 	var user User // User struct defined in database.go
 	err = userCollection.FindOne(ctx, filter).Decode(&user)
 	if err != nil {
-		fmt.Printf("Error occurred while finding user in database! %s\n", err)
-		return "Error occurred while finding user in database! " + strings.Title(err.Error()), nil
+		return nil, dbError("finding user in database", err)
 	}
 
 	// Check if user has an inventory
 	if len(user.Inventory) == 0 {
-		return "You do not have any items in your inventory!", nil
+		return nil, ErrEmptyInventory
 	}
 
-	// Otherwise, return the user's inventory as a rich embed
-	embed := &discordgo.MessageEmbed{
-		Title: userName + "'s Inventory",
-		Color: 0xffc0cb,
-	}
-	
-	// Find the emoji for each item
-	// Add each item to the embed
+	// Find the emoji for each item and how worn down durable items are
+	var inventory []InventoryItem
 	for _, item := range user.Inventory {
-		// Capitalize each item.Name
-		item.Name = strings.Title(item.Name)
-
-		// Get the emoji for the item using emojiLookup map[string]string
-		emoji := emojiLookup[item.Name]
-		
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-			Name: fmt.Sprintf("%s %s", emoji, item.Name),
-			Value: fmt.Sprintf("Quantity: %d", item.Quantity),
-			Inline: true,
+		shopItem, _ := FindShopItem(item.Name)
+		inventory = append(inventory, InventoryItem{
+			Item: item,
+			Emoji: emojiLookup[strings.Title(item.Name)], // Get the emoji for the item using emojiLookup map[string]string
+			Durability: itemDurability(item),
+			MaxDurability: shopItem.MaxDurability,
 		})
 	}
 
-	return "", embed
+	return inventory, nil
 }
 
-func Give(mongoURI string, guildID int, guildName string, userID int, userName string, item string, amount int, pingedUser int) (string) {
+func Give(mongoURI string, guildID int, guildName string, userID int, userName string, item string, amount int, pingedUser int) (ItemResult, error) {
 	// Connect to MongoDB
-	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI))
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
 	if err != nil {
-		fmt.Printf("Error occurred creating MongoDB client! %s\n", err)
-		return "Error occurred creating MongoDB client! " + strings.Title(err.Error())
+		return ItemResult{}, dbError("creating MongoDB client", err)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
 	defer cancel() // Fix for memory leak
 	err = client.Connect(ctx)
 	if err != nil {
-		fmt.Printf("Error occurred while connecting to database! %s\n", err)
-		return "Error occurred while connecting to database! " + strings.Title(err.Error())
+		return ItemResult{}, dbError("connecting to database", err)
 	}
 
 	// Disconnect from database
 	defer client.Disconnect(ctx) // Occurs as last line of main() function
 
 	// Check if user exists in database
-	res := IsPlaying(ctx, client, guildID, guildName, userID, userName)
-	if res != "" {
-		return res
+	err = IsPlaying(ctx, client, guildID, guildName, userID, userName)
+	if err != nil {
+		return ItemResult{}, err
+	}
+
+	// Jailed users are locked out of the economy until they're released or pay bail
+	err = IsJailed(ctx, client, guildID, userID)
+	if err != nil {
+		return ItemResult{}, err
 	}
 
 	// Check if the user has enough of the item to give
 	userCollection := client.Database(strconv.Itoa(guildID)).Collection("Users")
 
 	// Check if pinged user exists in database
-	err = userCollection.FindOne(ctx, bson.M{"guild_id": guildID, "user_id": pingedUser}).Err()
-	if err != nil {
-		return "The user you are trying to give an item to is not playing the game!"
+	// Items only go to users who are playing, so Give never makes a user with only an inventory
+	var pingedUserStruct User
+	err = userCollection.FindOne(ctx, bson.M{"guild_id": guildID, "user_id": pingedUser}).Decode(&pingedUserStruct)
+	if err == mongo.ErrNoDocuments {
+		return ItemResult{}, ErrNotPlaying
+	} else if err != nil {
+		return ItemResult{}, dbError("finding user in database", err)
 	}
 
 	// Get user from database
@@ -448,22 +538,15 @@ func Give(mongoURI string, guildID int, guildName string, userID int, userName s
 	var user User // User struct defined in database.go
 	err = userCollection.FindOne(ctx, filter).Decode(&user)
 	if err != nil {
-		fmt.Printf("Error occurred while finding user in database! %s\n", err)
-		return "Error occurred while finding user in database! " + strings.Title(err.Error())
+		return ItemResult{}, dbError("finding user in database", err)
 	}
 
 	// Check if item exists
-	itemPrice := 0
-	for i := range items {
-		pattern := regexp.MustCompile(`^\p{So}|\s`)
-		if strings.ToLower(pattern.ReplaceAllString(items[i].Name, "")) == item {
-			itemPrice = items[i].Price
-			break
-		}
-	}
-	if itemPrice == 0 {
-		return "That item doesn't exist!"
+	shopItem, ok := FindShopItem(item)
+	if !ok {
+		return ItemResult{}, ErrNoSuchItem
 	}
+	item = itemKey(shopItem.Name)
 
 	// Check if the user has the item in their inventory
 	itemName := ""
@@ -473,12 +556,24 @@ func Give(mongoURI string, guildID int, guildName string, userID int, userName s
 			itemName = inventoryItem.Name
 			itemIndex = i
 			if inventoryItem.Quantity < amount {
-				return "You do not have enough of this item to give!"
+				return ItemResult{}, ErrNotEnoughItems
+			}
+			if inventoryItem.Broken && inventoryItem.Quantity == amount {
+				return ItemResult{}, ErrItemBroken{Item: item}
+			}
+			// Only spares are given away, and the one in hand can't go while it's worn
+			if itemWorn(inventoryItem) && inventoryItem.Quantity == amount {
+				return ItemResult{}, ErrItemWorn{Item: item}
 			}
 		}
 	}
 	if itemName == "" {
-		return "You do not have this item in your inventory!"
+		return ItemResult{}, ErrItemNotFound
+	}
+
+	// The pinged user needs the same level as buying it from the shop
+	if pingedUserStruct.Level < shopItem.MinLevel {
+		return ItemResult{}, ErrLevelTooLow{Level: shopItem.MinLevel, UserID: pingedUser}
 	}
 
 	// Otherwise, update the user's inventory and the pinged user's inventory
@@ -496,49 +591,35 @@ func Give(mongoURI string, guildID int, guildName string, userID int, userName s
 		},
 	)
 	if err != nil {
-		fmt.Printf("Error occurred while updating database! %s\n", err)
-		return "Error occurred while updating database! " + strings.Title(err.Error())
+		return ItemResult{}, dbError("updating database", err)
 	}
 
 	// Update pinged user's inventory
 	pingedUserCollection := client.Database(strconv.Itoa(guildID)).Collection("Users")
 	filter = bson.M{"guild_id": guildID, "user_id": pingedUser}
 
-	// Check if pinged user has an inventory
-	var pingedUserStruct User
-	err = pingedUserCollection.FindOne(ctx, filter).Decode(&pingedUserStruct)
-	if err != nil {
-		// If the user doesn't have an inventory, create a new one with the given item and amount
-		update := bson.M{"$set": bson.M{"inventory": []bson.M{{"name": item, "quantity": amount}}}}
-		opts := options.Update().SetUpsert(true)
-		_, err = pingedUserCollection.UpdateOne(ctx, filter, update, opts)
-		if err != nil {
-			fmt.Printf("Error occurred while updating pinged user's inventory! %s\n", err)
-			return "Error occurred while updating pinged user's inventory! " + strings.Title(err.Error())
+	// Update the quantity of the given item
+	inventory := pingedUserStruct.Inventory
+	itemIndex = -1
+	for i, inventoryItem := range inventory {
+		if inventoryItem.Name == item {
+			itemIndex = i
+			break
 		}
+	}
+	if itemIndex == -1 {
+		// If the user doesn't have the item, add it to their inventory
+		inventory = append(inventory, Item{Name: item, Quantity: amount})
 	} else {
-		// If the user already has an inventory, update the quantity of the given item
-		inventory := pingedUserStruct.Inventory
-		itemIndex := -1
-		for i, inventoryItem := range inventory {
-			if inventoryItem.Name == item {
-				itemIndex = i
-				break
-			}
-		}
-		if itemIndex == -1 {
-			// If the user doesn't have the item, add it to their inventory
-			inventory = append(inventory, Item{Name: item, Quantity: amount})
-		} else {
-			// If the user already has the item, update the quantity
-			inventory[itemIndex].Quantity += amount
-		}
-		update := bson.M{"$set": bson.M{"inventory": inventory}}
-		_, err = pingedUserCollection.UpdateOne(ctx, filter, update)
-		if err != nil {
-			fmt.Printf("Error occurred while updating pinged user's inventory! %s\n", err)
-			return "Error occurred while updating pinged user's inventory! " + strings.Title(err.Error())
-		}
+		// If the user already has the item, update the quantity
+		inventory[itemIndex].Quantity += amount
+	}
+	update := bson.M{"$set": bson.M{"inventory": inventory}}
+	_, err = pingedUserCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return ItemResult{}, dbError("updating pinged user's inventory", err)
 	}
-	return fmt.Sprintf("You gave %dX %s to <@%d>!", amount, item, pingedUser)
+	result := ItemResult{Item: item, Amount: amount, TargetID: pingedUser}
+	result.unlocked(checkAchievements(ctx, userCollection, guildID, pingedUser, eventItem))
+	return result, nil
 }
\ No newline at end of file
@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+	"mary-bot/metrics"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// How long a failed robber spends in jail, and how much it costs per minute left to get out early
+const (
+	robJailTime = 10 * time.Minute
+	bailCostPerMinute = 50
+)
+
+// Not a command
+// A helper function that checks if the user is currently in jail
+// If they are, it returns ErrJailed with how long they have left
+func IsJailed(ctx context.Context, client *mongo.Client, guildID int, userID int) (error) {
+	userCollection := client.Database(strconv.Itoa(guildID)).Collection("Users")
+	filter := bson.M{"guild_id": guildID, "user_id": userID}
+	var user User // User struct defined in items.go
+	err := userCollection.FindOne(ctx, filter).Decode(&user)
+	if err != nil {
+		return dbError("selecting from database", err)
+	}
+
+	if time.Now().Before(user.JailedUntil) {
+		return ErrJailed{Remaining: time.Until(user.JailedUntil)}
+	}
+	return nil
+}
+
+// mary bail
+// Bail is its own function because jailed users are blocked from the rest of the economy
+// Returns the bail that was paid (or owed, if they couldn't pay it)
+func Bail(mongoURI string, guildID int, guildName string, userID int, userName string) (int64, error) {
+	// Connect to MongoDB
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return 0, dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return 0, dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	// Check if user exists in database
+	err = IsPlaying(ctx, client, guildID, guildName, userID, userName)
+	if err != nil {
+		return 0, err
+	}
+
+	// Get user from database
+	userCollection := client.Database(strconv.Itoa(guildID)).Collection("Users")
+	filter := bson.M{"guild_id": guildID, "user_id": userID}
+	var user User // User struct defined in items.go
+	err = userCollection.FindOne(ctx, filter).Decode(&user)
+	if err != nil {
+		return 0, dbError("finding user in database", err)
+	}
+
+	// Check if the user is actually in jail
+	if !time.Now().Before(user.JailedUntil) {
+		return 0, ErrNotJailed
+	}
+
+	// Bail is charged for every minute (rounded up) left on the sentence
+	minutesLeft := int64(math.Ceil(time.Until(user.JailedUntil).Minutes()))
+	bail := minutesLeft * bailCostPerMinute
+	if user.Balance < bail {
+		return 0, ErrCantAfford{Cost: bail, Balance: user.Balance}
+	}
+
+	// Only take the bail if the user still has enough by the time the update runs
+	result := userCollection.FindOneAndUpdate(
+		ctx,
+		bson.D{
+			{Key: "user_id", Value: userID},
+			{Key: "guild_id", Value: guildID},
+			{Key: "balance", Value: bson.D{{Key: "$gte", Value: bail}}},
+		},
+		bson.D{
+			{Key: "$inc", Value: bson.D{
+				{Key: "balance", Value: -bail},
+			}},
+			{Key: "$set", Value: bson.D{
+				{Key: "jailed_until", Value: time.Now()},
+			}},
+		},
+	)
+	if result.Err() == mongo.ErrNoDocuments {
+		// Their balance dropped since it was checked
+		return bail, ErrInsufficientFunds
+	} else if result.Err() != nil {
+		return 0, dbError("updating database", result.Err())
+	}
+	metrics.CoinsBurned.Add("bail", float64(bail))
+
+	logRobbery(ctx, userCollection, RobLog{
+		RobberID: userID,
+		Outcome: "bail",
+		Amount: bail,
+		Time: time.Now(),
+	})
+	return bail, nil
+}
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"
+	"strconv"
+	"time"
+	"mary-bot/metrics"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// A language picked with `mary language`
+// The server's own language is stored with a user ID of 0
+type LanguageSetting struct {
+	UserID   int    `bson:"user_id"`
+	Language string `bson:"language"`
+}
+
+// Not a command
+// The language to reply to a user in: theirs if they picked one, otherwise the server's, otherwise ""
+func Language(mongoURI string, guildID int, userID int) (string, error) {
+	// Connect to MongoDB
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return "", dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return "", dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	return userLanguage(ctx, client.Database(strconv.Itoa(guildID)), userID)
+}
+
+// Not a command
+// Looks up the language for Language and Preferences
+func userLanguage(ctx context.Context, serverDatabase *mongo.Database, userID int) (string, error) {
+	languageCollection := serverDatabase.Collection("Languages")
+
+	// Get the user's and the server's settings in one go
+	cursor, err := languageCollection.Find(ctx, bson.D{{Key: "user_id", Value: bson.D{{Key: "$in", Value: bson.A{userID, 0}}}}})
+	if err != nil {
+		return "", dbError("selecting from database", err)
+	}
+	var settings []LanguageSetting
+	err = cursor.All(ctx, &settings)
+	if err != nil {
+		return "", dbError("decoding result", err)
+	}
+
+	language := ""
+	for _, setting := range settings {
+		if setting.UserID == userID {
+			return setting.Language, nil
+		}
+		language = setting.Language
+	}
+	return language, nil
+}
+
+// mary language [code/default] -> sets the language the user is replied to in
+// mary language server [code] -> sets it for the whole server, with a user ID of 0
+// An empty language goes back to the server's (or the default, for the server)
+func SetLanguage(mongoURI string, guildID int, userID int, language string) (error) {
+	// Connect to MongoDB
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	languageCollection := client.Database(strconv.Itoa(guildID)).Collection("Languages")
+
+	if language == "" {
+		_, err = languageCollection.DeleteOne(ctx, bson.D{{Key: "user_id", Value: userID}})
+	} else {
+		_, err = languageCollection.UpdateOne(
+			ctx,
+			bson.D{{Key: "user_id", Value: userID}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "language", Value: language}}}},
+			options.Update().SetUpsert(true),
+		)
+	}
+	if err != nil {
+		return dbError("updating database", err)
+	}
+	forgetLanguage(guildID, userID)
+	return nil
+}
@@ -0,0 +1,284 @@
+package database
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+	"mary-bot/metrics"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// A way of ranking users on the leaderboard
+type Ranking struct {
+	Name  string      // What the user types, e.g. "networth"
+	Title string      // Shown on the leaderboard, e.g. "Net Worth"
+	Unit  string      // Shown after each score, e.g. "coins"
+	field string      // The stored field the score comes from, or "" if it has to be worked out
+	score interface{} // Aggregation expression for the score
+}
+
+// Define the rankings
+// Rankings on a stored field can be sorted straight from the index
+var rankings = []Ranking{
+	{"wallet", "Wallet", "coins", "balance", bson.D{{Key: "$ifNull", Value: bson.A{"$balance", 0}}}},
+	{"networth", "Net Worth", "coins", "", netWorthScore()},
+	{"trivia", "Trivia Wins", "wins", "trivia_wins", bson.D{{Key: "$ifNull", Value: bson.A{"$trivia_wins", 0}}}},
+	{"gambling", "Gambling Profit", "coins", "gamble_profit", bson.D{{Key: "$ifNull", Value: bson.A{"$gamble_profit", 0}}}},
+}
+
+// Other names users might type for the rankings
+var rankingAliases = map[string]string{
+	"": "wallet",
+	"balance": "wallet",
+	"bal": "wallet",
+	"worth": "networth",
+	"net": "networth",
+	"quiz": "trivia",
+	"gamble": "gambling",
+}
+
+type LeaderboardEntry struct {
+	Rank   int
+	UserID int
+	Name   string
+	Score  int64
+}
+
+// One page of a leaderboard, plus where the caller stands on it
+type LeaderboardPage struct {
+	Ranking Ranking
+	Entries []LeaderboardEntry
+	Total   int               // Number of users on the whole leaderboard
+	Caller  *LeaderboardEntry // nil if the caller isn't playing
+}
+
+// Finds a ranking by name or alias
+func findRanking(name string) (Ranking, bool) {
+	name = strings.ToLower(name)
+	if alias, ok := rankingAliases[name]; ok {
+		name = alias
+	}
+	for _, ranking := range rankings {
+		if ranking.Name == name {
+			return ranking, true
+		}
+	}
+	return Ranking{}, false
+}
+
+// Helper to list the ranking names for error messages, e.g. "`wallet`, `networth`"
+func RankingNames() (string) {
+	names := []string{}
+	for _, ranking := range rankings {
+		names = append(names, "`" + ranking.Name + "`")
+	}
+	return strings.Join(names, ", ")
+}
+
+// Not a command
+// Balance plus what everything in the inventory is worth at its base shop price
+func netWorthScore() (interface{}) {
+	branches := bson.A{}
+	for _, shopItem := range append(append([]ShopItem{}, items...), craftedItems...) {
+		branches = append(branches, bson.D{
+			{Key: "case", Value: bson.D{{Key: "$eq", Value: bson.A{"$$item.name", itemKey(shopItem.Name)}}}},
+			{Key: "then", Value: shopItem.Price},
+		})
+	}
+	return bson.D{
+		{Key: "$add", Value: bson.A{
+			bson.D{{Key: "$ifNull", Value: bson.A{"$balance", 0}}},
+			bson.D{{Key: "$sum", Value: bson.D{
+				{Key: "$map", Value: bson.D{
+					{Key: "input", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$inventory", bson.A{}}}}},
+					{Key: "as", Value: "item"},
+					{Key: "in", Value: bson.D{
+						{Key: "$multiply", Value: bson.A{
+							"$$item.quantity",
+							bson.D{{Key: "$switch", Value: bson.D{
+								{Key: "branches", Value: branches},
+								{Key: "default", Value: 0},
+							}}},
+						}},
+					}},
+				}},
+			}}},
+		}},
+	}
+}
+
+// Not a command
+// Makes sure the rankings on stored fields have an index to sort with
+// Created along with the unique index in userIndexes, when a guild is migrated or gets its first user
+func ensureLeaderboardIndexes(ctx context.Context, userCollection *mongo.Collection) (error) {
+	models := []mongo.IndexModel{}
+	for _, ranking := range rankings {
+		if ranking.field == "" {
+			continue
+		}
+		models = append(models, mongo.IndexModel{
+			Keys: bson.D{
+				{Key: "guild_id", Value: 1},
+				{Key: ranking.field, Value: -1},
+				{Key: "user_id", Value: 1},
+			},
+		})
+	}
+	_, err := userCollection.Indexes().CreateMany(ctx, models)
+	return err
+}
+
+// Not a command
+// The start of every leaderboard pipeline: the guild's users with their score
+func scoredUsers(guildID int, ranking Ranking) (mongo.Pipeline) {
+	return mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: "guild_id", Value: guildID}}}},
+		{{Key: "$addFields", Value: bson.D{{Key: "score", Value: ranking.score}}}},
+	}
+}
+
+// mary top/leaderboard [ranking]
+// Gets one page of the leaderboard, worked out by the database
+func Leaderboard(mongoURI string, guildID int, userID int, rankingName string, page int, pageSize int) (LeaderboardPage, error) {
+	ranking, ok := findRanking(rankingName)
+	if !ok {
+		return LeaderboardPage{}, ErrNoSuchRanking
+	}
+
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return LeaderboardPage{}, dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err = client.Connect(ctx)
+	if err != nil {
+		return LeaderboardPage{}, dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx)
+
+	userCollection := client.Database(strconv.Itoa(guildID)).Collection("Users")
+
+	total, err := userCollection.CountDocuments(ctx, bson.D{{Key: "guild_id", Value: guildID}})
+	if err != nil {
+		return LeaderboardPage{}, dbError("selecting from database", err)
+	}
+	if page < 0 {
+		page = 0
+	}
+
+	// Ties are broken by user ID so that every user has exactly one rank
+	// Stored fields are sorted before the score is added so the index can be used
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: "guild_id", Value: guildID}}}},
+	}
+	if ranking.field != "" {
+		pipeline = append(pipeline,
+			bson.D{{Key: "$sort", Value: bson.D{{Key: ranking.field, Value: -1}, {Key: "user_id", Value: 1}}}},
+			bson.D{{Key: "$skip", Value: page * pageSize}},
+			bson.D{{Key: "$limit", Value: pageSize}},
+			bson.D{{Key: "$addFields", Value: bson.D{{Key: "score", Value: ranking.score}}}},
+		)
+	} else {
+		pipeline = append(scoredUsers(guildID, ranking),
+			bson.D{{Key: "$sort", Value: bson.D{{Key: "score", Value: -1}, {Key: "user_id", Value: 1}}}},
+			bson.D{{Key: "$skip", Value: page * pageSize}},
+			bson.D{{Key: "$limit", Value: pageSize}},
+		)
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$project", Value: bson.D{
+		{Key: "user_id", Value: 1},
+		{Key: "user_name", Value: 1},
+		{Key: "score", Value: bson.D{{Key: "$toLong", Value: "$score"}}},
+	}}})
+
+	cursor, err := userCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return LeaderboardPage{}, dbError("selecting from database", err)
+	}
+	var results []struct {
+		UserID   int    `bson:"user_id"`
+		UserName string `bson:"user_name"`
+		Score    int64  `bson:"score"`
+	}
+	err = cursor.All(ctx, &results)
+	if err != nil {
+		return LeaderboardPage{}, dbError("decoding result", err)
+	}
+
+	leaderboard := LeaderboardPage{Ranking: ranking, Total: int(total)}
+	for i, result := range results {
+		leaderboard.Entries = append(leaderboard.Entries, LeaderboardEntry{
+			Rank: page * pageSize + i + 1,
+			UserID: result.UserID,
+			Name: result.UserName,
+			Score: result.Score,
+		})
+	}
+
+	// Find the caller's own rank, even if they're not on this page
+	caller, err := leaderboardRank(ctx, userCollection, guildID, userID, ranking)
+	if err != nil {
+		return LeaderboardPage{}, dbError("selecting from database", err)
+	}
+	leaderboard.Caller = caller
+	return leaderboard, nil
+}
+
+// Not a command
+// Works out a single user's rank by counting everyone ahead of them
+// Returns nil if the user isn't playing
+func leaderboardRank(ctx context.Context, userCollection *mongo.Collection, guildID int, userID int, ranking Ranking) (*LeaderboardEntry, error) {
+	// Get the user's own score
+	cursor, err := userCollection.Aggregate(ctx, append(
+		mongo.Pipeline{{{Key: "$match", Value: bson.D{{Key: "guild_id", Value: guildID}, {Key: "user_id", Value: userID}}}}},
+		bson.D{{Key: "$project", Value: bson.D{
+			{Key: "user_name", Value: 1},
+			{Key: "score", Value: bson.D{{Key: "$toLong", Value: ranking.score}}},
+		}}},
+	))
+	if err != nil {
+		return nil, err
+	}
+	var own []struct {
+		UserName string `bson:"user_name"`
+		Score    int64  `bson:"score"`
+	}
+	err = cursor.All(ctx, &own)
+	if err != nil || len(own) == 0 {
+		return nil, err
+	}
+
+	// Count everyone with a higher score, or the same score and a lower user ID
+	cursor, err = userCollection.Aggregate(ctx, append(scoredUsers(guildID, ranking),
+		bson.D{{Key: "$match", Value: bson.D{
+			{Key: "$or", Value: bson.A{
+				bson.D{{Key: "score", Value: bson.D{{Key: "$gt", Value: own[0].Score}}}},
+				bson.D{{Key: "score", Value: own[0].Score}, {Key: "user_id", Value: bson.D{{Key: "$lt", Value: userID}}}},
+			}},
+		}}},
+		bson.D{{Key: "$count", Value: "ahead"}},
+	))
+	if err != nil {
+		return nil, err
+	}
+	var ahead []struct {
+		Ahead int `bson:"ahead"`
+	}
+	err = cursor.All(ctx, &ahead)
+	if err != nil {
+		return nil, err
+	}
+
+	rank := 1
+	if len(ahead) > 0 {
+		rank += ahead[0].Ahead
+	}
+	return &LeaderboardEntry{Rank: rank, UserID: userID, Name: own[0].UserName, Score: own[0].Score}, nil
+}
@@ -0,0 +1,384 @@
+package database
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+	"mary-bot/logging"
+	"mary-bot/metrics"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// How XP is handed out
+const (
+	minMessageXP = 5
+	maxMessageXP = 15
+	messageXPCooldown = time.Minute // Only one message a minute counts, so spamming doesn't help
+	xpPerAction = 10 // For each economy command that goes through
+	xpPerLevel = 100 // Reaching level n takes xpPerLevel * n^2 XP in total
+)
+
+// Called when a user reaches a level with a role reward
+// Set by main, since giving roles needs the Discord session
+var AssignRole func(guildID int, userID int, roleID string) (error)
+
+// A role given out when users reach a level in a guild, stored in the guild's "LevelRoles" collection
+type LevelRole struct {
+	Level  int    `bson:"level"`
+	RoleID string `bson:"role_id"`
+}
+
+// When each user last got XP for a message, keyed by "guildID:userID"
+// Entries older than messageXPCooldown don't matter any more and are pruned, so it only holds recent chatters
+var (
+	messageXPTimes  = map[string]time.Time{}
+	messageXPPruned time.Time
+	messageXPLock   sync.Mutex
+)
+
+// Not a command
+// Whether a message from the user counts for XP now, and if it does, starts their cooldown
+func messageXPReady(key string, now time.Time) (bool) {
+	messageXPLock.Lock()
+	defer messageXPLock.Unlock()
+
+	// Once per cooldown is enough to keep the map down to the users who chatted in the last two
+	if now.Sub(messageXPPruned) >= messageXPCooldown {
+		for other, last := range messageXPTimes {
+			if now.Sub(last) >= messageXPCooldown {
+				delete(messageXPTimes, other)
+			}
+		}
+		messageXPPruned = now
+	}
+
+	if last, ok := messageXPTimes[key]; ok && now.Sub(last) < messageXPCooldown {
+		return false
+	}
+	messageXPTimes[key] = now
+	return true
+}
+
+// Helper to work out the level for an amount of XP
+func levelForXP(xp int64) (int) {
+	return int(math.Sqrt(float64(xp) / xpPerLevel))
+}
+
+// Helper to work out the total XP needed to reach a level
+func xpForLevel(level int) (int64) {
+	return int64(xpPerLevel * level * level)
+}
+
+// A user reaching a new level, and the role rewards they were given for it
+type LevelUp struct {
+	UserID  int
+	Level   int
+	RoleIDs []string
+}
+
+// Not a command
+// Gives the user XP and levels them up if they've earned it
+// Returns the level up (with any role rewards), or nil if they didn't level up
+// Errors are only logged because XP failing shouldn't undo what the user just did
+func grantXP(ctx context.Context, userCollection *mongo.Collection, guildID int, userID int, amount int) (*LevelUp) {
+	var user struct {
+		XP    int64 `bson:"xp"`
+		Level int   `bson:"level"`
+	}
+	err := userCollection.FindOneAndUpdate(
+		ctx,
+		bson.D{
+			{Key: "user_id", Value: userID},
+			{Key: "guild_id", Value: guildID},
+		},
+		bson.D{
+			{Key: "$inc", Value: bson.D{
+				{Key: "xp", Value: int64(amount)},
+			}},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		return nil // Not playing, so no XP
+	} else if err != nil {
+		logging.Error("Error occurred while granting XP!", "guild", guildID, "user", userID, "err", err)
+		metrics.ErrorsTotal.Inc("levels")
+		return nil
+	}
+
+	newLevel := levelForXP(user.XP)
+	if newLevel <= user.Level {
+		return nil
+	}
+
+	// Only the update that actually raises the level announces it, in case two commands level up at once
+	result, err := userCollection.UpdateOne(
+		ctx,
+		bson.D{
+			{Key: "user_id", Value: userID},
+			{Key: "guild_id", Value: guildID},
+			{Key: "$or", Value: bson.A{
+				bson.D{{Key: "level", Value: bson.D{{Key: "$lt", Value: newLevel}}}},
+				bson.D{{Key: "level", Value: bson.D{{Key: "$exists", Value: false}}}}, // Users from before levels existed
+			}},
+		},
+		bson.D{
+			{Key: "$set", Value: bson.D{
+				{Key: "level", Value: newLevel},
+			}},
+		},
+	)
+	if err != nil || result.ModifiedCount == 0 {
+		if err != nil {
+			logging.Error("Error occurred while updating level!", "guild", guildID, "user", userID, "err", err)
+			metrics.ErrorsTotal.Inc("levels")
+		}
+		return nil
+	}
+	levelUp := &LevelUp{UserID: userID, Level: newLevel}
+
+	// Hand out the role rewards for every level they just passed
+	roleCollection := userCollection.Database().Collection("LevelRoles")
+	cursor, err := roleCollection.Find(ctx, bson.D{
+		{Key: "level", Value: bson.D{
+			{Key: "$gt", Value: user.Level},
+			{Key: "$lte", Value: newLevel},
+		}},
+	})
+	if err != nil {
+		logging.Error("Error occurred while selecting from database!", "guild", guildID, "user", userID, "err", err)
+		metrics.ErrorsTotal.Inc("levels")
+		return levelUp
+	}
+	var roles []LevelRole
+	err = cursor.All(ctx, &roles)
+	if err != nil {
+		logging.Error("Error occurred while decoding result!", "guild", guildID, "user", userID, "err", err)
+		metrics.ErrorsTotal.Inc("levels")
+		return levelUp
+	}
+	for _, role := range roles {
+		if AssignRole == nil {
+			break
+		}
+		err = AssignRole(guildID, userID, role.RoleID)
+		if err != nil {
+			logging.Error("Error occurred while giving level role!", "guild", guildID, "user", userID, "role", role.RoleID, "err", err)
+			metrics.ErrorsTotal.Inc("discord")
+			continue
+		}
+		levelUp.RoleIDs = append(levelUp.RoleIDs, role.RoleID)
+	}
+	return levelUp
+}
+
+// Not a command
+// Helper to get a user's level from their raw document
+func userLevel(document bson.Raw) (int) {
+	level, ok := document.Lookup("level").AsInt64OK()
+	if !ok {
+		return 0
+	}
+	return int(level)
+}
+
+// Not a command
+// The level the user needs to own the item, or 0 if they're already high enough
+// The shop checks levels itself, this is for the market, trades and gifts, so they can't get around it
+func itemLevelNeeded(ctx context.Context, userCollection *mongo.Collection, guildID int, userID int, item string) (int, error) {
+	shopItem, ok := FindShopItem(item)
+	if !ok || shopItem.MinLevel == 0 {
+		return 0, nil
+	}
+	document, err := userCollection.FindOne(
+		ctx,
+		bson.D{
+			{Key: "user_id", Value: userID},
+			{Key: "guild_id", Value: guildID},
+		},
+	).DecodeBytes()
+	if err != nil {
+		return 0, err
+	}
+	if userLevel(document) < shopItem.MinLevel {
+		return shopItem.MinLevel, nil
+	}
+	return 0, nil
+}
+
+// Gives a user XP for chatting, at most once a minute
+// Only users who are already playing get XP, chatting doesn't sign anyone up
+// Returns nil unless they levelled up
+func GrantMessageXP(mongoURI string, guildID int, userID int) (*LevelUp) {
+	if !messageXPReady(strconv.Itoa(guildID) + ":" + strconv.Itoa(userID), time.Now()) {
+		return nil
+	}
+
+	// Connect to MongoDB
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		logging.Error("Error occurred creating MongoDB client!", "guild", guildID, "user", userID, "err", err)
+		metrics.ErrorsTotal.Inc("database")
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		logging.Error("Error occurred while connecting to database!", "guild", guildID, "user", userID, "err", err)
+		metrics.ErrorsTotal.Inc("database")
+		return nil
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	userCollection := client.Database(strconv.Itoa(guildID)).Collection("Users")
+	return grantXP(ctx, userCollection, guildID, userID, minMessageXP + rand.Intn(maxMessageXP - minMessageXP + 1))
+}
+
+// A user's level, XP and server rank
+type RankCard struct {
+	Level       int
+	XP          int64
+	LevelXP     int64      // Total XP needed for the current level
+	NextLevelXP int64      // Total XP needed for the next level
+	Rank        int        // Position in the server by XP
+	NextReward  *LevelRole // nil if there are no rewards left to reach
+}
+
+// mary rank
+func Rank(mongoURI string, guildID int, guildName string, userID int, userName string) (RankCard, error) {
+	// Connect to MongoDB
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return RankCard{}, dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return RankCard{}, dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	// Check if user exists in database
+	err = IsPlaying(ctx, client, guildID, guildName, userID, userName)
+	if err != nil {
+		return RankCard{}, err
+	}
+
+	serverDatabase := client.Database(strconv.Itoa(guildID))
+	userCollection := serverDatabase.Collection("Users")
+	var user struct {
+		XP    int64 `bson:"xp"`
+		Level int   `bson:"level"`
+	}
+	err = userCollection.FindOne(ctx, bson.D{
+		{Key: "user_id", Value: userID},
+		{Key: "guild_id", Value: guildID},
+	}).Decode(&user)
+	if err != nil {
+		return RankCard{}, dbError("finding user in database", err)
+	}
+
+	// Server rank is everyone with more XP, plus one
+	ahead, err := userCollection.CountDocuments(ctx, bson.D{
+		{Key: "guild_id", Value: guildID},
+		{Key: "xp", Value: bson.D{{Key: "$gt", Value: user.XP}}},
+	})
+	if err != nil {
+		return RankCard{}, dbError("selecting from database", err)
+	}
+
+	card := RankCard{
+		Level: user.Level,
+		XP: user.XP,
+		LevelXP: xpForLevel(user.Level),
+		NextLevelXP: xpForLevel(user.Level + 1),
+		Rank: int(ahead) + 1,
+	}
+
+	// Find the next role reward, if there is one
+	var nextRole LevelRole
+	err = serverDatabase.Collection("LevelRoles").FindOne(
+		ctx,
+		bson.D{{Key: "level", Value: bson.D{{Key: "$gt", Value: user.Level}}}},
+		options.FindOne().SetSort(bson.D{{Key: "level", Value: 1}}),
+	).Decode(&nextRole)
+	if err == nil {
+		card.NextReward = &nextRole
+	}
+	return card, nil
+}
+
+// mary levelrole [level] @role / mary levelrole remove [level] / mary levelrole
+// Admins choose roles to hand out at levels, level 0 just lists them
+// Returns every reward once the change has been made, lowest level first
+func LevelRoles(mongoURI string, guildID int, level int, roleID string, remove bool) ([]LevelRole, error) {
+	// Connect to MongoDB
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return nil, dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return nil, dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	roleCollection := client.Database(strconv.Itoa(guildID)).Collection("LevelRoles")
+
+	if level < 0 || (level == 0 && (remove || roleID != "")) {
+		return nil, ErrNotPositive
+	} else if level > 0 && remove {
+		result, err := roleCollection.DeleteOne(ctx, bson.D{{Key: "level", Value: level}})
+		if err != nil {
+			return nil, dbError("updating database", err)
+		}
+		if result.DeletedCount == 0 {
+			return nil, ErrNoLevelRole{Level: level}
+		}
+	} else if level > 0 {
+		// One role per level, so setting it again replaces the old one
+		_, err = roleCollection.UpdateOne(
+			ctx,
+			bson.D{{Key: "level", Value: level}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "role_id", Value: roleID}}}},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			return nil, dbError("updating database", err)
+		}
+	}
+
+	// List the rewards
+	cursor, err := roleCollection.Find(ctx, bson.D{})
+	if err != nil {
+		return nil, dbError("selecting from database", err)
+	}
+	var roles []LevelRole
+	err = cursor.All(ctx, &roles)
+	if err != nil {
+		return nil, dbError("decoding result", err)
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].Level < roles[j].Level
+	})
+	return roles, nil
+}
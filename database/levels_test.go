@@ -0,0 +1,66 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLevelForXP(t *testing.T) {
+	tests := []struct {
+		xp   int64
+		want int
+	}{
+		{0, 0},
+		{99, 0},
+		{100, 1},
+		{399, 1},
+		{400, 2},
+		{10000, 10},
+	}
+	for _, test := range tests {
+		if got := levelForXP(test.xp); got != test.want {
+			t.Errorf("levelForXP(%d) = %d, want %d", test.xp, got, test.want)
+		}
+	}
+}
+
+func TestXPForLevel(t *testing.T) {
+	for level := 0; level <= 50; level++ {
+		xp := xpForLevel(level)
+		if got := levelForXP(xp); got != level {
+			t.Errorf("levelForXP(xpForLevel(%d)) = %d", level, got)
+		}
+		if level > 0 && levelForXP(xp - 1) != level - 1 {
+			t.Errorf("levelForXP(xpForLevel(%d) - 1) = %d, want %d", level, levelForXP(xp - 1), level - 1)
+		}
+	}
+}
+
+func TestMessageXPReady(t *testing.T) {
+	messageXPTimes = map[string]time.Time{}
+	messageXPPruned = time.Time{}
+	start := time.Now()
+
+	tests := []struct {
+		name  string
+		key   string
+		after time.Duration
+		want  bool
+	}{
+		{"first message", "1:1", 0, true},
+		{"too soon", "1:1", messageXPCooldown / 2, false},
+		{"someone else", "1:2", messageXPCooldown / 2, true},
+		{"after the cooldown", "1:1", messageXPCooldown, true},
+	}
+	for _, test := range tests {
+		if got := messageXPReady(test.key, start.Add(test.after)); got != test.want {
+			t.Errorf("%s: messageXPReady(%q) = %v, want %v", test.name, test.key, got, test.want)
+		}
+	}
+
+	// Users who stopped chatting are pruned
+	messageXPReady("1:3", start.Add(3 * messageXPCooldown))
+	if len(messageXPTimes) != 1 {
+		t.Errorf("messageXPTimes has %d entries, want 1", len(messageXPTimes))
+	}
+}
@@ -0,0 +1,558 @@
+package database
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+	"mary-bot/logging"
+	"mary-bot/metrics"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// How long fixed-price listings stay up, and the limits on auction length
+const (
+	listingDuration = 24 * time.Hour
+	maxAuctionDuration = 7 * 24 * time.Hour
+	marketPageSize = 5
+)
+
+// A player listing in the guild's "Market" collection
+// The items are held in escrow by the listing until it's bought, cancelled or expires
+type Listing struct {
+	ListingID       int       `bson:"listing_id"`
+	SellerID        int       `bson:"seller_id"`
+	SellerName      string    `bson:"seller_name"`
+	Item            string    `bson:"item"`
+	Quantity        int       `bson:"quantity"`
+	Price           int64     `bson:"price"` // Asking price, or the starting price for auctions
+	Auction         bool      `bson:"auction"`
+	HighestBid      int64     `bson:"highest_bid"` // Held in escrow until the auction ends or they're outbid
+	HighestBidderID int       `bson:"highest_bidder_id"`
+	Status          string    `bson:"status"` // "open", "sold", "expired" or "cancelled"
+	CreatedAt       time.Time `bson:"created_at"`
+	ExpiresAt       time.Time `bson:"expires_at"`
+}
+
+// A page of open listings
+type MarketPage struct {
+	Listings   []Listing
+	Page       int // Starts at 0
+	TotalPages int
+}
+
+// What happened to a listing after listing, buying, bidding or cancelling
+type MarketResult struct {
+	Listing
+	Bid      int64 // The bid that was placed
+	OutbidID int   // Who was outbid, if anyone
+	Announcements
+}
+
+// Not a command
+// Gets the next listing number for the guild so that listings have short IDs users can type
+func nextListingID(ctx context.Context, serverDatabase *mongo.Database) (int, error) {
+	var counter struct {
+		Value int `bson:"value"`
+	}
+	err := serverDatabase.Collection("Counters").FindOneAndUpdate(
+		ctx,
+		bson.D{{Key: "_id", Value: "listing_id"}},
+		bson.D{{Key: "$inc", Value: bson.D{{Key: "value", Value: 1}}}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&counter)
+	return counter.Value, err
+}
+
+// Not a command
+// Settles every listing that has run out of time
+// Unsold items go back to the seller, finished auctions go to the highest bidder and their bid goes to the seller
+func settleMarket(ctx context.Context, client *mongo.Client, guildID int) (error) {
+	serverDatabase := client.Database(strconv.Itoa(guildID))
+	marketCollection := serverDatabase.Collection("Market")
+	userCollection := serverDatabase.Collection("Users")
+
+	cursor, err := marketCollection.Find(ctx, bson.D{
+		{Key: "status", Value: "open"},
+		{Key: "expires_at", Value: bson.D{{Key: "$lte", Value: time.Now()}}},
+	})
+	if err != nil {
+		return dbError("selecting from database", err)
+	}
+	var expired []Listing
+	err = cursor.All(ctx, &expired)
+	if err != nil {
+		return dbError("decoding result", err)
+	}
+
+	for _, listing := range expired {
+		listing := listing
+		err := runTransaction(ctx, client, func(sessCtx mongo.SessionContext) error {
+			status := "expired"
+			if listing.Auction && listing.HighestBidderID != 0 {
+				status = "sold"
+			}
+
+			// Claim the listing so that it only gets settled once
+			result, err := marketCollection.UpdateOne(
+				sessCtx,
+				bson.D{
+					{Key: "listing_id", Value: listing.ListingID},
+					{Key: "status", Value: "open"},
+				},
+				bson.D{{Key: "$set", Value: bson.D{{Key: "status", Value: status}}}},
+			)
+			if err != nil || result.MatchedCount == 0 {
+				return err
+			}
+
+			if status == "sold" {
+				_, err = adjustBalance(sessCtx, userCollection, guildID, listing.SellerID, listing.HighestBid)
+				if err != nil {
+					return err
+				}
+				return addToInventory(sessCtx, userCollection, guildID, listing.HighestBidderID, listing.Item, listing.Quantity)
+			}
+			return addToInventory(sessCtx, userCollection, guildID, listing.SellerID, listing.Item, listing.Quantity)
+		})
+		if err != nil {
+			return err
+		}
+		logging.Info("Settled market listing", "guild", guildID, "listing", listing.ListingID)
+	}
+	return nil
+}
+
+// mary market list [item] [quantity] [price]
+// mary market auction [item] [quantity] [starting price] [minutes]
+func MarketList(mongoURI string, guildID int, guildName string, userID int, userName string, item string, quantity int, price int, auction bool, duration time.Duration) (MarketResult, error) {
+	if quantity < 1 || price < 1 {
+		return MarketResult{}, ErrNotPositive
+	} else if auction && (duration < time.Minute || duration > maxAuctionDuration) {
+		return MarketResult{}, ErrAuctionLength{Max: maxAuctionDuration}
+	}
+
+	// Check if item exists
+	shopItem, ok := FindShopItem(item)
+	if !ok {
+		return MarketResult{}, ErrNoSuchItem
+	}
+	item = itemKey(shopItem.Name)
+
+	// Connect to MongoDB
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return MarketResult{}, dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return MarketResult{}, dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	// Check if user exists in database
+	err = IsPlaying(ctx, client, guildID, guildName, userID, userName)
+	if err != nil {
+		return MarketResult{}, err
+	}
+
+	// Jailed users are locked out of the economy until they're released or pay bail
+	err = IsJailed(ctx, client, guildID, userID)
+	if err != nil {
+		return MarketResult{}, err
+	}
+
+	// Clear out anything that has expired first
+	err = settleMarket(ctx, client, guildID)
+	if err != nil {
+		return MarketResult{}, err
+	}
+
+	serverDatabase := client.Database(strconv.Itoa(guildID))
+	userCollection := serverDatabase.Collection("Users")
+	marketCollection := serverDatabase.Collection("Market")
+
+	// Broken items can't be sold to other players either, and worn ones have to be repaired first
+	var user User // User struct defined in items.go
+	err = userCollection.FindOne(ctx, bson.M{"guild_id": guildID, "user_id": userID}).Decode(&user)
+	if err != nil {
+		return MarketResult{}, dbError("finding user in database", err)
+	}
+	for _, inventoryItem := range user.Inventory {
+		if inventoryItem.Name == item && inventoryItem.Broken && inventoryItem.Quantity <= quantity {
+			return MarketResult{}, ErrItemBroken{Item: item}
+		} else if inventoryItem.Name == item && itemWorn(inventoryItem) && inventoryItem.Quantity <= quantity {
+			return MarketResult{}, ErrItemWorn{Item: item}
+		}
+	}
+
+	listingID, err := nextListingID(ctx, serverDatabase)
+	if err != nil {
+		return MarketResult{}, dbError("updating database", err)
+	}
+	if !auction {
+		duration = listingDuration
+	}
+
+	// Move the items out of the seller's inventory and into the listing
+	listing := Listing{
+		ListingID: listingID,
+		SellerID: userID,
+		SellerName: userName,
+		Item: item,
+		Quantity: quantity,
+		Price: int64(price),
+		Auction: auction,
+		Status: "open",
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(duration),
+	}
+	err = runTransaction(ctx, client, func(sessCtx mongo.SessionContext) error {
+		ok, err := takeFromInventory(sessCtx, userCollection, guildID, userID, item, quantity)
+		if err != nil {
+			return err
+		} else if !ok {
+			return transactionAbort{ErrNotEnoughItems}
+		}
+		_, err = marketCollection.InsertOne(sessCtx, listing)
+		return err
+	})
+	if err != nil {
+		return MarketResult{}, err
+	}
+
+	return MarketResult{Listing: listing}, nil
+}
+
+// mary market [page]
+func MarketBrowse(mongoURI string, guildID int, page int) (MarketPage, error) {
+	// Connect to MongoDB
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return MarketPage{}, dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return MarketPage{}, dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	// Clear out anything that has expired before showing the listings
+	err = settleMarket(ctx, client, guildID)
+	if err != nil {
+		return MarketPage{}, err
+	}
+
+	marketCollection := client.Database(strconv.Itoa(guildID)).Collection("Market")
+	filter := bson.D{{Key: "status", Value: "open"}}
+	total, err := marketCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return MarketPage{}, dbError("selecting from database", err)
+	}
+	if total == 0 {
+		return MarketPage{}, ErrMarketEmpty
+	}
+
+	// Check if the page is out of bounds
+	totalPages := int(math.Ceil(float64(total) / marketPageSize))
+	if page < 0 {
+		page = 0
+	} else if page >= totalPages {
+		page = totalPages - 1
+	}
+
+	cursor, err := marketCollection.Find(
+		ctx,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "listing_id", Value: 1}}).SetSkip(int64(page * marketPageSize)).SetLimit(marketPageSize),
+	)
+	if err != nil {
+		return MarketPage{}, dbError("selecting from database", err)
+	}
+	var listings []Listing
+	err = cursor.All(ctx, &listings)
+	if err != nil {
+		return MarketPage{}, dbError("decoding result", err)
+	}
+
+	return MarketPage{Listings: listings, Page: page, TotalPages: totalPages}, nil
+}
+
+// mary market buy [listing]
+func MarketBuy(mongoURI string, guildID int, guildName string, userID int, userName string, listingID int) (MarketResult, error) {
+	// Connect to MongoDB
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return MarketResult{}, dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return MarketResult{}, dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	// Check if user exists in database
+	err = IsPlaying(ctx, client, guildID, guildName, userID, userName)
+	if err != nil {
+		return MarketResult{}, err
+	}
+
+	// Jailed users are locked out of the economy until they're released or pay bail
+	err = IsJailed(ctx, client, guildID, userID)
+	if err != nil {
+		return MarketResult{}, err
+	}
+
+	// Clear out anything that has expired first
+	err = settleMarket(ctx, client, guildID)
+	if err != nil {
+		return MarketResult{}, err
+	}
+
+	serverDatabase := client.Database(strconv.Itoa(guildID))
+	userCollection := serverDatabase.Collection("Users")
+	marketCollection := serverDatabase.Collection("Market")
+
+	// Mark the listing as sold, pay the seller and hand over the items all at once
+	var listing Listing
+	err = runTransaction(ctx, client, func(sessCtx mongo.SessionContext) error {
+		err := marketCollection.FindOneAndUpdate(
+			sessCtx,
+			bson.D{
+				{Key: "listing_id", Value: listingID},
+				{Key: "status", Value: "open"},
+				{Key: "expires_at", Value: bson.D{{Key: "$gt", Value: time.Now()}}},
+			},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "status", Value: "sold"}}}},
+		).Decode(&listing)
+		if err == mongo.ErrNoDocuments {
+			return transactionAbort{ErrListingNotFound}
+		} else if err != nil {
+			return err
+		}
+
+		if listing.Auction {
+			return transactionAbort{ErrWrongListingType{ListingID: listingID, Auction: true}}
+		} else if listing.SellerID == userID {
+			return transactionAbort{ErrOwnListing{ListingID: listingID, Auction: false}}
+		}
+
+		// Level-gated items need the same level as buying them from the shop
+		level, err := itemLevelNeeded(sessCtx, userCollection, guildID, userID, listing.Item)
+		if err != nil {
+			return err
+		} else if level > 0 {
+			return transactionAbort{ErrLevelTooLow{Level: level}}
+		}
+
+		ok, err := transferCoins(sessCtx, userCollection, guildID, userID, listing.SellerID, listing.Price)
+		if err == ErrNotPlaying {
+			return transactionAbort{err}
+		} else if err != nil {
+			return err
+		} else if !ok {
+			return transactionAbort{ErrInsufficientFunds}
+		}
+		return addToInventory(sessCtx, userCollection, guildID, userID, listing.Item, listing.Quantity)
+	})
+	if err != nil {
+		return MarketResult{}, err
+	}
+
+	result := MarketResult{Listing: listing}
+	result.unlocked(checkAchievements(ctx, userCollection, guildID, userID, eventItem))
+	return result, nil
+}
+
+// mary market bid [listing] [amount]
+func MarketBid(mongoURI string, guildID int, guildName string, userID int, userName string, listingID int, amount int) (MarketResult, error) {
+	// Connect to MongoDB
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return MarketResult{}, dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return MarketResult{}, dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	// Check if user exists in database
+	err = IsPlaying(ctx, client, guildID, guildName, userID, userName)
+	if err != nil {
+		return MarketResult{}, err
+	}
+
+	// Jailed users are locked out of the economy until they're released or pay bail
+	err = IsJailed(ctx, client, guildID, userID)
+	if err != nil {
+		return MarketResult{}, err
+	}
+
+	// Clear out anything that has expired first
+	err = settleMarket(ctx, client, guildID)
+	if err != nil {
+		return MarketResult{}, err
+	}
+
+	serverDatabase := client.Database(strconv.Itoa(guildID))
+	userCollection := serverDatabase.Collection("Users")
+	marketCollection := serverDatabase.Collection("Market")
+
+	// Hold the new bid in escrow and refund whoever was outbid
+	var listing Listing
+	err = runTransaction(ctx, client, func(sessCtx mongo.SessionContext) error {
+		err := marketCollection.FindOne(
+			sessCtx,
+			bson.D{
+				{Key: "listing_id", Value: listingID},
+				{Key: "status", Value: "open"},
+				{Key: "expires_at", Value: bson.D{{Key: "$gt", Value: time.Now()}}},
+			},
+		).Decode(&listing)
+		if err == mongo.ErrNoDocuments {
+			return transactionAbort{ErrAuctionNotFound}
+		} else if err != nil {
+			return err
+		}
+
+		if !listing.Auction {
+			return transactionAbort{ErrWrongListingType{ListingID: listingID, Auction: false}}
+		} else if listing.SellerID == userID {
+			return transactionAbort{ErrOwnListing{ListingID: listingID, Auction: true}}
+		} else if listing.HighestBidderID == userID {
+			return transactionAbort{ErrAlreadyHighestBidder}
+		}
+
+		// Checked when bidding, since the winner gets the items when the auction ends
+		level, err := itemLevelNeeded(sessCtx, userCollection, guildID, userID, listing.Item)
+		if err != nil {
+			return err
+		} else if level > 0 {
+			return transactionAbort{ErrLevelTooLow{Level: level}}
+		}
+
+		// The first bid has to meet the starting price, later bids have to beat the highest one
+		minimumBid := listing.Price
+		if listing.HighestBidderID != 0 {
+			minimumBid = listing.HighestBid + 1
+		}
+		if int64(amount) < minimumBid {
+			return transactionAbort{ErrBidTooLow{Minimum: minimumBid}}
+		}
+
+		// Only update the listing if nobody else has bid in the meantime
+		result, err := marketCollection.UpdateOne(
+			sessCtx,
+			bson.D{
+				{Key: "listing_id", Value: listingID},
+				{Key: "status", Value: "open"},
+				{Key: "highest_bidder_id", Value: listing.HighestBidderID},
+				{Key: "highest_bid", Value: listing.HighestBid},
+			},
+			bson.D{{Key: "$set", Value: bson.D{
+				{Key: "highest_bidder_id", Value: userID},
+				{Key: "highest_bid", Value: int64(amount)},
+			}}},
+		)
+		if err != nil {
+			return err
+		} else if result.MatchedCount == 0 {
+			return transactionAbort{ErrBidRace}
+		}
+
+		ok, err := adjustBalance(sessCtx, userCollection, guildID, userID, -int64(amount))
+		if err != nil {
+			return err
+		} else if !ok {
+			return transactionAbort{ErrInsufficientFunds}
+		}
+		if listing.HighestBidderID != 0 {
+			_, err = adjustBalance(sessCtx, userCollection, guildID, listing.HighestBidderID, listing.HighestBid)
+		}
+		return err
+	})
+	if err != nil {
+		return MarketResult{}, err
+	}
+
+	return MarketResult{Listing: listing, Bid: int64(amount), OutbidID: listing.HighestBidderID}, nil
+}
+
+// mary market cancel [listing]
+func MarketCancel(mongoURI string, guildID int, guildName string, userID int, userName string, listingID int) (MarketResult, error) {
+	// Connect to MongoDB
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return MarketResult{}, dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return MarketResult{}, dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	// Check if user exists in database
+	err = IsPlaying(ctx, client, guildID, guildName, userID, userName)
+	if err != nil {
+		return MarketResult{}, err
+	}
+
+	serverDatabase := client.Database(strconv.Itoa(guildID))
+	userCollection := serverDatabase.Collection("Users")
+	marketCollection := serverDatabase.Collection("Market")
+
+	// Take the listing down and give the items back
+	// Auctions that already have a bid can't be cancelled
+	var listing Listing
+	err = runTransaction(ctx, client, func(sessCtx mongo.SessionContext) error {
+		err := marketCollection.FindOneAndUpdate(
+			sessCtx,
+			bson.D{
+				{Key: "listing_id", Value: listingID},
+				{Key: "seller_id", Value: userID},
+				{Key: "status", Value: "open"},
+				{Key: "highest_bidder_id", Value: 0},
+			},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "status", Value: "cancelled"}}}},
+		).Decode(&listing)
+		if err == mongo.ErrNoDocuments {
+			return transactionAbort{ErrCantCancel}
+		} else if err != nil {
+			return err
+		}
+		return addToInventory(sessCtx, userCollection, guildID, userID, listing.Item, listing.Quantity)
+	})
+	if err != nil {
+		return MarketResult{}, err
+	}
+
+	return MarketResult{Listing: listing}, nil
+}
@@ -0,0 +1,367 @@
+package database
+
+import (
+	"context"
+	"strconv"
+	"time"
+	"mary-bot/logging"
+	"mary-bot/metrics"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// The version of the user documents this code writes, stored on each user as schema_version
+// Bump it and add a migration below whenever the fields of a user change
+const schemaVersion = 3
+
+// A change to every user document, run once per document in version order
+// Documents from before schema_version existed count as version 0
+type migration struct {
+	version     int
+	description string
+	run         func(ctx context.Context, userCollection *mongo.Collection) (int64, error) // Returns the number of documents changed
+}
+
+var migrations = []migration{
+	{1, "backfill missing fields", backfillDefaults},
+	{2, "store coin amounts as int64", fixInt64s},
+	{3, "merge duplicate users", removeDuplicates},
+}
+
+// Not a command
+// Users were made with different fields by IsPlaying, Economy's "insert" and Give's upsert,
+// and fields like married_to and last_use were only added when first used
+func backfillDefaults(ctx context.Context, userCollection *mongo.Collection) (int64, error) {
+	// The same fields new users get, but without the names, which aren't known here
+	defaults := withoutFields(newUser("", "", 0), "schema_version")
+
+	var changed int64
+	for _, field := range defaults {
+		result, err := userCollection.UpdateMany(
+			ctx,
+			bson.D{{Key: field.Key, Value: bson.D{{Key: "$exists", Value: false}}}},
+			bson.D{{Key: "$set", Value: bson.D{field}}},
+		)
+		if err != nil {
+			return changed, dbError("updating database", err)
+		}
+		changed += result.ModifiedCount
+	}
+	return changed, nil
+}
+
+// Not a command
+// Coin amounts decode into int64, but older code and hand edits stored some as int32 or doubles
+func fixInt64s(ctx context.Context, userCollection *mongo.Collection) (int64, error) {
+	var changed int64
+	for _, field := range []string{"balance", "gamble_profit", "xp"} {
+		result, err := userCollection.UpdateMany(
+			ctx,
+			bson.D{{Key: field, Value: bson.D{{Key: "$type", Value: bson.A{"int", "double", "decimal"}}}}},
+			// An update pipeline, so the new value can come from the old one
+			mongo.Pipeline{{{Key: "$set", Value: bson.D{{Key: field, Value: bson.D{{Key: "$toLong", Value: "$" + field}}}}}}},
+		)
+		if err != nil {
+			return changed, dbError("updating database", err)
+		}
+		changed += result.ModifiedCount
+	}
+	return changed, nil
+}
+
+// Not a command
+// The IDs of every guild with a database, which are named after the guild's ID
+func guildIDs(ctx context.Context, client *mongo.Client) ([]int, error) {
+	names, err := client.ListDatabaseNames(ctx, bson.D{})
+	if err != nil {
+		return nil, dbError("listing databases", err)
+	}
+	ids := []int{}
+	for _, name := range names {
+		// Skips MongoDB's own databases and the global leaderboard's
+		if id, err := strconv.Atoi(name); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// Not a command
+// Before the unique index, two commands from a new user at the same time could insert them twice
+// Merges the copies into the oldest one, which is the one lookups found first, and deletes the rest
+// Nothing is lost, since coins and items earned on any copy end up on the one that's kept
+func removeDuplicates(ctx context.Context, userCollection *mongo.Collection) (int64, error) {
+	cursor, err := userCollection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "guild_id", Value: "$guild_id"}, {Key: "user_id", Value: "$user_id"}}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+		{{Key: "$match", Value: bson.D{{Key: "count", Value: bson.D{{Key: "$gt", Value: 1}}}}}},
+	})
+	if err != nil {
+		return 0, dbError("selecting from database", err)
+	}
+	var duplicates []struct {
+		Key struct {
+			GuildID int `bson:"guild_id"`
+			UserID  int `bson:"user_id"`
+		} `bson:"_id"`
+	}
+	err = cursor.All(ctx, &duplicates)
+	if err != nil {
+		return 0, dbError("decoding result", err)
+	}
+
+	var changed int64
+	for _, duplicate := range duplicates {
+		// Oldest first
+		cursor, err := userCollection.Find(
+			ctx,
+			bson.D{{Key: "guild_id", Value: duplicate.Key.GuildID}, {Key: "user_id", Value: duplicate.Key.UserID}},
+			options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}),
+		)
+		if err != nil {
+			return changed, dbError("selecting from database", err)
+		}
+		var copies []struct {
+			ID         interface{}   `bson:"_id"`
+			MergedFrom []interface{} `bson:"merged_from"` // Copies already merged into this one by a run that stopped before deleting them
+			User       `bson:",inline"`
+		}
+		err = cursor.All(ctx, &copies)
+		if err != nil {
+			return changed, dbError("decoding result", err)
+		}
+		if len(copies) < 2 {
+			continue // Already merged by another run
+		}
+
+		users := []User{copies[0].User}
+		extraIDs := bson.A{}
+		for _, copy := range copies[1:] {
+			extraIDs = append(extraIDs, copy.ID)
+			alreadyMerged := false
+			for _, id := range copies[0].MergedFrom {
+				if id == copy.ID {
+					alreadyMerged = true
+				}
+			}
+			if !alreadyMerged {
+				users = append(users, copy.User)
+			}
+		}
+		merged := mergeUsers(users)
+
+		// Merge first and remember which copies were merged, so stopping before the delete
+		// neither loses their coins nor adds them twice when this runs again
+		_, err = userCollection.UpdateOne(
+			ctx,
+			bson.D{{Key: "_id", Value: copies[0].ID}},
+			bson.D{{Key: "$set", Value: bson.D{
+				{Key: "balance", Value: merged.Balance},
+				{Key: "gamble_profit", Value: merged.GambleProfit},
+				{Key: "trivia_wins", Value: merged.TriviaWins},
+				{Key: "rob_successes", Value: merged.RobSuccesses},
+				{Key: "daily_streak", Value: merged.DailyStreak},
+				{Key: "xp", Value: merged.XP},
+				{Key: "level", Value: merged.Level},
+				{Key: "married_to", Value: merged.MarriedTo},
+				{Key: "last_daily", Value: merged.LastDaily},
+				{Key: "last_beg", Value: merged.LastBeg},
+				{Key: "last_gamble", Value: merged.LastGamble},
+				{Key: "last_trivia", Value: merged.LastTrivia},
+				{Key: "last_use", Value: merged.LastUse},
+				{Key: "last_rob", Value: merged.LastRob},
+				{Key: "jailed_until", Value: merged.JailedUntil},
+				{Key: "achievements", Value: merged.Achievements},
+				{Key: "inventory", Value: merged.Inventory},
+				{Key: "merged_from", Value: extraIDs},
+			}}},
+		)
+		if err != nil {
+			return changed, dbError("updating database", err)
+		}
+		result, err := userCollection.DeleteMany(ctx, bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: extraIDs}}}})
+		if err != nil {
+			return changed, dbError("updating database", err)
+		}
+		_, err = userCollection.UpdateOne(ctx, bson.D{{Key: "_id", Value: copies[0].ID}}, bson.D{{Key: "$unset", Value: bson.D{{Key: "merged_from", Value: ""}}}})
+		if err != nil {
+			return changed, dbError("updating database", err)
+		}
+		logging.Info("Merged duplicate user", "guild", duplicate.Key.GuildID, "user", duplicate.Key.UserID, "copies", len(copies), "balance", merged.Balance)
+		changed += result.DeletedCount
+	}
+	return changed, nil
+}
+
+// Not a command
+// Combines copies of the same user into the first one
+// Coins, counts and items are added up, achievements are combined, XP and streaks take the highest,
+// and cooldowns and jail take the latest, so merging never resets a timer
+func mergeUsers(users []User) (User) {
+	merged := users[0]
+	merged.Inventory = append([]Item{}, users[0].Inventory...)
+	merged.Achievements = append([]UnlockedAchievement{}, users[0].Achievements...)
+	for _, user := range users[1:] {
+		merged.Balance += user.Balance
+		merged.GambleProfit += user.GambleProfit
+		merged.TriviaWins += user.TriviaWins
+		merged.RobSuccesses += user.RobSuccesses
+		if user.DailyStreak > merged.DailyStreak {
+			merged.DailyStreak = user.DailyStreak
+		}
+		if user.XP > merged.XP {
+			merged.XP = user.XP
+		}
+		if user.Level > merged.Level {
+			merged.Level = user.Level
+		}
+		if merged.MarriedTo == 0 {
+			merged.MarriedTo = user.MarriedTo
+		}
+		for _, pair := range []struct{ merged *time.Time; other time.Time }{
+			{&merged.LastDaily, user.LastDaily},
+			{&merged.LastBeg, user.LastBeg},
+			{&merged.LastGamble, user.LastGamble},
+			{&merged.LastTrivia, user.LastTrivia},
+			{&merged.LastUse, user.LastUse},
+			{&merged.LastRob, user.LastRob},
+			{&merged.JailedUntil, user.JailedUntil},
+		} {
+			if pair.other.After(*pair.merged) {
+				*pair.merged = pair.other
+			}
+		}
+
+		for _, achievement := range user.Achievements {
+			found := false
+			for i := range merged.Achievements {
+				if merged.Achievements[i].ID == achievement.ID {
+					found = true
+					if achievement.UnlockedAt.Before(merged.Achievements[i].UnlockedAt) {
+						merged.Achievements[i].UnlockedAt = achievement.UnlockedAt
+					}
+				}
+			}
+			if !found {
+				merged.Achievements = append(merged.Achievements, achievement)
+			}
+		}
+
+		// The kept copy's item in hand stays in hand, unless it's broken and the other copy's isn't
+		for _, item := range user.Inventory {
+			found := false
+			for i := range merged.Inventory {
+				if merged.Inventory[i].Name == item.Name {
+					found = true
+					merged.Inventory[i].Quantity += item.Quantity
+					if merged.Inventory[i].Broken && !item.Broken {
+						merged.Inventory[i].Broken = false
+						merged.Inventory[i].Durability = item.Durability
+					}
+				}
+			}
+			if !found {
+				merged.Inventory = append(merged.Inventory, item)
+			}
+		}
+	}
+	return merged
+}
+
+// Not a command
+// Every user lookup filters on both IDs, and each user can only be in a guild once
+// Also creates the leaderboard's indexes, so every index a guild needs is made in one place
+// Creating an index that already exists does nothing, so this is called whenever a guild might be new
+func userIndexes(ctx context.Context, userCollection *mongo.Collection) (error) {
+	index := mongo.IndexModel{
+		Keys:    bson.D{{Key: "guild_id", Value: 1}, {Key: "user_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+	_, err := userCollection.Indexes().CreateOne(ctx, index)
+	// Older versions made the same index without unique, which has to go first
+	if commandError, ok := err.(mongo.CommandError); ok && commandError.Name == "IndexOptionsConflict" {
+		_, err = userCollection.Indexes().DropOne(ctx, "guild_id_1_user_id_1")
+		if err != nil {
+			return dbError("dropping index", err)
+		}
+		_, err = userCollection.Indexes().CreateOne(ctx, index)
+	}
+	if err != nil {
+		return dbError("creating index", err)
+	}
+
+	// The leaderboard sorts by these, see leaderboard.go
+	err = ensureLeaderboardIndexes(ctx, userCollection)
+	if err != nil {
+		return dbError("creating leaderboard indexes", err)
+	}
+	return nil
+}
+
+// Not a command
+// Brings one guild's users up to schemaVersion
+func migrateGuild(ctx context.Context, serverDatabase *mongo.Database) (error) {
+	userCollection := serverDatabase.Collection("Users")
+	for _, m := range migrations {
+		// $not $gte rather than $lt, so documents without schema_version match too
+		behind := bson.D{{Key: "schema_version", Value: bson.D{{Key: "$not", Value: bson.D{{Key: "$gte", Value: m.version}}}}}}
+		count, err := userCollection.CountDocuments(ctx, behind)
+		if err != nil {
+			return dbError("selecting from database", err)
+		}
+		if count == 0 {
+			continue
+		}
+
+		// Every migration only touches documents that still need it, so a run that stops halfway can just be run again
+		changed, err := m.run(ctx, userCollection)
+		if err != nil {
+			return err
+		}
+		_, err = userCollection.UpdateMany(ctx, behind, bson.D{{Key: "$set", Value: bson.D{{Key: "schema_version", Value: m.version}}}})
+		if err != nil {
+			return dbError("updating database", err)
+		}
+		logging.Info("Migrated users", "guild", serverDatabase.Name(), "version", m.version, "migration", m.description, "users", count, "changed", changed)
+	}
+
+	// After the migrations, so there are no duplicates left to break the unique index
+	return userIndexes(ctx, userCollection)
+}
+
+// mary-bot migrate, and every time the bot starts
+// Brings every guild's database up to date, returning the IDs of the guilds that were migrated
+// Safe to run more than once, guilds that are already up to date are left alone
+func Migrate(mongoURI string) ([]int, error) {
+	// Connect to MongoDB
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return nil, dbError("creating MongoDB client", err)
+	}
+
+	// Every guild is migrated in one go, so this gets longer than the usual 10 secs
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return nil, dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	ids, err := guildIDs(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	for _, guildID := range ids {
+		err = migrateGuild(ctx, client.Database(strconv.Itoa(guildID)))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeUsers(t *testing.T) {
+	now := time.Now()
+	kept := User{
+		Balance: 100,
+		XP: 50,
+		Level: 1,
+		LastDaily: now.Add(-time.Hour),
+		Achievements: []UnlockedAchievement{{ID: "first_buy", UnlockedAt: now}},
+		Inventory: []Item{{Name: "gun", Quantity: 1, Durability: 2, Broken: true}},
+	}
+	other := User{
+		Balance: 250,
+		XP: 80,
+		Level: 2,
+		MarriedTo: 42,
+		LastDaily: now,
+		Achievements: []UnlockedAchievement{{ID: "first_buy", UnlockedAt: now.Add(-time.Hour)}, {ID: "rich", UnlockedAt: now}},
+		Inventory: []Item{{Name: "gun", Quantity: 2, Durability: 4}, {Name: "ring", Quantity: 1}},
+	}
+
+	merged := mergeUsers([]User{kept, other})
+
+	if merged.Balance != 350 {
+		t.Errorf("Balance = %d, want 350", merged.Balance)
+	}
+	if merged.XP != 80 || merged.Level != 2 {
+		t.Errorf("XP, Level = %d, %d, want 80, 2", merged.XP, merged.Level)
+	}
+	if merged.MarriedTo != 42 {
+		t.Errorf("MarriedTo = %d, want 42", merged.MarriedTo)
+	}
+	if !merged.LastDaily.Equal(now) {
+		t.Errorf("LastDaily = %v, want the latest %v", merged.LastDaily, now)
+	}
+	if len(merged.Achievements) != 2 || !merged.Achievements[0].UnlockedAt.Equal(now.Add(-time.Hour)) {
+		t.Errorf("Achievements = %v, want both with the earliest unlock", merged.Achievements)
+	}
+	want := []Item{{Name: "gun", Quantity: 3, Durability: 4}, {Name: "ring", Quantity: 1}}
+	if len(merged.Inventory) != len(want) {
+		t.Fatalf("Inventory = %v, want %v", merged.Inventory, want)
+	}
+	for i := range want {
+		if merged.Inventory[i] != want[i] {
+			t.Errorf("Inventory[%d] = %v, want %v", i, merged.Inventory[i], want[i])
+		}
+	}
+
+	// The copies passed in are left alone
+	if kept.Inventory[0].Quantity != 1 {
+		t.Errorf("merging changed the kept copy's inventory")
+	}
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+	"mary-bot/config"
+	"mary-bot/metrics"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// How long languages and server settings are kept before being looked up again
+// `mary language` and `mary config set` clear what they change straight away,
+// this only matters for changes made elsewhere, e.g. from the command line
+const preferencesTTL = 5 * time.Minute
+
+// A cached language or server settings, and when it was looked up
+type cachedLanguage struct {
+	language string
+	fetched  time.Time
+}
+
+type cachedSettings struct {
+	settings config.Config
+	fetched  time.Time
+}
+
+// A user in a guild, since the same user can pick a different language in each
+type languageKey struct {
+	guildID int
+	userID  int
+}
+
+var (
+	languageCache     = map[languageKey]cachedLanguage{}
+	settingsCache     = map[int]cachedSettings{}
+	preferencesPruned time.Time
+	preferencesLock   sync.Mutex
+)
+
+// Not a command
+// The language to reply to a user in and the server's settings, which every command needs before it runs
+// Both are cached, so most commands don't have to connect to the database for them,
+// and when they do, both are looked up with the one connection
+func Preferences(mongoURI string, guildID int, userID int) (string, config.Config, error) {
+	language, settings, languageOK, settingsOK := cachedPreferences(guildID, userID, time.Now())
+	if languageOK && settingsOK {
+		return language, settings, nil
+	}
+
+	// Connect to MongoDB
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return language, config.Current, dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return language, config.Current, dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	serverDatabase := client.Database(strconv.Itoa(guildID))
+	if !languageOK {
+		language, err = userLanguage(ctx, serverDatabase, userID)
+		if err != nil {
+			return "", config.Current, err
+		}
+	}
+	if !settingsOK {
+		settings, err = guildSettings(ctx, serverDatabase)
+		if err != nil {
+			return language, config.Current, err
+		}
+	}
+
+	preferencesLock.Lock()
+	defer preferencesLock.Unlock()
+	// Only what was just looked up, so nothing stays cached longer than preferencesTTL
+	now := time.Now()
+	if !languageOK {
+		languageCache[languageKey{guildID, userID}] = cachedLanguage{language, now}
+	}
+	if !settingsOK {
+		settingsCache[guildID] = cachedSettings{settings, now}
+	}
+	return language, settings, nil
+}
+
+// Not a command
+// Whatever's cached for the user and the guild, and whether each of them was
+// Drops everything that's expired once per preferencesTTL, so the caches only hold users who were recently active
+func cachedPreferences(guildID int, userID int, now time.Time) (string, config.Config, bool, bool) {
+	preferencesLock.Lock()
+	defer preferencesLock.Unlock()
+
+	if now.Sub(preferencesPruned) >= preferencesTTL {
+		for key, cached := range languageCache {
+			if now.Sub(cached.fetched) >= preferencesTTL {
+				delete(languageCache, key)
+			}
+		}
+		for key, cached := range settingsCache {
+			if now.Sub(cached.fetched) >= preferencesTTL {
+				delete(settingsCache, key)
+			}
+		}
+		preferencesPruned = now
+	}
+
+	language, languageOK := languageCache[languageKey{guildID, userID}]
+	if !languageOK || now.Sub(language.fetched) >= preferencesTTL {
+		language, languageOK = cachedLanguage{}, false
+	}
+	settings, settingsOK := settingsCache[guildID]
+	if !settingsOK || now.Sub(settings.fetched) >= preferencesTTL {
+		settings, settingsOK = cachedSettings{}, false
+	}
+	return language.language, settings.settings, languageOK, settingsOK
+}
+
+// Not a command
+// Clears the cached language after it changes
+// The server's language (user ID 0) is what everyone without their own falls back to, so it clears the whole guild
+func forgetLanguage(guildID int, userID int) {
+	preferencesLock.Lock()
+	defer preferencesLock.Unlock()
+	for key := range languageCache {
+		if key.guildID == guildID && (userID == 0 || key.userID == userID) {
+			delete(languageCache, key)
+		}
+	}
+}
+
+// Not a command
+// Clears the cached settings after `mary config set`
+func forgetSettings(guildID int) {
+	preferencesLock.Lock()
+	defer preferencesLock.Unlock()
+	delete(settingsCache, guildID)
+}
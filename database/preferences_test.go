@@ -0,0 +1,65 @@
+package database
+
+import (
+	"testing"
+	"time"
+	"mary-bot/config"
+)
+
+func TestCachedPreferences(t *testing.T) {
+	now := time.Now()
+	languageCache = map[languageKey]cachedLanguage{
+		{1, 10}: {"es", now},
+		{1, 11}: {"en", now},
+		{2, 10}: {"en", now.Add(-preferencesTTL)}, // Expired
+	}
+	settingsCache = map[int]cachedSettings{1: {config.Config{ShopPageSize: 7}, now}}
+	preferencesPruned = now
+
+	tests := []struct {
+		name         string
+		guildID      int
+		userID       int
+		wantLanguage string
+		wantPageSize int
+		languageOK   bool
+		settingsOK   bool
+	}{
+		{"both cached", 1, 10, "es", 7, true, true},
+		{"expired", 2, 10, "", 0, false, false},
+		{"never looked up", 3, 10, "", 0, false, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			language, settings, languageOK, settingsOK := cachedPreferences(test.guildID, test.userID, now)
+			if language != test.wantLanguage || settings.ShopPageSize != test.wantPageSize || languageOK != test.languageOK || settingsOK != test.settingsOK {
+				t.Errorf("cachedPreferences(%d, %d) = %q, %d, %v, %v, want %q, %d, %v, %v", test.guildID, test.userID,
+					language, settings.ShopPageSize, languageOK, settingsOK,
+					test.wantLanguage, test.wantPageSize, test.languageOK, test.settingsOK)
+			}
+		})
+	}
+
+	// Changing the server's language clears everyone in it, changing a user's only clears them
+	forgetLanguage(1, 11)
+	if _, ok := languageCache[languageKey{1, 11}]; ok {
+		t.Errorf("forgetLanguage(1, 11) left the user cached")
+	}
+	if _, ok := languageCache[languageKey{1, 10}]; !ok {
+		t.Errorf("forgetLanguage(1, 11) cleared another user")
+	}
+	forgetLanguage(1, 0)
+	if _, ok := languageCache[languageKey{1, 10}]; ok {
+		t.Errorf("forgetLanguage(1, 0) left a user in the guild cached")
+	}
+	forgetSettings(1)
+	if _, _, _, ok := cachedPreferences(1, 10, now); ok {
+		t.Errorf("forgetSettings(1) left the settings cached")
+	}
+
+	// Expired entries are pruned
+	cachedPreferences(1, 10, now.Add(2 * preferencesTTL))
+	if len(languageCache) != 0 {
+		t.Errorf("languageCache has %d entries after they expired, want 0", len(languageCache))
+	}
+}
@@ -0,0 +1,189 @@
+package database
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// How shop prices move
+// Every item bought raises the price a little, every item sold lowers it, and the difference from the base price halves every few hours
+const (
+	priceIncreasePerUnit = 0.02
+	priceHalfLife = 6 * time.Hour
+	priceHistoryLength = 12
+	dealsPerDay = 2
+)
+
+// How much is taken off today's deals
+const DealDiscount = 0.2
+
+// The price of a shop item in a guild, stored in the guild's "Prices" collection
+// Items nobody has bought yet don't have a document and sit at their base price
+type ItemPrice struct {
+	Item      string    `bson:"item"`
+	Price     float64   `bson:"price"` // The price as of UpdatedAt, before it decayed back towards the base
+	UpdatedAt time.Time `bson:"updated_at"`
+	History   []int     `bson:"history"` // The last few prices after each purchase or sale, oldest first
+}
+
+// Not a command
+// The price now, after decaying back towards the base price since it was last updated
+func (itemPrice ItemPrice) current(basePrice int) (float64) {
+	if itemPrice.UpdatedAt.IsZero() {
+		return float64(basePrice)
+	}
+	decay := math.Pow(0.5, time.Since(itemPrice.UpdatedAt).Hours() / priceHalfLife.Hours())
+	return float64(basePrice) + (itemPrice.Price - float64(basePrice)) * decay
+}
+
+// Not a command
+// Picks today's discounted items for the guild
+// The same guild always gets the same deals on the same day, so nothing needs to be stored
+func dailyDeals(guildID int) (map[string]bool) {
+	now := time.Now().UTC()
+	random := rand.New(rand.NewSource(int64(guildID) + int64(now.Year() * 1000 + now.YearDay())))
+	deals := map[string]bool{}
+	for _, i := range random.Perm(len(items))[:dealsPerDay] {
+		deals[itemKey(items[i].Name)] = true
+	}
+	return deals
+}
+
+// Not a command
+// Gets the stored price for every item that has one in the guild
+func loadPrices(ctx context.Context, priceCollection *mongo.Collection) (map[string]ItemPrice, error) {
+	cursor, err := priceCollection.Find(ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+	var stored []ItemPrice
+	err = cursor.All(ctx, &stored)
+	if err != nil {
+		return nil, err
+	}
+	prices := map[string]ItemPrice{}
+	for _, itemPrice := range stored {
+		prices[itemPrice.Item] = itemPrice
+	}
+	return prices, nil
+}
+
+// Not a command
+// Gets the stored price of a single item, which is the zero ItemPrice if nobody has bought it yet
+func storedPrice(ctx context.Context, priceCollection *mongo.Collection, shopItem ShopItem) (ItemPrice, error) {
+	var itemPrice ItemPrice
+	err := priceCollection.FindOne(ctx, bson.D{{Key: "item", Value: itemKey(shopItem.Name)}}).Decode(&itemPrice)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return ItemPrice{}, err
+	}
+	return itemPrice, nil
+}
+
+// Not a command
+// Rounds the price and applies the daily discount
+func finalPrice(itemPrice ItemPrice, shopItem ShopItem, deals map[string]bool) (int) {
+	price := itemPrice.current(shopItem.Price)
+	if deals[itemKey(shopItem.Name)] {
+		price *= 1 - DealDiscount
+	}
+	return int(math.Max(1, math.Round(price)))
+}
+
+// Not a command
+// What buying amount of an item costs when the first one is at price
+// Each one bought raises the price of the next, so a big order costs the same as many small ones
+func purchaseCost(price float64, amount int, deal bool) (int64) {
+	if deal {
+		price *= 1 - DealDiscount
+	}
+	growth := 1 + priceIncreasePerUnit
+	cost := math.Round(price * (math.Pow(growth, float64(amount)) - 1) / priceIncreasePerUnit)
+	cost = math.Max(cost, float64(amount)) // Nothing costs less than 1 coin
+	if cost >= math.MaxInt64 || math.IsInf(cost, 0) || math.IsNaN(cost) {
+		return math.MaxInt64 // More than anyone can afford
+	}
+	return int64(cost)
+}
+
+// Not a command
+// What the shop pays for amount of an item when the first one is at price, before deals
+// Each one sold lowers the price, and is paid for at sellBackPercent of the lower of the base price and the price it drops to
+// So selling straight back what was just bought never pays more than it cost
+func saleValue(price float64, basePrice int, amount int, sellBackPercent int) (int64) {
+	base := float64(basePrice)
+	growth := 1 + priceIncreasePerUnit
+
+	// The first few sold can leave the price above the base price, and are paid for at the base price
+	atBase := 0
+	if price > base && base > 0 {
+		atBase = int(math.Floor(math.Log(price / base) / math.Log(growth)))
+	}
+	if atBase > amount {
+		atBase = amount
+	}
+	value := base * float64(atBase)
+
+	// The rest are paid for at their falling price
+	rest := amount - atBase
+	if rest > 0 {
+		first := price / math.Pow(growth, float64(atBase + 1))
+		value += first * (1 - math.Pow(growth, -float64(rest))) / (1 - 1 / growth)
+	}
+	return int64(math.Floor(value * float64(sellBackPercent) / 100))
+}
+
+// Not a command
+// Raises the price of an item after amount of it was bought, and records it in the price history
+func recordPurchase(ctx context.Context, priceCollection *mongo.Collection, shopItem ShopItem, amount int) (error) {
+	return shiftPrice(ctx, priceCollection, shopItem, math.Pow(1 + priceIncreasePerUnit, float64(amount)))
+}
+
+// Not a command
+// Lowers the price of an item after amount of it was sold back, and records it in the price history
+func recordSale(ctx context.Context, priceCollection *mongo.Collection, shopItem ShopItem, amount int) (error) {
+	return shiftPrice(ctx, priceCollection, shopItem, math.Pow(1 + priceIncreasePerUnit, -float64(amount)))
+}
+
+// Not a command
+// Multiplies the item's decayed price by factor in a single update, so buys and sales at the same time can't overwrite each other
+// The decay is the same as ItemPrice.current, worked out by the database from the stored price
+func shiftPrice(ctx context.Context, priceCollection *mongo.Collection, shopItem ShopItem, factor float64) (error) {
+	now := time.Now()
+	base := float64(shopItem.Price)
+	decay := bson.D{{Key: "$pow", Value: bson.A{
+		0.5,
+		bson.D{{Key: "$divide", Value: bson.A{
+			bson.D{{Key: "$subtract", Value: bson.A{now, bson.D{{Key: "$ifNull", Value: bson.A{"$updated_at", now}}}}}},
+			float64(priceHalfLife.Milliseconds()), // Subtracting dates gives milliseconds
+		}}},
+	}}}
+	markup := bson.D{{Key: "$subtract", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$price", base}}}, base}}}
+	price := bson.D{{Key: "$add", Value: bson.A{base, bson.D{{Key: "$multiply", Value: bson.A{markup, decay}}}}}}
+
+	_, err := priceCollection.UpdateOne(
+		ctx,
+		bson.D{{Key: "item", Value: itemKey(shopItem.Name)}},
+		mongo.Pipeline{
+			{{Key: "$set", Value: bson.D{
+				{Key: "price", Value: bson.D{{Key: "$multiply", Value: bson.A{price, factor}}}},
+				{Key: "updated_at", Value: now},
+			}}},
+			{{Key: "$set", Value: bson.D{
+				{Key: "history", Value: bson.D{{Key: "$slice", Value: bson.A{ // Only keep the most recent prices
+					bson.D{{Key: "$concatArrays", Value: bson.A{
+						bson.D{{Key: "$ifNull", Value: bson.A{"$history", bson.A{}}}},
+						bson.A{bson.D{{Key: "$toInt", Value: bson.D{{Key: "$round", Value: bson.A{"$price", 0}}}}}},
+					}}},
+					-priceHistoryLength,
+				}}}},
+			}}},
+		},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
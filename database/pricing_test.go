@@ -0,0 +1,105 @@
+package database
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPurchaseCost(t *testing.T) {
+	tests := []struct {
+		name   string
+		price  float64
+		amount int
+		deal   bool
+		want   int64
+	}{
+		{"one at base", 50, 1, false, 50},
+		{"each one raises the next", 100, 2, false, 202}, // 100 + 102
+		{"deal", 100, 1, true, 80},
+		{"never under a coin each", 0.1, 5, false, 5},
+		{"too many to afford", 50, 1000000, false, math.MaxInt64},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := purchaseCost(test.price, test.amount, test.deal); got != test.want {
+				t.Errorf("purchaseCost(%v, %d, %v) = %d, want %d", test.price, test.amount, test.deal, got, test.want)
+			}
+		})
+	}
+}
+
+func TestPurchaseCostMatchesSmallOrders(t *testing.T) {
+	// Buying 10 at once costs the same as buying them one at a time
+	price := 50.0
+	var total float64
+	for i := 0; i < 10; i++ {
+		total += price
+		price *= 1 + priceIncreasePerUnit
+	}
+	if got := purchaseCost(50, 10, false); got != int64(math.Round(total)) {
+		t.Errorf("purchaseCost(50, 10) = %d, want %d", got, int64(math.Round(total)))
+	}
+}
+
+func TestSaleValue(t *testing.T) {
+	tests := []struct {
+		name      string
+		price     float64
+		basePrice int
+		amount    int
+		percent   int
+		want      int64
+	}{
+		{"at base", 102, 100, 1, 50, 50},        // Drops to 100, paid half of it
+		{"above base pays base", 500, 100, 3, 50, 150},
+		{"below base pays falling price", 102, 200, 2, 100, 198}, // 100 + 98.04
+		{"nothing back", 100, 100, 5, 0, 0},
+		{"none sold", 100, 100, 0, 50, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := saleValue(test.price, test.basePrice, test.amount, test.percent)
+			if got != test.want {
+				t.Errorf("saleValue(%v, %d, %d, %d) = %d, want %d", test.price, test.basePrice, test.amount, test.percent, got, test.want)
+			}
+		})
+	}
+}
+
+func TestBuyingAndSellingBackMakesNoCoins(t *testing.T) {
+	for _, shopItem := range items {
+		for _, start := range []float64{float64(shopItem.Price) / 2, float64(shopItem.Price), float64(shopItem.Price) * 3} {
+			for _, amount := range []int{1, 10, 100} {
+				for _, deal := range []bool{false, true} {
+					cost := purchaseCost(start, amount, deal)
+					after := start * math.Pow(1 + priceIncreasePerUnit, float64(amount))
+					value := saleValue(after, shopItem.Price, amount, 80)
+					if value > cost {
+						t.Errorf("%s: buying %d at %.0f (deal %v) cost %d but sold back for %d", shopItem.Name, amount, start, deal, cost, value)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestCurrentPriceDecays(t *testing.T) {
+	tests := []struct {
+		name  string
+		price ItemPrice
+		want  float64
+	}{
+		{"never bought", ItemPrice{}, 100},
+		{"just bought", ItemPrice{Price: 200, UpdatedAt: time.Now()}, 200},
+		{"one half life", ItemPrice{Price: 200, UpdatedAt: time.Now().Add(-priceHalfLife)}, 150},
+		{"below base rises", ItemPrice{Price: 50, UpdatedAt: time.Now().Add(-priceHalfLife)}, 75},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.price.current(100); math.Abs(got - test.want) > 0.01 {
+				t.Errorf("current(100) = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"mary-bot/metrics"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// How many items are shown on a profile card
+const cardItemCount = 3
+
+// Everything drawn on a profile card
+type ProfileCard struct {
+	UserName    string
+	Balance     int64
+	Currency    string   // What the server calls its coins, e.g. "coins" or "ED"
+	Level       int
+	XP          int64
+	LevelXP     int64    // Total XP needed for the current level
+	NextLevelXP int64    // Total XP needed for the next level
+	Spouse      string   // "" if they're not married
+	Badges      []string // Names of unlocked achievements
+	TopItems    []string // The items they have the most of, e.g. "Chocolate x12"
+}
+
+// mary profile card
+func GetProfileCard(mongoURI string, guildID int, guildName string, userID int, userName string) (ProfileCard, error) {
+	// Connect to MongoDB
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return ProfileCard{}, dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return ProfileCard{}, dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	// Check if user exists in database
+	err = IsPlaying(ctx, client, guildID, guildName, userID, userName)
+	if err != nil {
+		return ProfileCard{}, err
+	}
+
+	serverDatabase := client.Database(strconv.Itoa(guildID))
+	userCollection := serverDatabase.Collection("Users")
+	var user User
+	err = userCollection.FindOne(ctx, bson.M{"guild_id": guildID, "user_id": userID}).Decode(&user)
+	if err != nil {
+		return ProfileCard{}, dbError("finding user in database", err)
+	}
+	settings, err := guildSettings(ctx, serverDatabase)
+	if err != nil {
+		return ProfileCard{}, err
+	}
+
+	// The card's font can't draw emoji, so only the currency's name is shown
+	card := ProfileCard{
+		UserName: user.UserName,
+		Balance: user.Balance,
+		Currency: settings.Currency.Name,
+		Level: user.Level,
+		XP: user.XP,
+		LevelXP: xpForLevel(user.Level),
+		NextLevelXP: xpForLevel(user.Level + 1),
+	}
+
+	// Find the name of the user they're married to
+	if user.MarriedTo != 0 {
+		var spouse User
+		err = userCollection.FindOne(ctx, bson.M{"guild_id": guildID, "user_id": user.MarriedTo}).Decode(&spouse)
+		if err == nil {
+			card.Spouse = spouse.UserName
+		}
+	}
+
+	// Achievement names, in the order they're defined
+	unlocked := map[string]bool{}
+	for _, achievement := range user.Achievements {
+		unlocked[achievement.ID] = true
+	}
+	for _, achievement := range achievements {
+		if unlocked[achievement.ID] {
+			card.Badges = append(card.Badges, achievement.Name)
+		}
+	}
+
+	// Show off the items they have the most of
+	sort.Slice(user.Inventory, func(i, j int) bool {
+		return user.Inventory[i].Quantity > user.Inventory[j].Quantity
+	})
+	for i, item := range user.Inventory {
+		if i == cardItemCount {
+			break
+		}
+		card.TopItems = append(card.TopItems, fmt.Sprintf("%s x%d", strings.Title(item.Name), item.Quantity))
+	}
+	return card, nil
+}
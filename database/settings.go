@@ -0,0 +1,135 @@
+package database
+
+import (
+	"context"
+	"strconv"
+	"time"
+	"mary-bot/config"
+	"mary-bot/logging"
+	"mary-bot/metrics"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// A server setting changed with `mary config set`, stored in the guild's "Settings" collection
+// Settings the server hasn't changed don't have a document and use the bot's config
+type GuildSetting struct {
+	Key   string `bson:"key"`   // e.g. "cooldowns.daily", see config.GuildKeys()
+	Value string `bson:"value"` // As typed in the command, e.g. "12h"
+}
+
+// Not a command
+// The settings for a guild: the bot's config with the server's own changes on top
+func guildSettings(ctx context.Context, serverDatabase *mongo.Database) (config.Config, error) {
+	settings := config.Current
+
+	cursor, err := serverDatabase.Collection("Settings").Find(ctx, bson.D{})
+	if err != nil {
+		return settings, dbError("selecting from database", err)
+	}
+	var changed []GuildSetting
+	err = cursor.All(ctx, &changed)
+	if err != nil {
+		return settings, dbError("decoding result", err)
+	}
+
+	for _, setting := range changed {
+		// A stored value can stop making sense if the bot's config changes, e.g. beg_min above a new beg_max
+		// Skip it rather than breaking every command in the server
+		err = settings.Set(setting.Key, setting.Value)
+		if err != nil {
+			logging.Warn("Ignoring invalid server setting", "guild", serverDatabase.Name(), "key", setting.Key, "value", setting.Value, "err", err)
+		}
+	}
+	return settings, nil
+}
+
+// mary config show
+// The settings the server is using, whether they were changed or not
+func GuildSettings(mongoURI string, guildID int) (config.Config, error) {
+	// Connect to MongoDB
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return config.Config{}, dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return config.Config{}, dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	return guildSettings(ctx, client.Database(strconv.Itoa(guildID)))
+}
+
+// mary config set [key] [value] -> changes a setting for the server
+// A value of "default" goes back to the bot's config
+// Returns the server's settings after the change
+func SetGuildSetting(mongoURI string, guildID int, key string, value string) (config.Config, error) {
+	// Check the key before connecting, so typos don't cost a round trip
+	isKey := false
+	for _, guildKey := range config.GuildKeys() {
+		if guildKey == key {
+			isKey = true
+		}
+	}
+	if !isKey {
+		return config.Config{}, ErrNoSuchSetting
+	}
+
+	// Connect to MongoDB
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return config.Config{}, dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return config.Config{}, dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	serverDatabase := client.Database(strconv.Itoa(guildID))
+	settingsCollection := serverDatabase.Collection("Settings")
+
+	if value == "default" {
+		_, err = settingsCollection.DeleteOne(ctx, bson.D{{Key: "key", Value: key}})
+		if err != nil {
+			return config.Config{}, dbError("updating database", err)
+		}
+		forgetSettings(guildID)
+		return guildSettings(ctx, serverDatabase)
+	}
+
+	// Check the value against the rest of the server's settings, e.g. beg_min can't go above beg_max
+	settings, err := guildSettings(ctx, serverDatabase)
+	if err != nil {
+		return config.Config{}, err
+	}
+	err = settings.Set(key, value)
+	if err != nil {
+		return config.Config{}, ErrInvalidSetting{Key: key, Reason: err.Error()}
+	}
+
+	_, err = settingsCollection.UpdateOne(
+		ctx,
+		bson.D{{Key: "key", Value: key}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "value", Value: value}}}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return config.Config{}, dbError("updating database", err)
+	}
+	forgetSettings(guildID)
+	logging.Info("Changed server setting", "guild", guildID, "key", key, "value", value)
+	return settings, nil
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"fmt"
+	"time"
+	"mary-bot/config"
+)
+
+// How long until a command can be used again, 0 if it's ready now
+type Timer struct {
+	Name      string
+	Remaining time.Duration
+}
+
+// Not a command
+// Helper to work out how long is left on a cooldown that started at last
+func remaining(last time.Time, cooldown time.Duration) (time.Duration) {
+	left := cooldown - time.Since(last)
+	if left < 0 {
+		return 0
+	}
+	return left.Round(time.Second)
+}
+
+// Not a command
+// Every cooldown for the user, using the server's settings, in the order they're shown on the profile
+func userTimers(user User, settings config.Config) ([]Timer) {
+	return []Timer{
+		{"Daily", remaining(user.LastDaily, settings.Cooldowns.Daily)},
+		{"Beg", remaining(user.LastBeg, settings.Cooldowns.Beg)},
+		{"Rob", remaining(user.LastRob, settings.Cooldowns.Rob)},
+		{"Gamble", remaining(user.LastGamble, settings.Cooldowns.Gamble)},
+		{"Trivia", remaining(user.LastTrivia, settings.Cooldowns.Trivia)},
+		{"Use Item", remaining(user.LastUse, settings.Cooldowns.Use)},
+	}
+}
+
+// Helper to show a countdown, e.g. "3h 12m 5s" or "Ready!"
+func FormatCountdown(d time.Duration) (string) {
+	if d <= 0 {
+		return "Ready!"
+	}
+	d = d.Round(time.Second)
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	seconds := int(d.Seconds()) % 60
+	if hours > 0 {
+		return fmt.Sprintf("%dh %dm %ds", hours, minutes, seconds)
+	} else if minutes > 0 {
+		return fmt.Sprintf("%dm %ds", minutes, seconds)
+	}
+	return fmt.Sprintf("%ds", seconds)
+}
@@ -0,0 +1,373 @@
+package database
+
+import (
+	"context"
+	"strconv"
+	"time"
+	"mary-bot/metrics"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Trades are cancelled if nobody touches them for this long
+const tradeTimeout = 5 * time.Minute
+
+// What one side of a trade is putting up
+type TradeOffer struct {
+	UserID    int    `bson:"user_id"`
+	UserName  string `bson:"user_name"`
+	Items     []Item `bson:"items"`
+	Coins     int64  `bson:"coins"`
+	Confirmed bool   `bson:"confirmed"`
+}
+
+// A trade session between two users in the guild's "Trades" collection
+// Nothing is held in escrow, everything is checked and moved when both sides confirm
+type TradeSession struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Initiator TradeOffer         `bson:"initiator"`
+	Partner   TradeOffer         `bson:"partner"`
+	Status    string             `bson:"status"` // "open", "completed" or "cancelled"
+	ExpiresAt time.Time          `bson:"expires_at"`
+}
+
+// Which side of the trade the user is on ("initiator" or "partner"), and their offer
+func (trade *TradeSession) side(userID int) (string, *TradeOffer) {
+	if trade.Initiator.UserID == userID {
+		return "initiator", &trade.Initiator
+	}
+	return "partner", &trade.Partner
+}
+
+// Not a command
+// Finds the open trade the user is part of, if any
+func findOpenTrade(ctx context.Context, tradeCollection *mongo.Collection, userID int) (TradeSession, error) {
+	var trade TradeSession
+	err := tradeCollection.FindOne(
+		ctx,
+		bson.D{
+			{Key: "status", Value: "open"},
+			{Key: "expires_at", Value: bson.D{{Key: "$gt", Value: time.Now()}}},
+			{Key: "$or", Value: bson.A{
+				bson.D{{Key: "initiator.user_id", Value: userID}},
+				bson.D{{Key: "partner.user_id", Value: userID}},
+			}},
+		},
+	).Decode(&trade)
+	return trade, err
+}
+
+// What a trade command did
+type TradeResult struct {
+	Operation string // "open", "add", "confirm", "complete", "cancel" or "show"
+	UserID    int    // Who ran the command
+	Trade     TradeSession
+	Announcements
+}
+
+// mary trade @pingedUser
+func tradeOpen(ctx context.Context, tradeCollection *mongo.Collection, userCollection *mongo.Collection, guildID int, userID int, userName string, pingedUserID int) (TradeResult, error) {
+	if userID == pingedUserID {
+		return TradeResult{}, ErrSelfTarget
+	}
+
+	// Check if the pinged user is playing
+	var pingedUser User // User struct defined in items.go
+	err := userCollection.FindOne(ctx, bson.M{"guild_id": guildID, "user_id": pingedUserID}).Decode(&pingedUser)
+	if err != nil {
+		return TradeResult{}, ErrNotPlaying
+	}
+
+	// Each user can only be in one trade at a time
+	_, err = findOpenTrade(ctx, tradeCollection, userID)
+	if err == nil {
+		return TradeResult{}, ErrAlreadyTrading
+	}
+	_, err = findOpenTrade(ctx, tradeCollection, pingedUserID)
+	if err == nil {
+		return TradeResult{}, ErrTargetTrading{UserID: pingedUserID}
+	}
+
+	trade := TradeSession{
+		Initiator: TradeOffer{UserID: userID, UserName: userName, Items: []Item{}},
+		Partner: TradeOffer{UserID: pingedUserID, UserName: pingedUser.UserName, Items: []Item{}},
+		Status: "open",
+		ExpiresAt: time.Now().Add(tradeTimeout),
+	}
+	_, err = tradeCollection.InsertOne(ctx, trade)
+	if err != nil {
+		return TradeResult{}, dbError("inserting to database", err)
+	}
+	return TradeResult{Operation: "open", UserID: userID, Trade: trade}, nil
+}
+
+// mary trade add [item] [amount]
+// mary trade add coins [amount]
+func tradeAdd(ctx context.Context, tradeCollection *mongo.Collection, userCollection *mongo.Collection, guildID int, userID int, item string, amount int) (TradeResult, error) {
+	if amount < 1 {
+		return TradeResult{}, ErrNotPositive
+	}
+
+	trade, err := findOpenTrade(ctx, tradeCollection, userID)
+	if err != nil {
+		return TradeResult{}, ErrNoTrade
+	}
+	sideName, offer := trade.side(userID)
+
+	var user User // User struct defined in items.go
+	err = userCollection.FindOne(ctx, bson.M{"guild_id": guildID, "user_id": userID}).Decode(&user)
+	if err != nil {
+		return TradeResult{}, dbError("finding user in database", err)
+	}
+
+	if item == "coins" || item == "coin" {
+		// Check if the user has enough coins for everything they've offered
+		if user.Balance < offer.Coins + int64(amount) {
+			return TradeResult{}, ErrInsufficientFunds
+		}
+		offer.Coins += int64(amount)
+	} else {
+		// Check if item exists
+		shopItem, ok := FindShopItem(item)
+		if !ok {
+			return TradeResult{}, ErrNoSuchItem
+		}
+		item = itemKey(shopItem.Name)
+
+		// Add to the offer, or add a new slot for the item
+		offered := amount
+		offerIndex := -1
+		for i, offerItem := range offer.Items {
+			if offerItem.Name == item {
+				offerIndex = i
+				offered += offerItem.Quantity
+			}
+		}
+
+		// Check if the user has enough of the item for everything they've offered
+		owned := 0
+		broken := false
+		worn := false
+		for _, inventoryItem := range user.Inventory {
+			if inventoryItem.Name == item {
+				owned = inventoryItem.Quantity
+				broken = inventoryItem.Broken
+				worn = itemWorn(inventoryItem)
+			}
+		}
+		if owned < offered {
+			return TradeResult{}, ErrNotEnoughItems
+		} else if broken && owned == offered {
+			return TradeResult{}, ErrItemBroken{Item: item}
+		} else if worn && owned == offered {
+			return TradeResult{}, ErrItemWorn{Item: item}
+		}
+
+		if offerIndex == -1 {
+			offer.Items = append(offer.Items, Item{Name: item, Quantity: amount})
+		} else {
+			offer.Items[offerIndex].Quantity = offered
+		}
+	}
+
+	// Any change to the offer means both sides have to confirm again
+	// Only the user's own side is set, so the other side adding to their offer at the same time isn't overwritten
+	err = tradeCollection.FindOneAndUpdate(
+		ctx,
+		bson.D{
+			{Key: "_id", Value: trade.ID},
+			{Key: "status", Value: "open"},
+		},
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: sideName + ".items", Value: offer.Items},
+			{Key: sideName + ".coins", Value: offer.Coins},
+			{Key: "initiator.confirmed", Value: false},
+			{Key: "partner.confirmed", Value: false},
+			{Key: "expires_at", Value: time.Now().Add(tradeTimeout)},
+		}}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&trade)
+	if err == mongo.ErrNoDocuments {
+		return TradeResult{}, ErrNoTrade // Cancelled since it was looked up
+	} else if err != nil {
+		return TradeResult{}, dbError("updating database", err)
+	}
+	return TradeResult{Operation: "add", UserID: userID, Trade: trade}, nil
+}
+
+// mary trade confirm
+// Once both sides have confirmed, the swap happens all at once
+func tradeConfirm(ctx context.Context, client *mongo.Client, tradeCollection *mongo.Collection, userCollection *mongo.Collection, guildID int, userID int) (TradeResult, error) {
+	trade, err := findOpenTrade(ctx, tradeCollection, userID)
+	if err != nil {
+		return TradeResult{}, ErrNoTrade
+	}
+	sideName, _ := trade.side(userID)
+
+	err = tradeCollection.FindOneAndUpdate(
+		ctx,
+		bson.D{
+			{Key: "_id", Value: trade.ID},
+			{Key: "status", Value: "open"},
+		},
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: sideName + ".confirmed", Value: true},
+		}}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&trade)
+	if err != nil {
+		return TradeResult{}, dbError("updating database", err)
+	}
+
+	// Wait for the other side
+	if !trade.Initiator.Confirmed || !trade.Partner.Confirmed {
+		return TradeResult{Operation: "confirm", UserID: userID, Trade: trade}, nil
+	}
+
+	// Swap everything in one transaction so that nobody ends up with both halves
+	err = runTransaction(ctx, client, func(sessCtx mongo.SessionContext) error {
+		result, err := tradeCollection.UpdateOne(
+			sessCtx,
+			bson.D{
+				{Key: "_id", Value: trade.ID},
+				{Key: "status", Value: "open"},
+				{Key: "initiator.confirmed", Value: true},
+				{Key: "partner.confirmed", Value: true},
+			},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "status", Value: "completed"}}}},
+		)
+		if err != nil {
+			return err
+		} else if result.MatchedCount == 0 {
+			return transactionAbort{ErrTradeChanged}
+		}
+
+		for _, offers := range [][2]TradeOffer{{trade.Initiator, trade.Partner}, {trade.Partner, trade.Initiator}} {
+			from, to := offers[0], offers[1]
+			for _, item := range from.Items {
+				level, err := itemLevelNeeded(sessCtx, userCollection, guildID, to.UserID, item.Name)
+				if err != nil {
+					return err
+				} else if level > 0 {
+					return transactionAbort{ErrLevelTooLow{Level: level, UserID: to.UserID}}
+				}
+				ok, err := takeFromInventory(sessCtx, userCollection, guildID, from.UserID, item.Name, item.Quantity)
+				if err != nil {
+					return err
+				} else if !ok {
+					return transactionAbort{ErrTradeShort{UserName: from.UserName, Item: item}}
+				}
+				err = addToInventory(sessCtx, userCollection, guildID, to.UserID, item.Name, item.Quantity)
+				if err != nil {
+					return err
+				}
+			}
+			if from.Coins > 0 {
+				ok, err := transferCoins(sessCtx, userCollection, guildID, from.UserID, to.UserID, from.Coins)
+				if err == ErrNotPlaying {
+					return transactionAbort{err}
+				} else if err != nil {
+					return err
+				} else if !ok {
+					return transactionAbort{ErrTradeShort{UserName: from.UserName, Coins: from.Coins}}
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		// Make both sides confirm again once they've sorted it out
+		tradeCollection.UpdateOne(
+			ctx,
+			bson.D{{Key: "_id", Value: trade.ID}},
+			bson.D{{Key: "$set", Value: bson.D{
+				{Key: "initiator.confirmed", Value: false},
+				{Key: "partner.confirmed", Value: false},
+			}}},
+		)
+		return TradeResult{}, err
+	}
+
+	result := TradeResult{Operation: "complete", UserID: userID, Trade: trade}
+	result.unlocked(checkAchievements(ctx, userCollection, guildID, trade.Initiator.UserID, eventItem))
+	result.unlocked(checkAchievements(ctx, userCollection, guildID, trade.Partner.UserID, eventItem))
+	return result, nil
+}
+
+// mary trade cancel
+func tradeCancel(ctx context.Context, tradeCollection *mongo.Collection, userID int) (TradeResult, error) {
+	trade, err := findOpenTrade(ctx, tradeCollection, userID)
+	if err != nil {
+		return TradeResult{}, ErrNoTrade
+	}
+	_, err = tradeCollection.UpdateOne(
+		ctx,
+		bson.D{
+			{Key: "_id", Value: trade.ID},
+			{Key: "status", Value: "open"},
+		},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "status", Value: "cancelled"}}}},
+	)
+	if err != nil {
+		return TradeResult{}, dbError("updating database", err)
+	}
+	return TradeResult{Operation: "cancel", UserID: userID, Trade: trade}, nil
+}
+
+// All the trade commands
+func Trade(mongoURI string, guildID int, guildName string, userID int, userName string, operation string, pingedUserID int, item string, amount int) (TradeResult, error) {
+	// Connect to MongoDB
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return TradeResult{}, dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return TradeResult{}, dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	// Check if user exists in database
+	err = IsPlaying(ctx, client, guildID, guildName, userID, userName)
+	if err != nil {
+		return TradeResult{}, err
+	}
+
+	serverDatabase := client.Database(strconv.Itoa(guildID))
+	userCollection := serverDatabase.Collection("Users")
+	tradeCollection := serverDatabase.Collection("Trades")
+
+	// Jailed users can still back out of a trade, but can't do anything else
+	if operation != "cancel" {
+		err = IsJailed(ctx, client, guildID, userID)
+		if err != nil {
+			return TradeResult{}, err
+		}
+	}
+
+	switch operation {
+		case "open":
+			return tradeOpen(ctx, tradeCollection, userCollection, guildID, userID, userName, pingedUserID)
+		case "add":
+			return tradeAdd(ctx, tradeCollection, userCollection, guildID, userID, item, amount)
+		case "confirm":
+			return tradeConfirm(ctx, client, tradeCollection, userCollection, guildID, userID)
+		case "cancel":
+			return tradeCancel(ctx, tradeCollection, userID)
+		case "show":
+			trade, err := findOpenTrade(ctx, tradeCollection, userID)
+			if err != nil {
+				return TradeResult{}, ErrNoTrade
+			}
+			return TradeResult{Operation: "show", UserID: userID, Trade: trade}, nil
+		default:
+			return TradeResult{}, ErrUnknownCommand
+	}
+}
@@ -3,13 +3,14 @@ package database
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"html"
 	"math/rand"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
+	"mary-bot/logging"
+	"mary-bot/metrics"
 	"github.com/bwmarrin/discordgo"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options" 
@@ -28,21 +29,28 @@ type TriviaQuestion struct {
 }
 
 
+// A question ready to be asked
+type TriviaRound struct {
+	Question   string
+	Category   string
+	Difficulty string
+	Choices    []string // Lettered, e.g. "A) Paris"
+	Answer     string   // The letter of the correct choice
+}
+
 // Trivia is a function that starts a trivia game session
-func Trivia(session *discordgo.Session, message *discordgo.MessageCreate, mongoURI string, guildID int, guildName string, userID int, userName string) (string, *discordgo.MessageEmbed, string, string) {
+func Trivia(session *discordgo.Session, message *discordgo.MessageCreate, mongoURI string, guildID int, guildName string, userID int, userName string) (TriviaRound, error) {
 	// Connect to MongoDB
-	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI))
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
 	if err != nil {
-		fmt.Printf("Error occurred creating MongoDB client! %s\n", err)
-		return "Error occurred creating MongoDB client! " + strings.Title(err.Error()), nil, "", ""
+		return TriviaRound{}, dbError("creating MongoDB client", err)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
 	defer cancel() // Fix for memory leak
 	err = client.Connect(ctx)
 	if err != nil {
-		fmt.Printf("Error occurred connecting to database! %s\n", err)
-		return "Error occurred while connecting to database! " + strings.Title(err.Error()), nil, "", ""
+		return TriviaRound{}, dbError("connecting to database", err)
 	}
 
 	// Disconnect from database
@@ -61,10 +69,14 @@ func Trivia(session *discordgo.Session, message *discordgo.MessageCreate, mongoU
 
 	// Get user
 	lastTrivia := collectionResult.Lookup("last_trivia").DateTime()
+	settings, err := guildSettings(ctx, serverDatabase)
+	if err != nil {
+		return TriviaRound{}, err
+	}
 
-	// Wait 5 seconds before playing trivia again
-	if time.Now().Unix() - lastTrivia/1000 < 5 && commands.IsOwner(userID) == false{
-		return "<@" + strconv.Itoa(userID) + ">, you must wait 5 seconds before playing trivia again!", nil, "", ""
+	// Wait for the trivia cooldown before playing again
+	if time.Now().Unix() - lastTrivia/1000 < int64(settings.Cooldowns.Trivia.Seconds()) && commands.IsOwner(userID) == false{
+		return TriviaRound{}, ErrCooldown{Action: "trivia", Remaining: remaining(time.UnixMilli(lastTrivia), settings.Cooldowns.Trivia)}
 	}
 
 	// If the user is not on cooldown, set the last_trivia field to now
@@ -84,8 +96,9 @@ func Trivia(session *discordgo.Session, message *discordgo.MessageCreate, mongoU
 	// Make a request to the trivia API
 	resp, err := http.Get("https://opentdb.com/api.php?amount=1&type=multiple")
 	if err != nil {
-		fmt.Printf("Failed to get trivia question! %s\n", err)
-		return "Failed to get trivia question!" + strings.Title(err.Error()), nil, "", ""
+		logging.Error("Failed to get trivia question!", "guild", guildID, "user", userID, "err", err)
+		metrics.ErrorsTotal.Inc("trivia")
+		return TriviaRound{}, ErrTriviaUnavailable
 	}
 	defer resp.Body.Close()
 
@@ -96,13 +109,15 @@ func Trivia(session *discordgo.Session, message *discordgo.MessageCreate, mongoU
 	}
 	err = json.NewDecoder(resp.Body).Decode(&triviaResponse)
 	if err != nil {
-		fmt.Printf("Failed to parse trivia question! %s\n", err)
-		return "Failed to parse trivia question!" + strings.Title(err.Error()), nil, "", ""
+		logging.Error("Failed to parse trivia question!", "guild", guildID, "user", userID, "err", err)
+		metrics.ErrorsTotal.Inc("trivia")
+		return TriviaRound{}, ErrTriviaUnavailable
 	}
 
 	if triviaResponse.ResponseCode != 0 || len(triviaResponse.Results) == 0 {
-		fmt.Printf("Failed to get trivia question! Response code: %d\n", triviaResponse.ResponseCode)
-		return "Failed to get trivia question!" + strings.Title(err.Error()), nil, "", ""
+		logging.Error("Failed to get trivia question!", "guild", guildID, "user", userID, "response_code", triviaResponse.ResponseCode)
+		metrics.ErrorsTotal.Inc("trivia")
+		return TriviaRound{}, ErrTriviaUnavailable
 	}
 
 	// Select the first question from the response and shuffle the answer choices
@@ -136,18 +151,13 @@ func Trivia(session *discordgo.Session, message *discordgo.MessageCreate, mongoU
 	// Capitalize the first letter of the difficulty
 	question.Difficulty = strings.Title(question.Difficulty)
 
-	// Send the question as a rich embed
-	embed := &discordgo.MessageEmbed{
-		Title:       question.Question,
-		Description: fmt.Sprintf("Category: %s \nDifficulty: %s", question.Category, question.Difficulty),
-		Color:       0xffc0cb,
-		Fields: []*discordgo.MessageEmbedField{
-			{Name: "Choices", Value: strings.Join(formattedChoices, "\n"), Inline: false},
-		},
-	}
-
-	// Return the embed
-	return "", embed, correctLetter, question.Difficulty
+	return TriviaRound{
+		Question: question.Question,
+		Category: question.Category,
+		Difficulty: question.Difficulty,
+		Choices: formattedChoices,
+		Answer: correctLetter,
+	}, nil
 }
 
 // Wait for the user to respond; This is the equivalent of channelMessageWait in Discord.js
@@ -179,16 +189,41 @@ func WaitForResponse(session *discordgo.Session, channelID string, authorID stri
 }
 
 // Pay the user for their correct answer
-func PayForCorrectAnswer(session *discordgo.Session, message *discordgo.MessageCreate, difficulty string, mongoURI string, guildID int, guildName string, userID int, userName string, amount int) (string) {
+// A negative amount takes the coins they gambled away instead
+func PayForCorrectAnswer(session *discordgo.Session, message *discordgo.MessageCreate, difficulty string, mongoURI string, guildID int, guildName string, userID int, userName string, amount int) (EconomyResult, error) {
+	// Connect to MongoDB
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
+	if err != nil {
+		return EconomyResult{}, dbError("creating MongoDB client", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
+	defer cancel() // Fix for memory leak
+	err = client.Connect(ctx)
+	if err != nil {
+		return EconomyResult{}, dbError("connecting to database", err)
+	}
+
+	// Disconnect from database
+	defer client.Disconnect(ctx) // Occurs as last line of main() function
+
+	// Get the correct database and collection
+	serverDatabase := client.Database(strconv.Itoa(guildID))
+	userCollection := serverDatabase.Collection("Users")
+	settings, err := guildSettings(ctx, serverDatabase)
+	if err != nil {
+		return EconomyResult{}, err
+	}
+
 	// Calculate the amount of coins to pay the user
 	if amount == 0 {
 		switch strings.ToLower(difficulty) {
 		case "easy":
-			amount = 50
+			amount = settings.Payouts.TriviaEasy
 		case "medium":
-			amount = 100
+			amount = settings.Payouts.TriviaMedium
 		case "hard":
-			amount = 200
+			amount = settings.Payouts.TriviaHard
 		}
 	} else if amount > 0 {
 		switch strings.ToLower(difficulty) {
@@ -201,29 +236,14 @@ func PayForCorrectAnswer(session *discordgo.Session, message *discordgo.MessageC
 		}
 	}
 
-	// Connect to MongoDB
-	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI))
-	if err != nil {
-		fmt.Printf("Error occurred creating MongoDB client! " + strings.Title(err.Error()))
-		return "Error occurred creating MongoDB client! " + strings.Title(err.Error())
+	// Update the user's balance
+	// A positive amount means they got the answer right, so it counts as a win
+	update := bson.D{
+		{Key: "balance", Value: amount},
 	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
-	defer cancel() // Fix for memory leak
-	err = client.Connect(ctx)
-	if err != nil {
-		fmt.Printf("Error occurred while connecting to database! %s\n", err)
-		return "Error occurred while connecting to database! " + strings.Title(err.Error())
+	if amount > 0 {
+		update = append(update, bson.E{Key: "trivia_wins", Value: 1})
 	}
-
-	// Disconnect from database
-	defer client.Disconnect(ctx) // Occurs as last line of main() function
-
-	// Get the correct database and collection
-	serverDatabase := client.Database(strconv.Itoa(guildID))
-	userCollection := serverDatabase.Collection("Users")
-
-	// Update the user's balance
 	_, err = userCollection.UpdateOne(
 		ctx,
 		bson.D{
@@ -231,44 +251,54 @@ func PayForCorrectAnswer(session *discordgo.Session, message *discordgo.MessageC
 			{Key: "guild_id", Value: guildID},
 		},
 		bson.D{
-			{Key: "$inc", Value: bson.D{
-				{Key: "balance", Value: amount},
-			}},
+			{Key: "$inc", Value: update},
 		},
 	)
 	if err != nil {
-		fmt.Printf("Error occurred while updating user's balance! %s\n", err)
-		return "Error occurred while updating user's balance! " + strings.Title(err.Error())
+		return EconomyResult{}, dbError("updating user's balance", err)
 	}
 	// Success
-	return "<@" + strconv.Itoa(userID) + ">, you have been paid " + strconv.Itoa(amount) + " coins!"
+	if amount > 0 {
+		metrics.CoinsMinted.Add("trivia", float64(amount))
+	} else {
+		metrics.CoinsBurned.Add("trivia", float64(-amount))
+	}
+	result := EconomyResult{Operation: "trivia", UserID: userID, Amount: int64(amount), Won: amount > 0}
+	if amount > 0 {
+		result.levelledUp(grantXP(ctx, userCollection, guildID, userID, xpPerAction))
+	}
+	return result, nil
 }
 
 // Check if the user has enough coins to gamble
 // Also check if the user is playing the game
-func CheckBalance(session *discordgo.Session, message *discordgo.MessageCreate, mongoURI string, guildID int, guildName string, userID int, userName string, amount int) (string) {
+func CheckBalance(session *discordgo.Session, message *discordgo.MessageCreate, mongoURI string, guildID int, guildName string, userID int, userName string, amount int) (error) {
 	// Connect to MongoDB
-	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI))
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
 	if err != nil {
-		fmt.Printf("Error occurred creating MongoDB client! " + strings.Title(err.Error()))
-		return "Error occurred creating MongoDB client! " + strings.Title(err.Error())
+		return dbError("creating MongoDB client", err)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
 	defer cancel() // Fix for memory leak
 	err = client.Connect(ctx)
 	if err != nil {
-		fmt.Printf("Error occurred while connecting to database! %s\n", err)
-		return "Error occurred while connecting to database! " + strings.Title(err.Error())
+		return dbError("connecting to database", err)
 	}
 
 	// Disconnect from database
 	defer client.Disconnect(ctx) // Occurs as last line of main() function
 
 	// Check if user exists in database
-	res := IsPlaying(ctx, client, guildID, guildName, userID, userName)
-	if res != "" {
-		return res
+	err = IsPlaying(ctx, client, guildID, guildName, userID, userName)
+	if err != nil {
+		return err
+	}
+
+	// Jailed users are locked out of the economy until they're released or pay bail
+	err = IsJailed(ctx, client, guildID, userID)
+	if err != nil {
+		return err
 	}
 
 	// Get user from database
@@ -288,15 +318,14 @@ func CheckBalance(session *discordgo.Session, message *discordgo.MessageCreate,
 		{Key: "guild_id", Value: guildID},
 	}).DecodeBytes() 
 	if err2 != nil {
-		fmt.Printf("Error occurred while finding user! %s\n", err2)
-		return "Error occurred while finding user! " + strings.Title(err2.Error())
+		return dbError("finding user", err2)
 	}
 
 	// Check if user has enough to gamble
 	userBalance := collectionResult.Lookup("balance").Int64()
 	if userBalance < int64(amount) {
-		return "<@" + strconv.Itoa(userID) + ">, you don't have enough coins to gamble that much!"
+		return ErrCantAfford{Cost: int64(amount), Balance: userBalance}
 	}
 	// Success
-	return ""
+	return nil
 }
\ No newline at end of file
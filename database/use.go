@@ -2,11 +2,10 @@ package database
 
 import (
 	"context"
-	"fmt"
 	"math/rand"
 	"strconv"
-	"strings"
 	"time"
+	"mary-bot/metrics"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/bson"
@@ -15,30 +14,48 @@ import (
 
 // Structs are defined in items.go
 
+// What happened when an item was used
+type UseResult struct {
+	Item     string
+	TargetID int    // The pinged user, if the item needs one
+	Outcome  string // "ate", "jackpot", "ran_over", "target_broke", "blocked", "robbed", "shot_back", "proposed" or "married"
+	Amount   int64  // Coins won, taken or lost
+	Announcements
+}
+
+// What happened when a user filed for divorce
+type DivorceResult struct {
+	PartnerID int
+	Official  bool // Whether the partner had already filed, which finalises the divorce
+}
 
-func Use(mongoURI string, guildID int, guildName string, userID int, userName string, item string, pingedUserID int) (string) {
+func Use(mongoURI string, guildID int, guildName string, userID int, userName string, item string, pingedUserID int) (UseResult, error) {
 	// Connect to MongoDB
-	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI))
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
 	if err != nil {
-		fmt.Printf("Error occurred creating MongoDB client! %s\n", err)
-		return "Error occurred creating MongoDB client! " + strings.Title(err.Error())
+		return UseResult{}, dbError("creating MongoDB client", err)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
 	defer cancel() // Fix for memory leak
 	err = client.Connect(ctx)
 	if err != nil {
-		fmt.Printf("Error occurred while connecting to database! %s\n", err)
-		return "Error occurred while connecting to database! " + strings.Title(err.Error())
+		return UseResult{}, dbError("connecting to database", err)
 	}
 
 	// Disconnect from database
 	defer client.Disconnect(ctx) // Occurs as last line of main() function
 
 	// Check if user exists in database
-	res := IsPlaying(ctx, client, guildID, guildName, userID, userName)
-	if res != "" {
-		return res
+	err = IsPlaying(ctx, client, guildID, guildName, userID, userName)
+	if err != nil {
+		return UseResult{}, err
+	}
+
+	// Jailed users are locked out of the economy until they're released or pay bail
+	err = IsJailed(ctx, client, guildID, userID)
+	if err != nil {
+		return UseResult{}, err
 	}
 
 	// Get user from database
@@ -47,13 +64,12 @@ func Use(mongoURI string, guildID int, guildName string, userID int, userName st
 	var user User // User struct defined in database.go
 	err = userCollection.FindOne(ctx, filter).Decode(&user)
 	if err != nil {
-		fmt.Printf("Error occurred while finding user in database! %s\n", err)
-		return "Error occurred while finding user in database! " + strings.Title(err.Error())
+		return UseResult{}, dbError("finding user in database", err)
 	}
 
 	// Check if user has an inventory
 	if len(user.Inventory) == 0 {
-		return "You do not have any items in your inventory!"
+		return UseResult{}, ErrEmptyInventory
 	}
 
 	// Check if user has the item in their inventory
@@ -63,19 +79,29 @@ func Use(mongoURI string, guildID int, guildName string, userID int, userName st
 			itemIndex = index
 			// Check if the user has enough of the item
 			if it.Quantity < 1 {
-				return "You do not have enough of that item in your inventory to use!"
+				return UseResult{}, ErrNotEnoughItems
 			}
 		}
 	}
 	if itemIndex == -1 {
-		return "You do not have that item in your inventory!"
+		return UseResult{}, ErrItemNotFound
+	}
+
+	// Broken items can't be used until they're repaired, unless the user has a spare
+	user.Inventory = swapBrokenItem(user.Inventory, itemIndex)
+	if user.Inventory[itemIndex].Broken {
+		return UseResult{}, ErrItemBroken{Item: item}
 	}
 
-	// Check if the user has waited a minute since their last use indicated by last_use
-	// If the user has not waited a minute, return an error
+	// Check if the user has waited out the use cooldown since their last use indicated by last_use
+	// If the user has not waited long enough, return an error
+	settings, err := guildSettings(ctx, userCollection.Database())
+	if err != nil {
+		return UseResult{}, err
+	}
 	lastUse := user.LastUse
-	if time.Since(lastUse) < time.Minute && commands.IsOwner(userID) == false {
-		return "You must wait a minute between uses!"
+	if time.Since(lastUse) < settings.Cooldowns.Use && commands.IsOwner(userID) == false {
+		return UseResult{}, ErrCooldown{Action: "use", Remaining: remaining(lastUse, settings.Cooldowns.Use)}
 	}
 	
 	// Update the user's last_use to the current time
@@ -92,18 +118,13 @@ func Use(mongoURI string, guildID int, guildName string, userID int, userName st
 		},
 	)
 	if err != nil {
-		fmt.Printf("Error occurred while updating database! %s", err)
-		return "Error occurred while updating database! " + strings.Title(err.Error())
+		return UseResult{}, dbError("updating database", err)
 	}
 
-	// Only update the inventory if they're not using car - car has infinite uses
+	// Wear down the item - single-use items are used up, the rest lose durability
 	// Do not take away ring until you check that the pinged user isn't married
-	if item != "car" && item != "ring" {
-		// Update the user's inventory to reduce the amount of the item they have
-		user.Inventory[itemIndex].Quantity -= 1
-		if user.Inventory[itemIndex].Quantity == 0 { // If the user has no more of the item, remove it from their inventory
-			user.Inventory = append(user.Inventory[:itemIndex], user.Inventory[itemIndex+1:]...)
-		}
+	if item != "ring" {
+		user.Inventory = wearItem(user.Inventory, itemIndex)
 		_, err = userCollection.UpdateOne(
 			ctx,
 			bson.D{
@@ -117,13 +138,13 @@ func Use(mongoURI string, guildID int, guildName string, userID int, userName st
 			},
 		)
 		if err != nil {
-			fmt.Printf("Error occurred while updating database! %s\n", err)
-			return "Error occurred while updating database! " + strings.Title(err.Error())
+			return UseResult{}, dbError("updating database", err)
 		}
 	}
 	
 	// Check what the item is
 	// The check for whether a pingedUser exists is done in mary.go
+	result := UseResult{Item: item, TargetID: pingedUserID}
 	switch item {
 	case "chocolate":
 		// Set a 1% chance that they will win 1000000 coins
@@ -143,13 +164,16 @@ func Use(mongoURI string, guildID int, guildName string, userID int, userName st
 				},
 			)
 			if err != nil {
-				fmt.Printf("Error occurred while updating database! %s\n", err)
-				return "Error occurred while updating database! " + strings.Title(err.Error())
+				return UseResult{}, dbError("updating database", err)
 			}
-			return "You found a golden ticket! You won 1000000 coins!"
+			metrics.CoinsMinted.Add("jackpot", 1000000)
+			result.Outcome = "jackpot"
+			result.Amount = 1000000
+			return result, nil
 		}
-		// Otherwise, just return a normal message
-		return "You ate some chocolate. Yum!"
+		// Otherwise, they just ate it
+		result.Outcome = "ate"
+		return result, nil
 		
 	case "car":
 		// Check if the pinged user is rich enough
@@ -158,12 +182,12 @@ func Use(mongoURI string, guildID int, guildName string, userID int, userName st
 		var pingedUser User
 		err = pingedUserCollection.FindOne(ctx, pingedUserFilter).Decode(&pingedUser)
 		if err != nil {
-			fmt.Printf("That user is not currently playing the game!\n")
-			return "That user is not currently playing the game!"
+			return UseResult{}, ErrNotPlaying
 		}
 		pingedUserBalance := pingedUser.Balance
 		if pingedUserBalance < 1000 {
-			return "You ran over <@" + strconv.Itoa(pingedUserID) + "> with your car, but they didn't have enough money to pay you!"
+			result.Outcome = "target_broke"
+			return result, nil
 		}
 
 		// Otherwise, take 1000 coins from the pinged user and give them to the user
@@ -180,8 +204,7 @@ func Use(mongoURI string, guildID int, guildName string, userID int, userName st
 			},
 		)
 		if err != nil {
-			fmt.Printf("Error occurred while updating database! %s\n", err)
-			return "Error occurred while updating database! " + strings.Title(err.Error())
+			return UseResult{}, dbError("updating database", err)
 		}
 		_, err = pingedUserCollection.UpdateOne(
 			ctx,
@@ -196,20 +219,20 @@ func Use(mongoURI string, guildID int, guildName string, userID int, userName st
 			},
 		)
 		if err != nil {
-			fmt.Printf("Error occurred while updating database! %s\n", err)
-			return "Error occurred while updating database! " + strings.Title(err.Error())
+			return UseResult{}, dbError("updating database", err)
 		}
-		return "You ran over <@" + strconv.Itoa(pingedUserID) + "> with your car and took 1000 coins from them!"
+		result.Outcome = "ran_over"
+		result.Amount = 1000
+		return result, nil
 
-	case "gun": 
+	case "gun", "crossbow":
 		// Check if the pinged user exists in the database
 		pingedUserCollection := client.Database(strconv.Itoa(guildID)).Collection("Users")
 		pingedUserFilter := bson.M{"guild_id": guildID, "user_id": pingedUserID}
 		var pingedUser User
 		err = pingedUserCollection.FindOne(ctx, pingedUserFilter).Decode(&pingedUser)
 		if err != nil {
-			fmt.Printf("That user is not currently playing the game!\n")
-			return "That user is not currently playing the game!"
+			return UseResult{}, ErrNotPlaying
 		}
 		pingedUserBalance := pingedUser.Balance
 
@@ -223,14 +246,22 @@ func Use(mongoURI string, guildID int, guildName string, userID int, userName st
 			}
 		}
 
+		// A broken shield is only useful if they have a spare
 		if shieldIndex != -1 {
-			// Reduce the pinged user's shield quantity by 1
-			pingedUser.Inventory[shieldIndex].Quantity -= 1
-
-			// If the pinged user has no more shields, remove the shield from their inventory
-			if pingedUser.Inventory[shieldIndex].Quantity == 0 {
-				pingedUser.Inventory = append(pingedUser.Inventory[:shieldIndex], pingedUser.Inventory[shieldIndex+1:]...)
+			pingedUser.Inventory = swapBrokenItem(pingedUser.Inventory, shieldIndex)
+			if pingedUser.Inventory[shieldIndex].Broken {
+				shieldIndex = -1
 			}
+		}
+
+		// Crossbow bolts go straight through shields
+		if item == "crossbow" {
+			shieldIndex = -1
+		}
+
+		if shieldIndex != -1 {
+			// Blocking the bullet wears down the pinged user's shield
+			pingedUser.Inventory = wearItem(pingedUser.Inventory, shieldIndex)
 			_, err = pingedUserCollection.UpdateOne(
 				ctx,
 				bson.D{
@@ -239,15 +270,15 @@ func Use(mongoURI string, guildID int, guildName string, userID int, userName st
 				},
 				bson.D{
 					{Key: "$set", Value: bson.D{
-						{Key: "inventory", Value: pingedUserInventory},
+						{Key: "inventory", Value: pingedUser.Inventory},
 					}},
 				},
 			)
 			if err != nil {
-				fmt.Printf("Error occurred while updating database! %s\n", err)
-				return "Error occurred while updating database! " + strings.Title(err.Error())
+				return UseResult{}, dbError("updating database", err)
 			}
-			return "You shot <@" + strconv.Itoa(pingedUserID) + "> with your gun, but they had a shield and it blocked the bullet!"
+			result.Outcome = "blocked"
+			return result, nil
 		}
 
 		// Otherwise, get the pinged user balance and rob them for a random percentage amount
@@ -265,8 +296,7 @@ func Use(mongoURI string, guildID int, guildName string, userID int, userName st
 			},
 		)
 		if err != nil {
-			fmt.Printf("Error occurred while updating database! %s\n", err)
-			return "Error occurred while updating database! " + strings.Title(err.Error())
+			return UseResult{}, dbError("updating database", err)
 		}
 
 		// Update the user's balance
@@ -282,7 +312,12 @@ func Use(mongoURI string, guildID int, guildName string, userID int, userName st
 				}},
 			},
 		)
-		return "You held up <@" + strconv.Itoa(pingedUserID) + "> at gunpoint and robbed " + strconv.Itoa(int(robbedAmount)) + " coins from them!"
+		if err != nil {
+			return UseResult{}, dbError("updating database", err)
+		}
+		result.Outcome = "robbed"
+		result.Amount = robbedAmount
+		return result, nil
 
 	case "bow":
 		// Check if the pinged user exists in the database
@@ -291,8 +326,7 @@ func Use(mongoURI string, guildID int, guildName string, userID int, userName st
 		var pingedUser User
 		err = pingedUserCollection.FindOne(ctx, pingedUserFilter).Decode(&pingedUser)
 		if err != nil {
-			fmt.Printf("That user is not currently playing the game!\n")
-			return "That user is not currently playing the game!"
+			return UseResult{}, ErrNotPlaying
 		}
 		pingedUserBalance := pingedUser.Balance
 
@@ -306,6 +340,14 @@ func Use(mongoURI string, guildID int, guildName string, userID int, userName st
 			}
 		}
 
+		// A broken gun is only useful if they have a spare
+		if gunIndex != -1 {
+			pingedUser.Inventory = swapBrokenItem(pingedUser.Inventory, gunIndex)
+			if pingedUser.Inventory[gunIndex].Broken {
+				gunIndex = -1
+			}
+		}
+
 		robbedAmount := int64(float64(pingedUserBalance) * (rand.Float64() * 0.1 + 0.2)) // Random percentage between 20% and 30% for you to rob
 		lostAmount := int64(float64(user.Balance) * (rand.Float64() * 0.1 + 0.1)) // Random percentage between 10% and 20% for you to lose
 
@@ -324,17 +366,11 @@ func Use(mongoURI string, guildID int, guildName string, userID int, userName st
 				},
 			)
 			if err != nil {
-				fmt.Printf("Error occurred while updating database! %s\n", err)
-				return "Error occurred while updating database! " + strings.Title(err.Error())
+				return UseResult{}, dbError("updating database", err)
 			}
 
-			// Reduce the pinged user's gun quantity by 1
-			pingedUser.Inventory[gunIndex].Quantity -= 1
-
-			// If the pinged user's gun quantity is 0, then remove it from their inventory
-			if pingedUser.Inventory[gunIndex].Quantity == 0 {
-				pingedUser.Inventory = append(pingedUser.Inventory[:gunIndex], pingedUser.Inventory[gunIndex+1:]...)
-			}
+			// Shooting back wears down the pinged user's gun
+			pingedUser.Inventory = wearItem(pingedUser.Inventory, gunIndex)
 			
 			// Update the pinged user's inventory
 			_, err = pingedUserCollection.UpdateOne(
@@ -350,10 +386,12 @@ func Use(mongoURI string, guildID int, guildName string, userID int, userName st
 				},
 			)
 			if err != nil {
-				fmt.Printf("Error occurred while updating database! %s\n", err)
-				return "Error occurred while updating database! " + strings.Title(err.Error())
+				return UseResult{}, dbError("updating database", err)
 			}
-			return "You tried to rob <@" + strconv.Itoa(pingedUserID) + "> with a bow, but they had a gun and shot you! You lost " + strconv.Itoa(int(lostAmount)) + " coins!"
+			metrics.CoinsBurned.Add("shot_back", float64(lostAmount))
+			result.Outcome = "shot_back"
+			result.Amount = lostAmount
+			return result, nil
 		
 		} else {
 			_, err = pingedUserCollection.UpdateOne(
@@ -369,8 +407,7 @@ func Use(mongoURI string, guildID int, guildName string, userID int, userName st
 				},
 			)
 			if err != nil {
-				fmt.Printf("Error occurred while updating database! %s\n", err)
-				return "Error occurred while updating database! " + strings.Title(err.Error())
+				return UseResult{}, dbError("updating database", err)
 			}
 
 			// Update the user's balance
@@ -386,7 +423,12 @@ func Use(mongoURI string, guildID int, guildName string, userID int, userName st
 					}},
 				},
 			)
-			return "You shot <@" + strconv.Itoa(pingedUserID) + "> and took " + strconv.Itoa(int(robbedAmount)) + " coins from them!"
+			if err != nil {
+				return UseResult{}, dbError("updating database", err)
+			}
+			result.Outcome = "robbed"
+			result.Amount = robbedAmount
+			return result, nil
 		}
 	
 	case "ring": // You check if the user is married earlier in the function
@@ -396,22 +438,21 @@ func Use(mongoURI string, guildID int, guildName string, userID int, userName st
 		var pingedUser User
 		err = pingedUserCollection.FindOne(ctx, pingedUserFilter).Decode(&pingedUser)
 		if err != nil {
-			fmt.Printf("That user is not currently playing the game!\n")
-			return "That user is not currently playing the game!"
+			return UseResult{}, ErrNotPlaying
 		}
 
 		// Check if the pinged user is married 
 		// If the married_to field is the user, then set married to true and return a different message
 		officiallyMarried := false
 		if pingedUser.MarriedTo != 0 && pingedUser.MarriedTo != userID {
-			return "That user is already married!"
+			return UseResult{}, ErrTargetMarried
 		} else if pingedUser.MarriedTo == userID {
 			officiallyMarried = true
 		}
 
 		// Check if the user is married
 		if user.MarriedTo != 0 {
-			return "You are already married!"
+			return UseResult{}, ErrAlreadyMarried
 		}
 
 		// Update the user's inventory to take away the ring
@@ -433,43 +474,43 @@ func Use(mongoURI string, guildID int, guildName string, userID int, userName st
 			},
 		)
 		if err != nil {
-			fmt.Printf("Error occurred while updating database! %s\n", err)
-			return "Error occurred while updating database! " + strings.Title(err.Error())
+			return UseResult{}, dbError("updating database", err)
 		}
 
 		if officiallyMarried {
-			return "🎉 Congratulations! You and <@" + strconv.Itoa(pingedUserID) + "> are now officially married! 🎉"
+			result.Outcome = "married"
+			result.unlocked(checkAchievements(ctx, userCollection, guildID, userID, eventMarried))
+			result.unlocked(checkAchievements(ctx, userCollection, guildID, pingedUserID, eventMarried))
 		} else {
-			return "You proposed to <@" + strconv.Itoa(pingedUserID) + "> with a ring! They now have to accept your proposal by using their own ring!"
+			result.Outcome = "proposed"
 		}
+		return result, nil
 	}
-	return "" 
+	return result, nil
 }
 
 // Divorce is its own function because it doesn't use an item
-func Divorce(mongoURI string, guildID int, guildName string, userID int, userName string, pingedUserID int) (string) {
+func Divorce(mongoURI string, guildID int, guildName string, userID int, userName string, pingedUserID int) (DivorceResult, error) {
 	// Connect to MongoDB
-	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI))
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
 	if err != nil {
-		fmt.Printf("Error occurred creating MongoDB client! %s\n", err)
-		return "Error occurred creating MongoDB client! " + strings.Title(err.Error())
+		return DivorceResult{}, dbError("creating MongoDB client", err)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
 	defer cancel() // Fix for memory leak
 	err = client.Connect(ctx)
 	if err != nil {
-		fmt.Printf("Error occurred while connecting to database! %s\n", err)
-		return "Error occurred while connecting to database! " + strings.Title(err.Error())
+		return DivorceResult{}, dbError("connecting to database", err)
 	}
 
 	// Disconnect from database
 	defer client.Disconnect(ctx) // Occurs as last line of main() function
 
 	// Check if user exists in database
-	res := IsPlaying(ctx, client, guildID, guildName, userID, userName)
-	if res != "" {
-		return res
+	err = IsPlaying(ctx, client, guildID, guildName, userID, userName)
+	if err != nil {
+		return DivorceResult{}, err
 	}
 
 	// Get user from database
@@ -478,13 +519,12 @@ func Divorce(mongoURI string, guildID int, guildName string, userID int, userNam
 	var user User // User struct defined in database.go
 	err = userCollection.FindOne(ctx, filter).Decode(&user)
 	if err != nil {
-		fmt.Printf("Error occurred while finding user in database! %s\n", err)
-		return "Error occurred while finding user in database! " + strings.Title(err.Error())
+		return DivorceResult{}, dbError("finding user in database", err)
 	}
 
 	// Check if user is married
 	if user.MarriedTo == 0 {
-		return "You are not married!"
+		return DivorceResult{}, ErrNotMarried
 	}
 
 	// Check if the pinged user exists in the database
@@ -493,12 +533,12 @@ func Divorce(mongoURI string, guildID int, guildName string, userID int, userNam
 	var pingedUser User
 	err = pingedUserCollection.FindOne(ctx, pingedUserFilter).Decode(&pingedUser)
 	if err != nil {
-		return "That user is not currently playing the game!"
+		return DivorceResult{}, ErrNotPlaying
 	}
 
 	// Check if the pinged user is married to the user
 	if user.MarriedTo != pingedUserID {
-		return "You are not married to that user!"
+		return DivorceResult{}, ErrNotMarriedToTarget
 	}
 
 	// Check if the pinged user is divorced
@@ -536,13 +576,8 @@ func Divorce(mongoURI string, guildID int, guildName string, userID int, userNam
 		},
 	)
 	if err != nil {
-		fmt.Printf("Error occurred while updating database! %s\n", err)
-		return "Error occurred while updating database! " + strings.Title(err.Error())
+		return DivorceResult{}, dbError("updating database", err)
 	}
 
-	if officiallyDivorced {
-		return "The papers have gone through. You and <@" + strconv.Itoa(pingedUserID) + "> are now officially divorced..."
-	} else {
-		return "You filed for divorce with <@" + strconv.Itoa(pingedUserID) + ">! They now have to sign the papers to finalize the divorce."
-	}
+	return DivorceResult{PartnerID: pingedUserID, Official: officiallyDivorced}, nil
 }
\ No newline at end of file
@@ -2,90 +2,242 @@ package database
 
 import (
 	"context"
-	"fmt"
+	"math"
 	"math/rand"
 	"strconv"
-	"strings"
 	"time"
+	"mary-bot/config"
+	"mary-bot/logging"
+	"mary-bot/metrics"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options" 
 	"go.mongodb.org/mongo-driver/bson"
 	commands "mary-bot/commands"
 )
 
+// A record of every robbery attempt (and bail payment) kept in the guild's "Robberies" collection
+type RobLog struct {
+	RobberID int       `bson:"robber_id"`
+	VictimID int       `bson:"victim_id"`
+	Outcome  string    `bson:"outcome"` // "success", "failure" or "bail"
+	Amount   int64     `bson:"amount"` // Coins stolen, fine paid or bail paid
+	Chance   float64   `bson:"chance"`
+	Time     time.Time `bson:"time"`
+}
+
+// What happened when a user robbed or paid someone
+type InteractionResult struct {
+	Operation  string // "rob" or "pay"
+	TargetID   int
+	TargetName string
+	Success    bool          // Whether the robbery worked
+	Amount     int64         // Coins stolen, fined or paid
+	JailTime   time.Duration // How long a failed robber was jailed for
+	Announcements
+}
+
+// Not a command
+// Stores a robbery outcome next to the Users collection
+// A failure to log shouldn't undo the robbery, so errors are only printed
+func logRobbery(ctx context.Context, userCollection *mongo.Collection, entry RobLog) {
+	robberyCollection := userCollection.Database().Collection("Robberies")
+	_, err := robberyCollection.InsertOne(ctx, entry)
+	if err != nil {
+		logging.Error("Error occurred while logging robbery!", "robber", entry.RobberID, "victim", entry.VictimID, "err", err)
+		metrics.ErrorsTotal.Inc("database")
+		return
+	}
+	logging.Info("Robbery logged", "robber", entry.RobberID, "victim", entry.VictimID, "outcome", entry.Outcome, "coins", entry.Amount)
+}
+
+// Helper to check whether an inventory holds at least one working copy of an item
+func hasItem(inventory []Item, name string) (bool) {
+	for _, item := range inventory {
+		if item.Name == name && item.Quantity > 0 && (!item.Broken || item.Quantity > 1) {
+			return true
+		}
+	}
+	return false
+}
+
+// The odds of a robbery succeeding, between 5% and 90%
+func robChance(robber User, victim User) (float64) {
+	chance := 0.5
+
+	// Robbing someone much richer than you is easier, robbing someone poorer is harder
+	if robber.Balance + victim.Balance > 0 {
+		chance += 0.2 * float64(victim.Balance - robber.Balance) / float64(victim.Balance + robber.Balance)
+	}
+
+	// A gun makes the robber more convincing, a shield makes the victim harder to rob
+	if hasItem(robber.Inventory, "gun") {
+		chance += 0.15
+	}
+	if hasItem(victim.Inventory, "shield") {
+		chance -= 0.25
+	}
+
+	return math.Max(0.05, math.Min(0.9, chance))
+}
+
+// Not a command
+// The fine for a failed robbery: 10% of the robber's balance, at least 50 coins but never more than they have
+func robFine(balance int64) (int64) {
+	fine := balance / 10
+	if fine < 50 {
+		fine = 50
+	}
+	if fine > balance {
+		fine = balance
+	}
+	if fine < 0 {
+		fine = 0
+	}
+	return fine
+}
+
 // mary rob @pingedUser
-func rob(ctx context.Context, userCollection *mongo.Collection, guildID int, userID int, pingedUserID int) (string) {
+func rob(ctx context.Context, userCollection *mongo.Collection, guildID int, userID int, pingedUserID int, settings config.Config) (InteractionResult, error) {
 	// Check if user is robbing themselves
 	if userID == pingedUserID {
-		return "You cannot rob yourself!"
+		return InteractionResult{}, ErrSelfTarget
 	}
-	
-	// Check if user has enough money to rob
-	userResult, err := userCollection.FindOne(
-		ctx,
-		bson.D{
-			{Key: "user_id", Value: pingedUserID},
-			{Key: "guild_id", Value: guildID},
-		},
-	).DecodeBytes()
+
+	// Get the robber and the victim
+	var robber User // User struct defined in items.go
+	err := userCollection.FindOne(ctx, bson.M{"guild_id": guildID, "user_id": userID}).Decode(&robber)
+	if err != nil {
+		return InteractionResult{}, dbError("selecting from database", err)
+	}
+	var victim User
+	err = userCollection.FindOne(ctx, bson.M{"guild_id": guildID, "user_id": pingedUserID}).Decode(&victim)
 	if err != nil {
-		fmt.Printf("Error occurred while selecting from database! %s\n", err)
-		return "Error occurred while selecting from database! " + strings.Title(err.Error())
+		return InteractionResult{}, dbError("selecting from database", err)
 	}
-	userBalance := userResult.Lookup("balance").Int64()
-	pingedUserName := userResult.Lookup("user_name").StringValue()
-	if userBalance < 100 {
-		return "That person is too poor to rob!"
+
+	// Check if the victim has enough money to rob
+	if victim.Balance < 100 {
+		return InteractionResult{}, ErrTooPoor
 	}
-	
-	// Check if user has robbed in the last 5 minutes
-	userLastRob := userResult.Lookup("last_rob").Time()
-	if time.Now().Sub(userLastRob) < 5 * time.Minute {
-		return "You have already robbed someone in the last 5 minutes! Please wait " + strconv.Itoa(int(5 - time.Now().Sub(userLastRob).Minutes())) + " minutes before robbing again."
+
+	// Check if the robber has robbed within the rob cooldown
+	if time.Since(robber.LastRob) < settings.Cooldowns.Rob {
+		return InteractionResult{}, ErrCooldown{Action: "rob", Remaining: settings.Cooldowns.Rob - time.Since(robber.LastRob)}
 	}
+	result := InteractionResult{Operation: "rob", TargetID: pingedUserID, TargetName: victim.UserName}
 
-	// Successful robbery
-	// Generate random number between 1-50
 	rand.Seed(time.Now().UnixNano())
-	robAmount := rand.Intn(50) + 1
-	
-	// Update user's balance
-	userCollection.FindOneAndUpdate(
-		ctx,
-		bson.D{
-			{Key: "user_id", Value: pingedUserID},
-			{Key: "guild_id", Value: guildID},
-		},
-		bson.D{
-			{Key: "$inc", Value: bson.D{
-				{Key: "balance", Value: robAmount},
-			}},
-		},
-	)
-	// Update pinged user's balance and user's last rob time
-	userCollection.FindOneAndUpdate(
+	chance := robChance(robber, victim)
+
+	if rand.Float64() < chance {
+		// Successful robbery - take between the server's minimum and maximum percent of the victim's balance (5% to 15% by default)
+		percent := float64(settings.Payouts.RobMinPercent) + rand.Float64() * float64(settings.Payouts.RobMaxPercent - settings.Payouts.RobMinPercent)
+		robAmount := int64(float64(victim.Balance) * percent / 100)
+		if robAmount < 1 {
+			robAmount = 1
+		}
+
+		// Only take the money if the victim still has it, the victim gets it back if paying the robber fails
+		ok, err := transferCoins(ctx, userCollection, guildID, pingedUserID, userID, robAmount)
+		if err == ErrNotPlaying {
+			return InteractionResult{}, err
+		} else if err != nil {
+			return InteractionResult{}, dbError("updating database", err)
+		} else if !ok {
+			return InteractionResult{}, ErrTooPoor
+		}
+
+		// Update the robber's stats and last rob time
+		_, err = userCollection.UpdateOne(
+			ctx,
+			bson.D{
+				{Key: "user_id", Value: userID},
+				{Key: "guild_id", Value: guildID},
+			},
+			bson.D{
+				{Key: "$inc", Value: bson.D{
+					{Key: "rob_successes", Value: 1},
+				}},
+				{Key: "$set", Value: bson.D{
+					{Key: "last_rob", Value: time.Now()},
+				}},
+			},
+		)
+		if err != nil {
+			return InteractionResult{}, dbError("updating database", err)
+		}
+
+		logRobbery(ctx, userCollection, RobLog{
+			RobberID: userID,
+			VictimID: pingedUserID,
+			Outcome: "success",
+			Amount: robAmount,
+			Chance: chance,
+			Time: time.Now(),
+		})
+		result.Success = true
+		result.Amount = robAmount
+		result.unlocked(checkAchievements(ctx, userCollection, guildID, userID, eventRob))
+		result.levelledUp(grantXP(ctx, userCollection, guildID, userID, xpPerAction))
+		return result, nil
+	}
+
+	// Failed robbery - the robber goes to jail and pays the victim a fine
+	update := userCollection.FindOneAndUpdate(
 		ctx,
 		bson.D{
-			{Key: "user_id", Value: pingedUserID},
+			{Key: "user_id", Value: userID},
 			{Key: "guild_id", Value: guildID},
 		},
 		bson.D{
-			{Key: "$inc", Value: bson.D{
-				{Key: "balance", Value: -robAmount},
-			}},
 			{Key: "$set", Value: bson.D{
 				{Key: "last_rob", Value: time.Now()},
+				{Key: "jailed_until", Value: time.Now().Add(robJailTime)},
 			}},
 		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
 	)
-	return "You successfully robbed " + strconv.Itoa(robAmount) + " coins from " + pingedUserName + "!"
+	err = update.Decode(&robber)
+	if err != nil {
+		return InteractionResult{}, dbError("updating database", err)
+	}
+
+	// The fine comes out of the robber's balance as it is now, and is worked out again if it drops before the fine goes through
+	fine := robFine(robber.Balance)
+	for fine > 0 {
+		ok, err := transferCoins(ctx, userCollection, guildID, userID, pingedUserID, fine)
+		if err == ErrNotPlaying {
+			return InteractionResult{}, err
+		} else if err != nil {
+			return InteractionResult{}, dbError("updating database", err)
+		} else if ok {
+			break
+		}
+		err = userCollection.FindOne(ctx, bson.M{"guild_id": guildID, "user_id": userID}).Decode(&robber)
+		if err != nil {
+			return InteractionResult{}, dbError("selecting from database", err)
+		}
+		fine = robFine(robber.Balance)
+	}
+
+	logRobbery(ctx, userCollection, RobLog{
+		RobberID: userID,
+		VictimID: pingedUserID,
+		Outcome: "failure",
+		Amount: fine,
+		Chance: chance,
+		Time: time.Now(),
+	})
+	result.Amount = fine
+	result.JailTime = robJailTime
+	return result, nil
 }
 
-func pay(ctx context.Context, userCollection *mongo.Collection, guildID int, userID int, pingedUserID int, amount int) (string) {
+func pay(ctx context.Context, userCollection *mongo.Collection, guildID int, userID int, pingedUserID int, amount int) (InteractionResult, error) {
 	// Check if user is paying themselves 
 	// Owner can pay themselves to test the command
 	if userID == pingedUserID && !commands.IsOwner(userID) {
-		return "You cannot pay yourself!"
+		return InteractionResult{}, ErrSelfTarget
 	}
 	
 	// Check if user has enough money to pay
@@ -97,71 +249,65 @@ func pay(ctx context.Context, userCollection *mongo.Collection, guildID int, use
 		},
 	).DecodeBytes()
 	if err != nil {
-		fmt.Printf("Error occurred while selecting from database! %s\n", err)
-		return "Error occurred while selecting from database! " + strings.Title(err.Error())
+		return InteractionResult{}, dbError("selecting from database", err)
 	}
 	userBalance := int(userResult.Lookup("balance").Int64())
 	if userBalance < amount && !commands.IsOwner(userID) {
-		return "You do not have enough money to pay that amount!"
+		return InteractionResult{}, ErrCantAfford{Cost: int64(amount), Balance: int64(userBalance)}
 	}
 
-	// Update user's balance if not owner -> owner can pay an infinite amount
-	if !commands.IsOwner(userID) {
-		userCollection.FindOneAndUpdate(
-			ctx,
-			bson.D{
-				{Key: "user_id", Value: userID},
-				{Key: "guild_id", Value: guildID},
-			},
-			bson.D{
-				{Key: "$inc", Value: bson.D{
-					{Key: "balance", Value: -amount},
-				}},
-			},
-		)
+	// Owner can pay an infinite amount, so only the pinged user's balance changes
+	if commands.IsOwner(userID) {
+		ok, err := adjustBalance(ctx, userCollection, guildID, pingedUserID, int64(amount))
+		if err != nil {
+			return InteractionResult{}, dbError("updating database", err)
+		} else if !ok {
+			return InteractionResult{}, ErrNotPlaying
+		}
+		return InteractionResult{Operation: "pay", TargetID: pingedUserID, Success: true, Amount: int64(amount)}, nil
 	}
 
-	// Update pinged user's balance
-	userCollection.FindOneAndUpdate(
-		ctx,
-		bson.D{
-			{Key: "user_id", Value: pingedUserID},
-			{Key: "guild_id", Value: guildID},
-		},
-		bson.D{
-			{Key: "$inc", Value: bson.D{
-				{Key: "balance", Value: amount},
-			}},
-		},
-	)
-	// Ping user with return message
-	return "You successfully paid <@" + strconv.Itoa(pingedUserID) + "> " + strconv.Itoa(amount) + " coins!"
+	// The balance is checked again as part of the update, so two payments at once can't spend the same coins
+	ok, err := transferCoins(ctx, userCollection, guildID, userID, pingedUserID, int64(amount))
+	if err == ErrNotPlaying {
+		return InteractionResult{}, err // The pinged user left since they were looked up, and the coins were refunded
+	} else if err != nil {
+		return InteractionResult{}, dbError("updating database", err)
+	}
+	if !ok {
+		return InteractionResult{}, ErrCantAfford{Cost: int64(amount), Balance: int64(userBalance)}
+	}
+	return InteractionResult{Operation: "pay", TargetID: pingedUserID, Success: true, Amount: int64(amount)}, nil
 }
 
 // All the economy commands that require pinging another user
-func UserInteraction(mongoURI string, guildID int, guildName string, userID int, userName string, pingedUserID int, operation string, amount int) (string) {
+func UserInteraction(mongoURI string, guildID int, guildName string, userID int, userName string, pingedUserID int, operation string, amount int) (InteractionResult, error) {
 	// Connect to MongoDB
-	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI))
+	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor))
 	if err != nil {
-		fmt.Printf("Error occurred creating MongoDB client! %s\n", err)
-		return "Error occurred creating MongoDB client! " + strings.Title(err.Error())
+		return InteractionResult{}, dbError("creating MongoDB client", err)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for connection is 10 secs
 	defer cancel() // Fix for memory leak
 	err = client.Connect(ctx)
 	if err != nil {
-		fmt.Printf("Error occurred while connecting to database! %s\n", err)
-		return "Error occurred while connecting to database! " + strings.Title(err.Error())
+		return InteractionResult{}, dbError("connecting to database", err)
 	}
 
 	// Disconnect from database
 	defer client.Disconnect(ctx) // Occurs as last line of main() function
 
 	// Check if user exists in database
-	res := IsPlaying(ctx, client, guildID, guildName, userID, userName)
-	if res != "" {
-		return res
+	err = IsPlaying(ctx, client, guildID, guildName, userID, userName)
+	if err != nil {
+		return InteractionResult{}, err
+	}
+
+	// Jailed users can't rob or pay anyone
+	err = IsJailed(ctx, client, guildID, userID)
+	if err != nil {
+		return InteractionResult{}, err
 	}
 
 	// Select database and collection
@@ -178,16 +324,20 @@ func UserInteraction(mongoURI string, guildID int, guildName string, userID int,
 	).DecodeBytes()
 	_ = collectionResult // Unused variable
 	if err != nil {
-		fmt.Printf("That person is not currently playing the game!\n")
-		return "That person is not currently playing the game!"
+		return InteractionResult{}, ErrNotPlaying
 	}
 
 	switch operation {
 		case "rob":
-			return rob(ctx, userCollection, guildID, userID, pingedUserID)
+			// Cooldowns and payouts can be changed per server
+			settings, err := guildSettings(ctx, serverDatabase)
+			if err != nil {
+				return InteractionResult{}, err
+			}
+			return rob(ctx, userCollection, guildID, userID, pingedUserID, settings)
 		case "pay":
 			return pay(ctx, userCollection, guildID, userID, pingedUserID, amount)
 		default: 
-			return "I'm sorry, I dont recognize that command."
+			return InteractionResult{}, ErrUnknownCommand
 	}
 }
\ No newline at end of file
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRobChance(t *testing.T) {
+	gun := []Item{{Name: "gun", Quantity: 1}}
+	shield := []Item{{Name: "shield", Quantity: 1}}
+	brokenShield := []Item{{Name: "shield", Quantity: 1, Broken: true}}
+
+	tests := []struct {
+		name   string
+		robber User
+		victim User
+		want   float64
+	}{
+		{"both broke", User{}, User{}, 0.5},
+		{"equal balances", User{Balance: 1000}, User{Balance: 1000}, 0.5},
+		{"richer victim", User{Balance: 0}, User{Balance: 1000}, 0.7},
+		{"poorer victim", User{Balance: 3000}, User{Balance: 1000}, 0.4},
+		{"gun", User{Inventory: gun}, User{}, 0.65},
+		{"shield", User{}, User{Inventory: shield}, 0.25},
+		{"broken shield", User{}, User{Inventory: brokenShield}, 0.5},
+		{"gun and richer victim", User{Inventory: gun}, User{Balance: 1000}, 0.85},
+		{"worst odds", User{Balance: 1000}, User{Inventory: shield}, 0.05},
+	}
+	for _, test := range tests {
+		if got := robChance(test.robber, test.victim); math.Abs(got - test.want) > 1e-9 {
+			t.Errorf("%s: robChance() = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestRobFine(t *testing.T) {
+	tests := []struct {
+		balance int64
+		want    int64
+	}{
+		{-100, 0},
+		{0, 0},
+		{30, 30},
+		{50, 50},
+		{400, 50},
+		{500, 50},
+		{10000, 1000},
+	}
+	for _, test := range tests {
+		if got := robFine(test.balance); got != test.want {
+			t.Errorf("robFine(%d) = %d, want %d", test.balance, got, test.want)
+		}
+	}
+}
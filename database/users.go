@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"strconv"
+	"time"
+	"mary-bot/logging"
+	"mary-bot/metrics"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Not a command
+// Every field a new user starts with, apart from user_id and guild_id which come from the filter
+// Anything that creates users goes through this, so they all look the same
+func newUser(guildName string, userName string, startingBalance int) (bson.D) {
+	// A day ago, so no cooldown is running
+	yesterday := time.Now().AddDate(0, 0, -1)
+	return bson.D{
+		{Key: "user_name", Value: userName},
+		{Key: "guild_name", Value: guildName},
+		{Key: "balance", Value: int64(startingBalance)}, // Enter balance as int64 value
+		{Key: "last_daily", Value: yesterday},
+		{Key: "last_beg", Value: yesterday},
+		{Key: "last_rob", Value: yesterday},
+		{Key: "last_gamble", Value: yesterday},
+		{Key: "last_trivia", Value: yesterday},
+		{Key: "last_use", Value: yesterday},
+		{Key: "jailed_until", Value: time.Time{}},
+		{Key: "married_to", Value: 0},
+		{Key: "trivia_wins", Value: 0},
+		{Key: "gamble_profit", Value: int64(0)},
+		{Key: "rob_successes", Value: 0},
+		{Key: "daily_streak", Value: 0},
+		{Key: "xp", Value: int64(0)},
+		{Key: "level", Value: 0},
+		{Key: "achievements", Value: bson.A{}},
+		{Key: "inventory", Value: bson.A{}},
+		{Key: "schema_version", Value: schemaVersion}, // Has every field, so migrations can skip it
+	}
+}
+
+// Not a command
+// Helper to leave fields out of a document, e.g. the ones an upsert already $sets
+// MongoDB refuses an update that touches the same field twice
+func withoutFields(doc bson.D, keys ...string) (bson.D) {
+	result := bson.D{}
+	for _, field := range doc {
+		keep := true
+		for _, key := range keys {
+			if field.Key == key {
+				keep = false
+			}
+		}
+		if keep {
+			result = append(result, field)
+		}
+	}
+	return result
+}
+
+// Not a command
+// Adds the user to the guild if they aren't in it yet, returning true if they were just added
+// This is a single upsert backed by the unique (guild_id, user_id) index,
+// so two commands from a new user at the same time can't make two documents
+func register(ctx context.Context, client *mongo.Client, guildID int, guildName string, userID int, userName string) (bool, error) {
+	// If database for server doesn't exist, create it
+	serverDatabase := client.Database(strconv.Itoa(guildID))
+	userCollection := serverDatabase.Collection("Users")
+	filter := bson.D{
+		{Key: "user_id", Value: userID},
+		{Key: "guild_id", Value: guildID},
+	}
+
+	// Most commands come from users who are already playing, so check before loading the settings
+	err := userCollection.FindOne(ctx, filter, options.FindOne().SetProjection(bson.D{{Key: "_id", Value: 1}})).Err()
+	if err == nil {
+		return false, nil
+	} else if err != mongo.ErrNoDocuments {
+		return false, dbError("selecting from database", err)
+	}
+
+	// The first user might also be the first in the guild, which needs its indexes
+	err = userIndexes(ctx, userCollection)
+	if err != nil {
+		return false, err
+	}
+
+	// New players start with the server's starting balance
+	settings, err := guildSettings(ctx, serverDatabase)
+	if err != nil {
+		return false, err
+	}
+
+	// $setOnInsert leaves the user alone if another command got there first
+	result, err := userCollection.UpdateOne(
+		ctx,
+		filter,
+		bson.D{{Key: "$setOnInsert", Value: newUser(guildName, userName, settings.StartingBalance)}},
+		options.Update().SetUpsert(true),
+	)
+	// Two upserts at once can both miss, and the unique index turns the loser into a duplicate key error
+	// The user is there either way
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	} else if err != nil {
+		return false, dbError("inserting to database", err)
+	}
+	if result.UpsertedCount == 0 {
+		return false, nil
+	}
+	metrics.CoinsMinted.Add("starting_balance", float64(settings.StartingBalance))
+	logging.Info("Inserted user into database", "guild", guildID, "user", userID, "user_name", userName, "id", result.UpsertedID)
+	return true, nil
+}
+
+// Not a command
+// A helper function accessible everywhere that checks if the user is in the database
+// If they're not, it adds them to the database
+func IsPlaying(ctx context.Context, client *mongo.Client, guildID int, guildName string, userID int, userName string) (error) {
+	_, err := register(ctx, client, guildID, guildName, userID, userName)
+	return err
+}
@@ -0,0 +1,51 @@
+// Package health tells the hosting platform whether Mary is alive (/healthz) and able to serve commands (/readyz)
+package health
+
+import (
+	"net/http"
+	"mary-bot/database"
+	"mary-bot/logging"
+	"mary-bot/metrics"
+	"github.com/bwmarrin/discordgo"
+)
+
+// /healthz
+// OK while the Discord gateway connection is up, so the bot gets restarted if it drops for good
+func Healthz(session *discordgo.Session) (http.Handler) {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session.RLock()
+		connected := session.DataReady
+		session.RUnlock()
+
+		if !connected {
+			logging.Warn("Health check failed, Discord gateway is not connected")
+			metrics.ErrorsTotal.Inc("health")
+			respond(w, http.StatusServiceUnavailable, "discord gateway not connected")
+			return
+		}
+		respond(w, http.StatusOK, "ok")
+	})
+}
+
+// /readyz
+// OK when MongoDB answers a ping, since every command needs the database
+func Readyz(mongoURI string) (http.Handler) {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := database.TestConnection(mongoURI)
+		if err != nil {
+			logging.Warn("Readiness check failed, MongoDB is not reachable", "err", err)
+			metrics.ErrorsTotal.Inc("health")
+			respond(w, http.StatusServiceUnavailable, "mongodb not reachable")
+			return
+		}
+		respond(w, http.StatusOK, "ok")
+	})
+}
+
+// Not a command
+// Writes a short plain text status
+func respond(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write([]byte(body + "\n"))
+}
@@ -0,0 +1,171 @@
+// Package logging writes leveled, structured log lines, e.g.
+// time=2022-11-05T14:03:12Z level=error msg="Error occurred while updating database!" guild=123 user=456 err="..."
+// Fields are passed as key/value pairs after the message
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+// Lines below this level are dropped
+var MinLevel = LevelInfo
+
+// Write lines as JSON objects instead of key=value pairs
+var JSON = false
+
+var output = log.New(os.Stderr, "", 0)
+
+// Gets a level from its name, e.g. "warn"
+func ParseLevel(name string) (Level, bool) {
+	for level, levelName := range levelNames {
+		if strings.EqualFold(levelName, name) {
+			return level, true
+		}
+	}
+	return LevelInfo, false
+}
+
+// A logger that adds the same fields to every line, e.g. the guild and user a command came from
+type Logger struct {
+	fields []interface{}
+}
+
+// Gets a logger that adds fields to every line
+func With(fields ...interface{}) (Logger) {
+	return Logger{}.With(fields...)
+}
+
+// Gets a logger with more fields added
+func (logger Logger) With(fields ...interface{}) (Logger) {
+	combined := make([]interface{}, 0, len(logger.fields) + len(fields))
+	combined = append(combined, logger.fields...)
+	combined = append(combined, fields...)
+	return Logger{fields: combined}
+}
+
+func (logger Logger) Debug(msg string, fields ...interface{}) {
+	logger.write(LevelDebug, msg, fields)
+}
+
+func (logger Logger) Info(msg string, fields ...interface{}) {
+	logger.write(LevelInfo, msg, fields)
+}
+
+func (logger Logger) Warn(msg string, fields ...interface{}) {
+	logger.write(LevelWarn, msg, fields)
+}
+
+func (logger Logger) Error(msg string, fields ...interface{}) {
+	logger.write(LevelError, msg, fields)
+}
+
+// Logging without any fields added up front
+func Debug(msg string, fields ...interface{}) {
+	Logger{}.write(LevelDebug, msg, fields)
+}
+
+func Info(msg string, fields ...interface{}) {
+	Logger{}.write(LevelInfo, msg, fields)
+}
+
+func Warn(msg string, fields ...interface{}) {
+	Logger{}.write(LevelWarn, msg, fields)
+}
+
+func Error(msg string, fields ...interface{}) {
+	Logger{}.write(LevelError, msg, fields)
+}
+
+// Helper to write one line
+func (logger Logger) write(level Level, msg string, fields []interface{}) {
+	if level < MinLevel {
+		return
+	}
+	all := append(append([]interface{}{
+		"time", time.Now().UTC().Format(time.RFC3339),
+		"level", levelNames[level],
+		"msg", msg,
+	}, logger.fields...), fields...)
+
+	if JSON {
+		line := map[string]interface{}{}
+		for i := 0; i < len(all); i += 2 {
+			line[key(all, i)] = jsonValue(value(all, i))
+		}
+		encoded, err := json.Marshal(line)
+		if err != nil {
+			output.Printf("level=error msg=\"Error occurred while encoding log line!\" err=%q", err.Error())
+			return
+		}
+		output.Println(string(encoded))
+		return
+	}
+
+	pairs := []string{}
+	for i := 0; i < len(all); i += 2 {
+		pairs = append(pairs, key(all, i) + "=" + textValue(value(all, i)))
+	}
+	output.Println(strings.Join(pairs, " "))
+}
+
+// Helpers to read a key/value pair, in case a key was passed without a value
+func key(fields []interface{}, i int) (string) {
+	return fmt.Sprint(fields[i])
+}
+
+func value(fields []interface{}, i int) (interface{}) {
+	if i + 1 < len(fields) {
+		return fields[i+1]
+	}
+	return "(missing)"
+}
+
+// Helper to write a value as text, quoting it if it has spaces or quotes in it
+func textValue(v interface{}) (string) {
+	text := ""
+	switch v := v.(type) {
+	case error:
+		text = v.Error()
+	case time.Duration:
+		text = v.String()
+	default:
+		text = fmt.Sprint(v)
+	}
+	if text == "" || strings.ContainsAny(text, " \"=\n\t") {
+		return strconv.Quote(text)
+	}
+	return text
+}
+
+// Helper to make errors and durations readable in JSON
+func jsonValue(v interface{}) (interface{}) {
+	switch v := v.(type) {
+	case error:
+		return v.Error()
+	case time.Duration:
+		return v.String()
+	}
+	return v
+}
@@ -1,11 +1,22 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"mary-bot/cards"
+	"mary-bot/cli"
+	"math"
 	"mary-bot/commands"
 	database "mary-bot/database"
+	"mary-bot/config"
+	"mary-bot/health"
+	"mary-bot/logging"
+	"mary-bot/metrics"
+	"mary-bot/replies"
 	"net/http"
 	"os"
 	"os/signal"
@@ -20,22 +31,31 @@ import (
 )
 
 func main() {
-	// Load token from env vars
-	// envErr := godotenv.Load(".env")
-	// if envErr != nil {
-	// 	fmt.Printf("Error loading environment variables! %s\n", envErr)
-	// 	return
-	// }
-	TOKEN := os.Getenv("TOKEN")
-	if TOKEN == "" {
-		fmt.Println("Token not found!")
-		return
+	os.Exit(cli.Run(os.Args[1:], serve))
+}
+
+// mary-bot serve, or mary-bot on its own
+// Runs the bot until it's stopped, returning the exit code
+func serve() (int) {
+	err := config.RequireToken()
+	if err != nil {
+		logging.Error("Error loading configuration!", "err", err)
+		return 1
 	}
-	
-	discord, discordError := discordgo.New("Bot " + TOKEN)
+
+	// Bring old user documents up to date and make sure every guild has its indexes
+	// The bot still works with old documents, so a failure here doesn't stop it starting
+	guildIDs, err := database.Migrate(config.Current.MongoURI)
+	if err != nil {
+		logging.Error("Error migrating database!", "err", err)
+	} else {
+		logging.Info("Database up to date", "guilds", len(guildIDs))
+	}
+
+	discord, discordError := discordgo.New("Bot " + config.Current.Token)
 	if discordError != nil {
-		fmt.Printf("Error creating Discord session! %s\n", discordError)
-		return
+		logging.Error("Error creating Discord session!", "err", discordError)
+		return 1
 	}
 
 	// Handler for sending messages
@@ -43,27 +63,47 @@ func main() {
 	// https://github.com/bwmarrin/discordgo/issues/1264
 	discord.Identify.Intents = discordgo.IntentMessageContent
 	discord.AddHandler(createMessage)
+	discord.AddHandler(commands.HandlePaginator) // Buttons on shop, inventory and leaderboard pages
+
+	// Give out level role rewards through this session
+	database.AssignRole = func(guildID int, userID int, roleID string) (error) {
+		return discord.GuildMemberRoleAdd(strconv.Itoa(guildID), strconv.Itoa(userID), roleID)
+	}
 	discord.Identify.Intents = discordgo.IntentsGuildMessages
+
+	// Serve /healthz, /readyz and Prometheus metrics on /metrics if an address is set, e.g. HTTP_ADDR=:8080
+	if config.Current.HTTPAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/healthz", health.Healthz(discord))
+		mux.Handle("/readyz", health.Readyz(config.Current.MongoURI))
+		mux.Handle("/metrics", metrics.Handler())
+		go func() {
+			err := http.ListenAndServe(config.Current.HTTPAddr, mux)
+			logging.Error("Error serving HTTP!", "addr", config.Current.HTTPAddr, "err", err)
+		}()
+		logging.Info("Serving health checks and metrics", "addr", config.Current.HTTPAddr)
+	}
 	
-	err := discord.Open()
+	err = discord.Open()
 	if err != nil {
-		fmt.Println("Error opening Discord connection!")
-		return
+		logging.Error("Error opening Discord connection!", "err", err)
+		return 1
 	}
 
 	// Set Mary's status (make sure to do this after discord.Open())
-	err = discord.UpdateGameStatus(0, "with her sister Eve")
+	err = discord.UpdateGameStatus(0, config.Current.Status)
 	if err != nil {
-		fmt.Printf("Error setting Mary's status! %s\n", err)
-		return
+		logging.Error("Error setting Mary's status!", "err", err)
+		return 1
 	}
 	
-	fmt.Println("Mary, online and ready!")
+	logging.Info("Mary, online and ready!")
 
 	sc := make(chan os.Signal, 1)
     signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, os.Interrupt, os.Kill)
     <-sc
 	discord.Close()
+	return 0
 }
 
 func createMessage(session *discordgo.Session, message *discordgo.MessageCreate) {
@@ -75,374 +115,410 @@ func createMessage(session *discordgo.Session, message *discordgo.MessageCreate)
 	if message.Content == "test" {
 		_, err := session.ChannelMessageSend(message.ChannelID, "Test successful!")
 		if err != nil {
-			fmt.Printf("Error occurred during testing! %s\n", err)
+			logging.Error("Error occurred during testing!", "channel", message.ChannelID, "err", err)
+			metrics.ErrorsTotal.Inc("discord")
 		} else {
 			return
 		}
 	}
 
 	// Get URI for connecting to MongoDB database
-	MONGO_URI := os.Getenv("MONGO_URI")
-	if MONGO_URI == "" {
-		fmt.Println("MongoDB URI not found!")
-		return
-	}
+	MONGO_URI := config.Current.MongoURI
 
 	// Get guild ID and name
 	guild, err1 := session.Guild(message.GuildID)
 	guildID, err2 := strconv.Atoi(guild.ID)
 	guildName := guild.Name
 	if err1 != nil {
-		fmt.Printf("Error retrieving guild details! %s\n", err1)
+		logging.Error("Error retrieving guild details!", "guild", message.GuildID, "err", err1)
+		metrics.ErrorsTotal.Inc("discord")
 	} else if err2 != nil {
-		fmt.Printf("Error converting guild ID! %s\n", err1)
+		logging.Error("Error converting guild ID!", "guild", message.GuildID, "err", err2)
 	}
 	userID, err3 := strconv.Atoi(message.Author.ID)
 	if err3 != nil {
-		fmt.Printf("Error converting user ID! %s\n", err1)
+		logging.Error("Error converting user ID!", "user", message.Author.ID, "err", err3)
 	}
 	userName := message.Author.Username
+	logger := logging.With("guild", guildID, "user", userID)
+
+	// Chatting earns XP, in the background so commands aren't slowed down
+	go func() {
+		levelUp := database.GrantMessageXP(MONGO_URI, guildID, userID)
+		if levelUp != nil {
+			language, _ := database.Language(MONGO_URI, guildID, userID) // Falls back to English if it can't be found
+			session.ChannelMessageSend(message.ChannelID, replies.For(language).LevelUp(*levelUp))
+		}
+	}()
 
 	command := strings.Split(message.Content, " ")
 	if strings.ToLower(command[0]) == "mary" {
+		// Log and count every command, with how long it took
+		name := commandName(command)
+		logger = logger.With("command", name)
+		start := time.Now()
+		defer func() {
+			metrics.CommandsTotal.Inc(name)
+			logger.Info("Handled command", "latency", time.Since(start))
+		}()
+
+		// Reply in the user's language, or the server's if they haven't picked one,
+		// with the server's currency, or the bot's if they can't be found
+		language, settings, err := database.Preferences(MONGO_URI, guildID, userID)
+		if err != nil {
+			logger.Error("Error retrieving language and server settings!", "err", err)
+		}
+		reply := replies.For(language)
+		reply.Currency = settings.Currency
+
+		// One command that changes a user's data at a time, so the same coins can't be spent twice
+		if changesUser[name] {
+			unlock, ok := commands.LockUser(guildID, userID)
+			if !ok {
+				logger.Debug("Turned away command while another was running")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("busy", "<@" + message.Author.ID + ">"))
+				return
+			}
+			defer unlock()
+		}
+
 		switch true {
 		
 		// mary test
 		case strings.ToLower(command[1]) == "test" && len(command) == 2:
-			session.ChannelMessageSend(message.ChannelID, "Test successful!")
+			session.ChannelMessageSend(message.ChannelID, reply.Text("test.success"))
 		
 		// mary test connection -> checks if mongoDB connection is working
 		case strings.ToLower(command[1]) == "test" && strings.ToLower(command[2]) == "connection":
 			dbErr := database.TestConnection(MONGO_URI)
-			if dbErr != "" {
-				session.ChannelMessageSend(message.ChannelID, dbErr)
+			if dbErr != nil {
+				session.ChannelMessageSend(message.ChannelID, reply.Error(userID, dbErr))
 			} else {
-				session.ChannelMessageSend(message.ChannelID, "Database connection successful!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("test.connection"))
 			}
 
 		// mary help -> shows all commands
 		case strings.ToLower(command[1]) == "help":
-			if len(command) == 2 {
-				// Get Mary's avatar
-				mary, err := discordgo.New("Bot " + os.Getenv("TOKEN"))
-				if err != nil {
-					session.ChannelMessageSend(message.ChannelID, "Error retrieving my avatar!")
-				}
-				maryUser, err := mary.User("@me")
-				if err != nil {
-					session.ChannelMessageSend(message.ChannelID, "Error retrieving my avatar!")
-				}
-				maryAvatar := maryUser.AvatarURL("")
-
-				// Create a rich embed
-				embed := &discordgo.MessageEmbed{
-					Title: "Mary's Commands",
-					Color: 0xffc0cb,
-					Thumbnail: &discordgo.MessageEmbedThumbnail{
-						URL: maryAvatar,
-					},
-					Fields: []*discordgo.MessageEmbedField{{
-							Name: "mary help [optional: page number]",
-							Value: "Shows all commands. The default page number is 1.",
-						},{
-							Name: "mary test",
-							Value: "Tests if Mary is online.",
-						},{
-							Name: "mary test connection",
-							Value: "Tests if Mary can connect to the database.",
-						},{
-							Name: "mary del [amount] (admin only)",
-							Value: "Deletes a set number of messages.",
-						},{
-							Name: "mary bankrupt @user (admin only)",
-							Value: "Reduces the user's balance to 0.",
-						},{
-							Name: "mary quote",
-							Value: "Shows a random quote.",
-						},{
-							Name: "mary profile [optional: @user]",
-							Value: "Shows your profile or a specified user's profile.",
-						},{
-							Name: "mary bal [optional: @user]",
-							Value: "Shows your balance or a specified user's balance.",
-						},{
-							Name: "mary inventory",
-							Value: "Shows your inventory.",
-						},{ 
-							Name: "mary give @user [item name] [optional: amount]",
-							Value: "Gives an item to a specified user. The default amount is 1.",
-						},
-					},
-					Footer: &discordgo.MessageEmbedFooter{
-						Text: "Page 1/3",
-					},
+			// If the user does not declare a page number, default to page 1
+			pageNumber := 1
+			if len(command) == 3 { // mary help [page number]
+				// Check if the page number is a number
+				num, err := strconv.Atoi(command[2])
+				if err != nil || num < 1 || num > replies.HelpPages() {
+					session.ChannelMessageSend(message.ChannelID, reply.Text("help.invalid_page"))
+					return
 				}
-				session.ChannelMessageSendEmbed(message.ChannelID, embed)
-		
-		} else if len(command) == 3 { // mary help [page number]
-			// Check if the page number is a number
-			pageNumber, err := strconv.Atoi(command[2])
-			if err != nil {
-				session.ChannelMessageSend(message.ChannelID, "Please enter a valid page number!")
-				return
-			}
-
-			if pageNumber < 1 || pageNumber > 3 {
-				session.ChannelMessageSend(message.ChannelID, "Please enter a valid page number!")
-				return
+				pageNumber = num
 			}
 
 			// Get Mary's avatar
-			mary, err := discordgo.New("Bot " + os.Getenv("TOKEN"))
+			mary, err := discordgo.New("Bot " + config.Current.Token)
 			if err != nil {
-				session.ChannelMessageSend(message.ChannelID, "Error retrieving my avatar!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("help.avatar_error"))
 			}
 			maryUser, err := mary.User("@me")
 			if err != nil {
-				session.ChannelMessageSend(message.ChannelID, "Error retrieving my avatar!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("help.avatar_error"))
 			}
 			maryAvatar := maryUser.AvatarURL("")
 
-			if pageNumber == 1 {
-				// Create a rich embed
-				embed := &discordgo.MessageEmbed{
-					Title: "Mary's Commands",
-					Color: 0xffc0cb,
-					Thumbnail: &discordgo.MessageEmbedThumbnail{
-						URL: maryAvatar,
-					},
-					Fields: []*discordgo.MessageEmbedField{{
-							Name: "mary help [optional: page number]",
-							Value: "Shows all commands. The default page number is 1.",
-						},{
-							Name: "mary test",
-							Value: "Tests if Mary is online.",
-						},{
-							Name: "mary test connection",
-							Value: "Tests if Mary can connect to the database.",
-						},{
-							Name: "mary del [amount] (admin only)",
-							Value: "Deletes a set number of messages.",
-						},{
-							Name: "mary bankrupt @user (admin only)",
-							Value: "Reduces the user's balance to 0.",
-						},{
-							Name: "mary quote",
-							Value: "Shows a random quote.",
-						},{
-							Name: "mary profile [optional: @user]",
-							Value: "Shows your profile or a specified user's profile.",
-						},{
-							Name: "mary bal [optional: @user]",
-							Value: "Shows your balance or a specified user's balance.",
-						},{
-							Name: "mary inventory",
-							Value: "Shows your inventory.",
-						},{
-							Name: "mary give @user [item name] [optional: amount]",
-							Value: "Gives an item to a specified user. The default amount is 1.",
-						},
-					},
-					Footer: &discordgo.MessageEmbedFooter{
-						Text: "Page 1/3",
-					},
+			session.ChannelMessageSendEmbed(message.ChannelID, reply.Help(pageNumber, maryAvatar, settings))
+
+		// mary language [code/default] -> shows or sets the language Mary replies to the user in
+		// mary language server [code] -> sets it for everyone on the server who hasn't picked one (needs Manage Server)
+		case strings.ToLower(command[1]) == "language" || strings.ToLower(command[1]) == "lang":
+			if len(command) == 2 {
+				session.ChannelMessageSend(message.ChannelID, reply.Text("language.current", replies.LanguageName(reply.Language), replies.LanguageNames()))
+				break
+			}
+			if strings.ToLower(command[2]) == "server" {
+				permissions, err := session.UserChannelPermissions(message.Author.ID, message.ChannelID)
+				if err != nil || permissions & discordgo.PermissionManageServer == 0 {
+					session.ChannelMessageSend(message.ChannelID, reply.Text("language.no_permission"))
+					break
 				}
-				session.ChannelMessageSendEmbed(message.ChannelID, embed)
-			
-			} else if pageNumber == 2 {
-				// Create a rich embed
-				embed := &discordgo.MessageEmbed{
-					Title: "Mary's Commands",
-					Color: 0xffc0cb,
-					Thumbnail: &discordgo.MessageEmbedThumbnail{
-						URL: maryAvatar,
-					},
-					Fields: []*discordgo.MessageEmbedField{{
-							Name: "mary shop [optional: page number]",
-							Value: "Shows the shop. You can also specify a page number.",
-						},{
-							Name: "mary buy [item name] [optional: amount]",
-							Value: "Buys the specified item. The default amount is 1.",
-						},{
-							Name: "mary sell [item name] [optional: amount]",
-							Value: "Sells the specified item at half the original price.",
-						},{
-							Name: "mary daily",
-							Value: "Gives you 100 coins.",
-						},{
-							Name: "mary pay @user [amount]",
-							Value: "Pays the mentioned user the specified amount of coins.",
-						},{
-							Name: "mary top/leaderboard",
-							Value: "Shows the top 10 users with the highest balance.",
-						},{
-							Name: "mary trivia [optional: amount]",
-							Value: "Starts a trivia game. Pays 50, 100, or 200 coins upon win depending on the difficulty. You can also gamble for 2X, 3X, 5X your bet.",
-						},{
-							Name: "mary gamble [amount]",
-							Value: "Gamble the specified amount of coins.",
-						},{
-							Name: "mary lottery [amount]",
-							Value: "Enter the lottery with 100 coins.",
-						},{
-							Name: "mary slots [amount]",
-							Value: "Play slots with 10 coins.",
-						},
-					},
-					Footer: &discordgo.MessageEmbedFooter{
-						Text: "Page 2/3",
-					},
+				if len(command) < 4 {
+					session.ChannelMessageSend(message.ChannelID, reply.Text("language.server_usage"))
+					break
 				}
-				session.ChannelMessageSendEmbed(message.ChannelID, embed)	
-			} else if pageNumber == 3 {
-				// Create a rich embed
-				embed := &discordgo.MessageEmbed{
-					Title: "Mary's Commands",
-					Color: 0xffc0cb,
-					Thumbnail: &discordgo.MessageEmbedThumbnail{
-						URL: maryAvatar,
-					},
-					Fields: []*discordgo.MessageEmbedField{{
-						Name: "mary use [item name] @user",
-						Value: "Uses the specified item on the mentioned user. You can only use one item at a time.",
-					},{
-						Name: "mary eat [item name]",
-						Value: "You eat a chocolate. Who knows, maybe you'll get lucky?",
-					},{
-						Name: "mary runover @user",
-						Value: "Run over the mentioned user. Does not use up car item.",
-					},{
-						Name: "mary shoot @user",
-						Value: "Shoot the mentioned user with the gun. If user has no gun, it uses the bow. Consumes one gun/bow item.",
-					},{
-						Name: "mary kill @user",
-						Value: "Shoot the mentioned user with the gun. Consumes one gun item.",
-					},{
-						Name: "mary marry @user",
-						Value: "Give the mentioned user a ring. If they give you one back, congratulations! You're married!",
-					},{
-						Name: "mary divorce @user",
-						Value: "Divorce the mentioned user. You must be married to them or have proposed to them. Gives you back one ring.",
-					},
-				},
-				Footer: &discordgo.MessageEmbedFooter{
-					Text: "Page 3/3",
-				},
+				if !replies.IsLanguage(command[3]) {
+					session.ChannelMessageSend(message.ChannelID, reply.Text("language.unknown", replies.LanguageNames()))
+					break
 				}
-				session.ChannelMessageSendEmbed(message.ChannelID, embed)
+				err = database.SetLanguage(MONGO_URI, guildID, 0, strings.ToLower(command[3]))
+				if err != nil {
+					session.ChannelMessageSend(message.ChannelID, reply.Error(userID, err))
+					break
+				}
+				// Only reply in the new language if the user hasn't picked their own
+				language, _ := database.Language(MONGO_URI, guildID, userID)
+				session.ChannelMessageSend(message.ChannelID, replies.For(language).Text("language.server_set", replies.LanguageName(command[3])))
+				break
 			}
-		}
+			if strings.ToLower(command[2]) == "default" {
+				err := database.SetLanguage(MONGO_URI, guildID, userID, "")
+				if err != nil {
+					session.ChannelMessageSend(message.ChannelID, reply.Error(userID, err))
+					break
+				}
+				language, _ := database.Language(MONGO_URI, guildID, userID)
+				session.ChannelMessageSend(message.ChannelID, replies.For(language).Text("language.cleared"))
+				break
+			}
+			if !replies.IsLanguage(command[2]) {
+				session.ChannelMessageSend(message.ChannelID, reply.Text("language.unknown", replies.LanguageNames()))
+				break
+			}
+			err := database.SetLanguage(MONGO_URI, guildID, userID, strings.ToLower(command[2]))
+			if err != nil {
+				session.ChannelMessageSend(message.ChannelID, reply.Error(userID, err))
+				break
+			}
+			session.ChannelMessageSend(message.ChannelID, replies.For(command[2]).Text("language.set", replies.LanguageName(command[2])))
 		
+		// mary config show -> shows the server's economy settings
+		// mary config set [setting] [value] -> changes one, "default" goes back to the bot's setting
+		case strings.ToLower(command[1]) == "config":
+			if len(command) == 2 || strings.ToLower(command[2]) == "show" {
+				session.ChannelMessageSendEmbed(message.ChannelID, reply.Settings(settings))
+				break
+			}
+			if strings.ToLower(command[2]) != "set" || len(command) < 5 {
+				session.ChannelMessageSend(message.ChannelID, reply.Text("config.usage"))
+				break
+			}
+			permissions, err := session.UserChannelPermissions(message.Author.ID, message.ChannelID)
+			if err != nil || permissions & discordgo.PermissionManageServer == 0 {
+				session.ChannelMessageSend(message.ChannelID, reply.Text("config.no_permission"))
+				break
+			}
+			key := strings.ToLower(command[3])
+			value := strings.Join(command[4:], " ") // Currency names can have spaces
+			changed, err := database.SetGuildSetting(MONGO_URI, guildID, key, value)
+			if err != nil {
+				session.ChannelMessageSend(message.ChannelID, reply.Error(userID, err))
+				break
+			}
+			session.ChannelMessageSend(message.ChannelID, reply.SettingChanged(changed, key, value == "default"))
+
+		// mary export [json/csv] -> sends every user's balance, inventory and marriage as a file
+		case strings.ToLower(command[1]) == "export":
+			permissions, err := session.UserChannelPermissions(message.Author.ID, message.ChannelID)
+			if err != nil || permissions & discordgo.PermissionAdministrator == 0 {
+				session.ChannelMessageSend(message.ChannelID, reply.Text("export.no_permission"))
+				break
+			}
+			format := "json"
+			if len(command) > 2 {
+				format = strings.ToLower(command[2])
+			}
+			if format != "json" && format != "csv" {
+				session.ChannelMessageSend(message.ChannelID, reply.Text("export.usage"))
+				break
+			}
+			snapshot, err := database.Export(MONGO_URI, guildID, guildName)
+			if err != nil {
+				session.ChannelMessageSend(message.ChannelID, reply.Error(userID, err))
+				break
+			}
+			var data []byte
+			if format == "csv" {
+				data, err = snapshot.CSV()
+			} else {
+				data, err = snapshot.JSON()
+			}
+			if err != nil {
+				session.ChannelMessageSend(message.ChannelID, reply.Text("export.error", strings.Title(err.Error())))
+				break
+			}
+			session.ChannelMessageSendComplex(message.ChannelID, &discordgo.MessageSend{
+				Content: reply.Text("export.success", guildName, len(snapshot.Users)),
+				Files: []*discordgo.File{{
+					Name: "economy-" + strconv.Itoa(guildID) + "." + format,
+					Reader: bytes.NewReader(data),
+				}},
+			})
+
+		// mary import [dry-run] -> restores the attached file from mary export
+		case strings.ToLower(command[1]) == "import":
+			permissions, err := session.UserChannelPermissions(message.Author.ID, message.ChannelID)
+			if err != nil || permissions & discordgo.PermissionAdministrator == 0 {
+				session.ChannelMessageSend(message.ChannelID, reply.Text("export.no_permission"))
+				break
+			}
+			if len(message.Attachments) == 0 {
+				session.ChannelMessageSend(message.ChannelID, reply.Text("import.usage"))
+				break
+			}
+			dryRun := len(command) > 2 && strings.ToLower(command[2]) == "dry-run"
+			attachment := message.Attachments[0]
+			if attachment.Size > maxImportSize {
+				session.ChannelMessageSend(message.ChannelID, reply.Text("import.too_big", maxImportSize >> 20))
+				break
+			}
+			resp, err := http.Get(attachment.URL)
+			if err != nil {
+				session.ChannelMessageSend(message.ChannelID, reply.Text("import.download_error", strings.Title(err.Error())))
+				break
+			}
+			if resp.StatusCode != http.StatusOK {
+				resp.Body.Close()
+				session.ChannelMessageSend(message.ChannelID, reply.Text("import.download_error", resp.Status))
+				break
+			}
+			// Read one byte past the limit, so a file that's too big fails instead of being cut off
+			data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxImportSize + 1))
+			resp.Body.Close()
+			if err != nil {
+				session.ChannelMessageSend(message.ChannelID, reply.Text("import.download_error", strings.Title(err.Error())))
+				break
+			}
+			if len(data) > maxImportSize {
+				session.ChannelMessageSend(message.ChannelID, reply.Text("import.too_big", maxImportSize >> 20))
+				break
+			}
+			snapshot, err := database.ParseSnapshot(attachment.Filename, data)
+			if err != nil {
+				session.ChannelMessageSend(message.ChannelID, reply.Import(userID, database.ImportResult{}, err))
+				break
+			}
+			res, err := database.Import(MONGO_URI, guildID, guildName, snapshot, dryRun)
+			session.ChannelMessageSend(message.ChannelID, reply.Import(userID, res, err))
+
 		// mary profile -> shows your profile
-		case strings.ToLower(command[1]) == "profile":
-			// Declare variables so that they can be used outside of the if statement
-			// Because apparently declaring variables inside an if statement limits their scope to the conditional
-			var user string
-			var bal int64
-			var serverName string
-			var timeLeft int
-			var avatarURL string
-			var spouse string
+		// mary profile card [theme] [@user] -> draws the profile as an image
+		case strings.ToLower(command[1]) == "profile" && len(command) > 2 && strings.ToLower(command[2]) == "card":
+			profileUser := message.Author
+			if len(message.Mentions) > 0 {
+				profileUser = message.Mentions[0]
+			}
+			theme := cards.DefaultTheme
+			if len(command) > 3 && !strings.HasPrefix(command[3], "<@") {
+				theme = strings.ToLower(command[3])
+			}
+			if _, ok := cards.Themes[theme]; !ok {
+				session.ChannelMessageSend(message.ChannelID, reply.Text("card.no_theme", cards.ThemeNames()))
+				break
+			}
+
+			profileUserID, _ := strconv.Atoi(profileUser.ID)
+			res, err := database.GetProfileCard(MONGO_URI, guildID, guildName, profileUserID, profileUser.Username)
+			if err != nil {
+				session.ChannelMessageSend(message.ChannelID, reply.Error(userID, err))
+				break
+			}
+			image, err2 := cards.RenderProfile(res, profileUser.AvatarURL("256"), theme)
+			if err2 != nil {
+				session.ChannelMessageSend(message.ChannelID, reply.Text("card.draw_error", strings.Title(err2.Error())))
+				break
+			}
+			session.ChannelFileSend(message.ChannelID, "profile.png", image)
 
+		// mary profile global -> shows the user's stats added up across every server, if they opted in
+		case strings.ToLower(command[1]) == "profile" && len(command) > 2 && strings.ToLower(command[2]) == "global":
+			profileUser := message.Author
+			if len(message.Mentions) > 0 {
+				profileUser = message.Mentions[0]
+			}
+			profileUserID, _ := strconv.Atoi(profileUser.ID)
+			res, err := database.GetGlobalProfile(MONGO_URI, botGuildIDs(session), profileUserID)
+			if errors.Is(err, database.ErrNotGlobal) {
+				session.ChannelMessageSend(message.ChannelID, reply.Text("global.not_on_leaderboard"))
+				break
+			} else if err != nil {
+				session.ChannelMessageSend(message.ChannelID, reply.Error(userID, err))
+				break
+			}
+
+			session.ChannelMessageSendEmbed(message.ChannelID, reply.GlobalProfile(res, profileUser.AvatarURL("")))
+
+		// mary profile [@user] -> shows the user's profile and cooldowns
+		case strings.ToLower(command[1]) == "profile":
 			// If user mentions another user, get their profile
+			profileUser := message.Author
 			if len(message.Mentions) > 0 {
-				// Get mentioned user's ID and username
-				mentionedUser := message.Mentions[0]
-				mentionedUserID, _:= strconv.Atoi(mentionedUser.ID)
-				mentionedUserName := mentionedUser.Username
-
-				// Get mentioned user's profile
-				user, bal, serverName, timeLeft, spouse = database.GetProfile(MONGO_URI, guildID, guildName, mentionedUserID, mentionedUserName)
-
-				// If the user variable returns the string "That person is not currently playing the game!"
-				// Then return an error message
-				if user == "That person is not currently playing the game!" {
-					session.ChannelMessageSend(message.ChannelID, "That person is not currently playing the game!")
-					time.Sleep(1 * time.Second)
-					session.ChannelMessageSend(message.ChannelID, "I will add that user to the database now...")
-					return
-				}
+				profileUser = message.Mentions[0]
+			}
+			profileUserID, _ := strconv.Atoi(profileUser.ID)
+			profile, err := database.GetProfile(MONGO_URI, guildID, guildName, profileUserID, profileUser.Username)
+			if err != nil {
+				session.ChannelMessageSend(message.ChannelID, reply.Error(userID, err))
+				break
+			}
 
-				// Get mentioned user's profile picture URL
-				avatarURL = mentionedUser.AvatarURL("")		
-			} else {
-				// Otherwise, get user's profile
-				// Get username 
-				userName := message.Author.Username
-				// Get user's profile
-				user, bal, serverName, timeLeft, spouse = database.GetProfile(MONGO_URI, guildID, guildName, userID, userName)
-
-				if user == "That person is not currently playing the game!" {
-					session.ChannelMessageSend(message.ChannelID, "You are not currently playing the game!")
-					time.Sleep(1 * time.Second)
-					session.ChannelMessageSend(message.ChannelID, "I will add you to the database now...")
-					return
-				}
-				
-				// Get user's profile picture URL
-				avatarURL = message.Author.AvatarURL("")
-			}
-			
-			// Extract hours, minutes and seconds from hoursUntilNextDaily
-			hoursLeft := int(timeLeft)
-			minutesLeft := int(hoursLeft % 60)
-			secondsLeft := int(minutesLeft % 60)
-
-			// Create embed
-			embed := &discordgo.MessageEmbed{
-				Title: "Profile",
-				Thumbnail: &discordgo.MessageEmbedThumbnail{
-					URL: avatarURL,
-				},
-				Color: 0xffc0cb,
-				Fields: []*discordgo.MessageEmbedField{
-					{
-						Name: "Username",
-						Value: user,
-						Inline: true,
-					},
-					{
-						Name: "Balance",
-						Value: strconv.FormatInt(bal, 10) + " coins",
-						Inline: true,
-					},
-					{
-						Name: "Server",
-						Value: serverName,
-						Inline: true,
-					},
-					{
-						Name: "Married To",
-						Value: spouse,
-						Inline: true,
-					},
-					{
-						Name: "Next Daily",
-						Value: strconv.Itoa(hoursLeft) + "h " + strconv.Itoa(minutesLeft) + "m " + strconv.Itoa(secondsLeft) + "s",
-						Inline: true,
-					},
-				},
-			}
-			// Send embed
+			session.ChannelMessageSendEmbed(message.ChannelID, reply.Profile(profile, profileUser.AvatarURL("")))
+
+		// mary rank -> shows the user's level and XP
+		case strings.ToLower(command[1]) == "rank" || strings.ToLower(command[1]) == "level":
+			res, err := database.Rank(MONGO_URI, guildID, guildName, userID, userName)
+			if err != nil {
+				session.ChannelMessageSend(message.ChannelID, reply.Error(userID, err))
+				break
+			}
+			embed := reply.Rank(userName, res)
+			embed.Thumbnail = &discordgo.MessageEmbedThumbnail{
+				URL: message.Author.AvatarURL(""),
+			}
 			session.ChannelMessageSendEmbed(message.ChannelID, embed)
 
+		// mary levelrole [level] @role -> gives users a role when they reach a level (needs Manage Roles)
+		// mary levelrole remove [level] -> removes the reward for a level
+		// mary levelrole -> lists the rewards
+		case strings.ToLower(command[1]) == "levelrole" || strings.ToLower(command[1]) == "levelroles":
+			if len(command) == 2 {
+				roles, err := database.LevelRoles(MONGO_URI, guildID, 0, "", false)
+				session.ChannelMessageSend(message.ChannelID, reply.LevelRoles(userID, 0, "", false, roles, err))
+				break
+			}
+			permissions, err := session.UserChannelPermissions(message.Author.ID, message.ChannelID)
+			if err != nil || permissions & discordgo.PermissionManageRoles == 0 {
+				session.ChannelMessageSend(message.ChannelID, reply.Text("levelrole.no_permission"))
+				break
+			}
+			if strings.ToLower(command[2]) == "remove" {
+				if len(command) < 4 || !valid.IsInt(command[3]) {
+					session.ChannelMessageSend(message.ChannelID, reply.Text("levelrole.remove_usage"))
+					break
+				}
+				level, _ := strconv.Atoi(command[3])
+				roles, err := database.LevelRoles(MONGO_URI, guildID, level, "", true)
+				session.ChannelMessageSend(message.ChannelID, reply.LevelRoles(userID, level, "", true, roles, err))
+				break
+			}
+			if !valid.IsInt(command[2]) || len(message.MentionRoles) == 0 {
+				session.ChannelMessageSend(message.ChannelID, reply.Text("levelrole.usage"))
+				break
+			}
+			level, _ := strconv.Atoi(command[2])
+			roles, err := database.LevelRoles(MONGO_URI, guildID, level, message.MentionRoles[0], false)
+			session.ChannelMessageSend(message.ChannelID, reply.LevelRoles(userID, level, message.MentionRoles[0], false, roles, err))
+
+		// mary achievements -> shows which achievements the user has unlocked
+		case strings.ToLower(command[1]) == "achievements" || strings.ToLower(command[1]) == "badges":
+			res, err := database.Achievements(MONGO_URI, guildID, guildName, userID, userName)
+			if err != nil {
+				session.ChannelMessageSend(message.ChannelID, reply.Error(userID, err))
+				break
+			}
+			session.ChannelMessageSendEmbed(message.ChannelID, reply.Achievements(userName, res))
+
 		// mary del (admin only) -> deletes a set number of messages
 		case strings.ToLower(command[1]) == "del" && len(command) == 3:
 			// Check if third argument is an integer
 			_, err := strconv.Atoi(command[2])
 			if err != nil {
-				session.ChannelMessageSend(message.ChannelID, "Please enter a valid number!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("invalid_number"))
 			} else {
 				// Amount to delete
 				amount, err := strconv.Atoi(command[2])
 				if err != nil {
-					session.ChannelMessageSend(message.ChannelID, "Error occurred while converting amount!" + strings.Title(err.Error()))
+					session.ChannelMessageSend(message.ChannelID, reply.Text("error.converting_amount", strings.Title(err.Error())))
 				}
 				
 				// Get user ID
 				userID, err := strconv.Atoi(message.Author.ID)
 				if err != nil {
-					session.ChannelMessageSend(message.ChannelID, "Error occurred while getting user ID!" + strings.Title(err.Error()))
+					session.ChannelMessageSend(message.ChannelID, reply.Text("error.user_id", strings.Title(err.Error())))
 				}
 				
 				res := commands.DeleteMessages(session, message, userID, amount)
@@ -455,24 +531,24 @@ func createMessage(session *discordgo.Session, message *discordgo.MessageCreate)
 				pingedUserID := strings.Trim(command[2], "<@!>")
 				pingedUser, err := strconv.Atoi(pingedUserID)
 				if err != nil {
-					fmt.Printf("Error converting pinged user ID! %s\n", err)
+					logger.Warn("Error converting pinged user ID!", "target", pingedUserID, "err", err)
 				}
 				res := commands.Bankrupt(MONGO_URI, guildID, userID, pingedUser)
 				session.ChannelMessageSend(message.ChannelID, res)
 			} else {
-				session.ChannelMessageSend(message.ChannelID, "Please mention a user! Are you trying to bankrupt yourself?")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("bankrupt.no_user"))
 			}
 		
 		// mary quote -> shows a random quote
 		case strings.ToLower(command[1]) == "quote":
 			quote, err := http.Get("https://api.quotable.io/random")
 			if err != nil {
-				session.ChannelMessageSend(message.ChannelID, "Error retrieving quote!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("quote.error"))
 			} else {
 				defer quote.Body.Close()
 				quoteData, err := ioutil.ReadAll(quote.Body)
 				if err != nil {
-					session.ChannelMessageSend(message.ChannelID, "Error retrieving quote!")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("quote.error"))
 				} else {
 					var quoteJSON map[string]interface{}
 					json.Unmarshal(quoteData, &quoteJSON)
@@ -480,12 +556,17 @@ func createMessage(session *discordgo.Session, message *discordgo.MessageCreate)
 				}
 			}
 		
+		// mary start -> joins the economy with a welcome, though any other command joins too
+		case strings.ToLower(command[1]) == "start":
+			res, err := database.Economy(MONGO_URI, guildID, guildName, userID, userName, "start", 0)
+			session.ChannelMessageSend(message.ChannelID, reply.Economy(userID, res, err))
+
 		// mary bal -> checks balance of message author
 		case strings.ToLower(command[1]) == "bal":
 			// Return balance of user
 			if len(command) == 2 {
-				res := database.Economy(MONGO_URI, guildID, guildName, userID, userName, "bal", 0)
-				session.ChannelMessageSend(message.ChannelID, res)
+				res, err := database.Economy(MONGO_URI, guildID, guildName, userID, userName, "bal", 0)
+				session.ChannelMessageSend(message.ChannelID, reply.Economy(userID, res, err))
 			} else if len(command) == 3 {
 				// Return balance of mentioned user
 				if strings.HasPrefix(command[2], "<@") && strings.HasSuffix(command[2], ">") {
@@ -494,34 +575,36 @@ func createMessage(session *discordgo.Session, message *discordgo.MessageCreate)
 					mentionedUser = strings.TrimPrefix(mentionedUser, "!")
 					mentionedUserID, err := strconv.Atoi(mentionedUser)
 					if err != nil {
-						session.ChannelMessageSend(message.ChannelID, "Error retrieving balance!")
+						session.ChannelMessageSend(message.ChannelID, reply.Text("bal.error"))
 					} else {
-						res := database.Economy(MONGO_URI, guildID, guildName, mentionedUserID, "", "bal", 0)
-						session.ChannelMessageSend(message.ChannelID, res)
+						res, err := database.Economy(MONGO_URI, guildID, guildName, mentionedUserID, "", "bal", 0)
+						session.ChannelMessageSend(message.ChannelID, reply.Economy(mentionedUserID, res, err))
 					}
 				} else {
-					session.ChannelMessageSend(message.ChannelID, "Error retrieving balance!")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("bal.error"))
 				}
 			} else {
-				session.ChannelMessageSend(message.ChannelID, "Error retrieving balance!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("bal.error"))
 			}
 		
 		// mary inventory -> shows user's inventory
 		case strings.ToLower(command[1]) == "inventory" || strings.ToLower(command[1]) == "inv": {
-			err, res := database.Inventory(MONGO_URI, guildID, guildName, userID, userName)
-			if err != "" {
-				session.ChannelMessageSend(message.ChannelID, err)
+			res, err := database.Inventory(MONGO_URI, guildID, guildName, userID, userName)
+			if err != nil {
+				session.ChannelMessageSend(message.ChannelID, reply.Error(userID, err))
 				break
 			}
-			session.ChannelMessageSendEmbed(message.ChannelID, res)
+			embed := reply.Inventory(userName, res)
+			pages := commands.PaginateFields(*embed, embed.Fields, 12)
+			commands.SendPaginator(session, message.ChannelID, message.Author.ID, pages, 0)
 		}
 
 		case strings.ToLower(command[1]) == "give":
 			if len(command) < 3 {
-				session.ChannelMessageSend(message.ChannelID, "Please specify a user to give the item to!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("give.no_user"))
 				break
 			} else if len(command) < 4 {
-				session.ChannelMessageSend(message.ChannelID, "Please specify an item to give!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("give.no_item"))
 				break
 			} 
 			words := strings.Fields(message.Content)
@@ -533,14 +616,14 @@ func createMessage(session *discordgo.Session, message *discordgo.MessageCreate)
 				pingedUser := strings.Trim(command[2], "<@!>")
 				pingedUserID, err := strconv.Atoi(pingedUser)
 				if err != nil {
-					session.ChannelMessageSend(message.ChannelID, "Error occurred while getting user ID!" + strings.Title(err.Error()))
+					session.ChannelMessageSend(message.ChannelID, reply.Text("error.user_id", strings.Title(err.Error())))
 				}
 				if pingedUserID == userID {
-					session.ChannelMessageSend(message.ChannelID, "You can't give yourself an item!")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("give.self"))
 					break
 				}
-				res := database.Give(MONGO_URI, guildID, guildName, userID, userName, item, amount, pingedUserID)
-				session.ChannelMessageSend(message.ChannelID, res)
+				res, err := database.Give(MONGO_URI, guildID, guildName, userID, userName, item, amount, pingedUserID)
+				session.ChannelMessageSend(message.ChannelID, reply.Give(userID, res, err))
 			} else {
 				// Assume amount to give is 1
 				amount := 1
@@ -548,43 +631,45 @@ func createMessage(session *discordgo.Session, message *discordgo.MessageCreate)
 				pingedUser := strings.Trim(command[2], "<@!>")
 				pingedUserID, err := strconv.Atoi(pingedUser)
 				if err != nil {
-					session.ChannelMessageSend(message.ChannelID, "Error occurred while getting user ID!" + strings.Title(err.Error()))
+					session.ChannelMessageSend(message.ChannelID, reply.Text("error.user_id", strings.Title(err.Error())))
 				}
 				if pingedUserID == userID {
-					session.ChannelMessageSend(message.ChannelID, "You can't give yourself an item!")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("give.self"))
 					break
 				}
-				res := database.Give(MONGO_URI, guildID, guildName, userID, userName, item, amount, pingedUserID)
-				session.ChannelMessageSend(message.ChannelID, res)
+				res, err := database.Give(MONGO_URI, guildID, guildName, userID, userName, item, amount, pingedUserID)
+				session.ChannelMessageSend(message.ChannelID, reply.Give(userID, res, err))
 			}
 
 		// mary shop -> shows shop
 		case strings.ToLower(command[1]) == "shop":
-			pageSize := 3
+			pageSize := config.Current.ShopPageSize
 
 			// If the user does not declare a page number, default to page 1 (0)
-			if len(command) == 2 {
-				database.Shop(session, message, pageSize, 0)
-			} else if len(command) == 3 {
+			page := 1
+			if len(command) == 3 {
 				// Check if third argument is an integer
-				_, err := strconv.Atoi(command[2])
+				num, err := strconv.Atoi(command[2])
 				if err != nil {
-					session.ChannelMessageSend(message.ChannelID, "Please enter a valid number!")
-				} else {
-					// Get page number
-					page, err := strconv.Atoi(command[2])
-					if err != nil {
-						session.ChannelMessageSend(message.ChannelID, "Error occurred while converting page number!" + strings.Title(err.Error()))
-					}
-					database.Shop(session, message, pageSize, page-1)
+					session.ChannelMessageSend(message.ChannelID, reply.Text("invalid_number"))
+					break
 				}
+				page = num
 			}
+			res, err := database.Shop(MONGO_URI, guildID)
+			if err != nil {
+				session.ChannelMessageSend(message.ChannelID, reply.Error(userID, err))
+				break
+			}
+			embed := reply.Shop(res)
+			pages := commands.PaginateFields(*embed, embed.Fields, pageSize)
+			commands.SendPaginator(session, message.ChannelID, message.Author.ID, pages, page-1)
 		
 		// mary buy -> buys an item from the shop
 		case strings.ToLower(command[1]) == "buy":
 			// Check if user specified an item
 			if len(command) == 2 {
-				session.ChannelMessageSend(message.ChannelID, "Please specify an item to buy!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("buy.no_item"))
 				break
 			}
 			words := strings.Fields(message.Content)
@@ -594,22 +679,22 @@ func createMessage(session *discordgo.Session, message *discordgo.MessageCreate)
 				// Get item name
 				item := strings.Join(command[2:len(command)-1], " ") // 0 is mary, 1 is buy
 				if err != nil {
-					session.ChannelMessageSend(message.ChannelID, "Error occurred while converting item number!" + strings.Title(err.Error()))
+					session.ChannelMessageSend(message.ChannelID, reply.Text("error.item_number", strings.Title(err.Error())))
 				}
-				res := database.Buy(MONGO_URI, guildID, guildName, userID, userName, strings.ToLower(item), num)
-				session.ChannelMessageSend(message.ChannelID, res)
+				res, err := database.Buy(MONGO_URI, guildID, guildName, userID, userName, strings.ToLower(item), num)
+				session.ChannelMessageSend(message.ChannelID, reply.Buy(userID, res, err))
 			} else {
 				// Get item name -> assume user wants to buy 1 of the item and the rest of the command is the item name
 				item := strings.Join(command[2:], " ")
-				res := database.Buy(MONGO_URI, guildID, guildName, userID, userName, strings.ToLower(item), 1)
-				session.ChannelMessageSend(message.ChannelID, res)
+				res, err := database.Buy(MONGO_URI, guildID, guildName, userID, userName, strings.ToLower(item), 1)
+				session.ChannelMessageSend(message.ChannelID, reply.Buy(userID, res, err))
 			}
 
 		// mary sell -> sells an item from the user's inventory
 		case strings.ToLower(command[1]) == "sell":
 			// Check if user specified an item
 			if len(command) == 2 {
-				session.ChannelMessageSend(message.ChannelID, "Please specify an item to sell!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("sell.no_item"))
 				break
 			}
 			words := strings.Fields(message.Content)
@@ -619,108 +704,308 @@ func createMessage(session *discordgo.Session, message *discordgo.MessageCreate)
 				// Get item name
 				item := strings.Join(command[2:len(command)-1], " ") // 0 is mary, 1 is sell
 				if err != nil {
-					session.ChannelMessageSend(message.ChannelID, "Error occurred while converting item number!" + strings.Title(err.Error()))
+					session.ChannelMessageSend(message.ChannelID, reply.Text("error.item_number", strings.Title(err.Error())))
 				}
-				res := database.Sell(MONGO_URI, guildID, guildName, userID, userName, strings.ToLower(item), num)
-				session.ChannelMessageSend(message.ChannelID, res)
+				res, err := database.Sell(MONGO_URI, guildID, guildName, userID, userName, strings.ToLower(item), num)
+				session.ChannelMessageSend(message.ChannelID, reply.Sell(userID, res, err))
 			} else {
 				// Get item name -> assume user wants to sell 1 of the item and the rest of the command is the item name
 				item := strings.Join(command[2:], " ")
-				res := database.Sell(MONGO_URI, guildID, guildName, userID, userName, strings.ToLower(item), 1)
+				res, err := database.Sell(MONGO_URI, guildID, guildName, userID, userName, strings.ToLower(item), 1)
+				session.ChannelMessageSend(message.ChannelID, reply.Sell(userID, res, err))
+			}
+
+		// mary repair -> repairs a worn down or broken item for coins
+		case strings.ToLower(command[1]) == "repair":
+			if len(command) == 2 {
+				session.ChannelMessageSend(message.ChannelID, reply.Text("repair.no_item"))
+				break
+			}
+			item := strings.Join(command[2:], " ")
+			cost, err := database.Repair(MONGO_URI, guildID, guildName, userID, userName, strings.ToLower(item))
+			session.ChannelMessageSend(message.ChannelID, reply.Repair(userID, strings.ToLower(item), cost, err))
+
+		// mary recipes -> shows everything that can be crafted
+		case strings.ToLower(command[1]) == "recipes":
+			session.ChannelMessageSendEmbed(message.ChannelID, reply.Recipes(database.Recipes()))
+
+		// mary craft -> crafts an item from the user's inventory
+		case strings.ToLower(command[1]) == "craft":
+			// Check if user specified an item
+			if len(command) == 2 {
+				session.ChannelMessageSend(message.ChannelID, reply.Text("craft.no_item"))
+				break
+			}
+			words := strings.Fields(message.Content)
+			lastWord := words[len(words)-1] // Check if amount specified is an integer (should be last argument)
+			if num, err := strconv.Atoi(lastWord); err == nil {
+				// If the last word is an integer, assume the user wants to craft that many of the item
+				item := strings.Join(command[2:len(command)-1], " ") // 0 is mary, 1 is craft
+				res, err := database.Craft(MONGO_URI, guildID, guildName, userID, userName, strings.ToLower(item), num)
+				session.ChannelMessageSend(message.ChannelID, reply.Craft(userID, res, err))
+			} else {
+				// Get item name -> assume user wants to craft 1 of the item and the rest of the command is the item name
+				item := strings.Join(command[2:], " ")
+				res, err := database.Craft(MONGO_URI, guildID, guildName, userID, userName, strings.ToLower(item), 1)
+				session.ChannelMessageSend(message.ChannelID, reply.Craft(userID, res, err))
+			}
+
+		// mary market -> player-to-player marketplace and auction house
+		case strings.ToLower(command[1]) == "market":
+			// mary market [optional: page number] -> browse listings
+			if len(command) == 2 || valid.IsInt(command[2]) {
+				page := 1
+				if len(command) == 3 {
+					page, _ = strconv.Atoi(command[2])
+				}
+				res, err := database.MarketBrowse(MONGO_URI, guildID, page-1)
+				if err != nil {
+					session.ChannelMessageSend(message.ChannelID, reply.Error(userID, err))
+					break
+				}
+				session.ChannelMessageSendEmbed(message.ChannelID, reply.MarketPage(res))
+				break
+			}
+
+			words := strings.Fields(message.Content)
+			switch strings.ToLower(command[2]) {
+			case "list": { // mary market list [item name] [quantity] [price]
+				if len(words) < 6 || !valid.IsInt(words[len(words)-2]) || !valid.IsInt(words[len(words)-1]) {
+					session.ChannelMessageSend(message.ChannelID, reply.Text("market.list.usage"))
+					break
+				}
+				item := strings.Join(words[3:len(words)-2], " ")
+				quantity, _ := strconv.Atoi(words[len(words)-2])
+				price, _ := strconv.Atoi(words[len(words)-1])
+				res, err := database.MarketList(MONGO_URI, guildID, guildName, userID, userName, strings.ToLower(item), quantity, price, false, 0)
+				session.ChannelMessageSend(message.ChannelID, reply.MarketList(userID, quantity, res, err))
+			}
+			case "auction": { // mary market auction [item name] [quantity] [starting price] [minutes]
+				if len(words) < 7 || !valid.IsInt(words[len(words)-3]) || !valid.IsInt(words[len(words)-2]) || !valid.IsInt(words[len(words)-1]) {
+					session.ChannelMessageSend(message.ChannelID, reply.Text("market.auction.usage"))
+					break
+				}
+				item := strings.Join(words[3:len(words)-3], " ")
+				quantity, _ := strconv.Atoi(words[len(words)-3])
+				price, _ := strconv.Atoi(words[len(words)-2])
+				minutes, _ := strconv.Atoi(words[len(words)-1])
+				res, err := database.MarketList(MONGO_URI, guildID, guildName, userID, userName, strings.ToLower(item), quantity, price, true, time.Duration(minutes) * time.Minute)
+				session.ChannelMessageSend(message.ChannelID, reply.MarketList(userID, quantity, res, err))
+			}
+			case "buy": { // mary market buy [listing]
+				if len(words) < 4 || !valid.IsInt(strings.TrimPrefix(words[3], "#")) {
+					session.ChannelMessageSend(message.ChannelID, reply.Text("market.buy.usage"))
+					break
+				}
+				listingID, _ := strconv.Atoi(strings.TrimPrefix(words[3], "#"))
+				res, err := database.MarketBuy(MONGO_URI, guildID, guildName, userID, userName, listingID)
+				session.ChannelMessageSend(message.ChannelID, reply.MarketBuy(userID, res, err))
+			}
+			case "bid": { // mary market bid [listing] [amount]
+				if len(words) < 5 || !valid.IsInt(strings.TrimPrefix(words[3], "#")) || !valid.IsInt(words[4]) {
+					session.ChannelMessageSend(message.ChannelID, reply.Text("market.bid.usage"))
+					break
+				}
+				listingID, _ := strconv.Atoi(strings.TrimPrefix(words[3], "#"))
+				amount, _ := strconv.Atoi(words[4])
+				res, err := database.MarketBid(MONGO_URI, guildID, guildName, userID, userName, listingID, amount)
+				session.ChannelMessageSend(message.ChannelID, reply.MarketBid(userID, res, err))
+			}
+			case "cancel": { // mary market cancel [listing]
+				if len(words) < 4 || !valid.IsInt(strings.TrimPrefix(words[3], "#")) {
+					session.ChannelMessageSend(message.ChannelID, reply.Text("market.cancel.usage"))
+					break
+				}
+				listingID, _ := strconv.Atoi(strings.TrimPrefix(words[3], "#"))
+				res, err := database.MarketCancel(MONGO_URI, guildID, guildName, userID, userName, listingID)
+				session.ChannelMessageSend(message.ChannelID, reply.MarketCancel(userID, res, err))
+			}
+			default: {
+				session.ChannelMessageSend(message.ChannelID, reply.Text("market.unknown_command"))
+			}
+			}
+
+		// mary trade -> swap items and coins with another user
+		case strings.ToLower(command[1]) == "trade":
+			words := strings.Fields(message.Content)
+			operation := "show"
+			pingedUserID := 0
+			item := ""
+			amount := 1
+			if len(words) > 2 {
+				operation = strings.ToLower(words[2])
+			}
+			switch operation {
+			case "show", "confirm", "cancel": // mary trade [confirm/cancel]
+			case "add": { // mary trade add [item name] [optional: amount]
+				if len(words) < 4 {
+					session.ChannelMessageSend(message.ChannelID, reply.Text("trade.add_usage"))
+					return
+				}
+				lastWord := words[len(words)-1] // Check if amount specified is an integer (should be last argument)
+				if num, err := strconv.Atoi(lastWord); err == nil && len(words) > 4 {
+					amount = num
+					item = strings.Join(words[3:len(words)-1], " ")
+				} else {
+					item = strings.Join(words[3:], " ")
+				}
+				item = strings.ToLower(item)
+			}
+			default: { // mary trade @user
+				pingedUser, err := strconv.Atoi(strings.Trim(words[2], "<@!>"))
+				if err != nil {
+					session.ChannelMessageSend(message.ChannelID, reply.Text("trade.no_user"))
+					return
+				}
+				operation = "open"
+				pingedUserID = pingedUser
+			}
+			}
+			trade, err := database.Trade(MONGO_URI, guildID, guildName, userID, userName, operation, pingedUserID, item, amount)
+			res, embed := reply.Trade(userID, trade, err)
+			if res != "" {
 				session.ChannelMessageSend(message.ChannelID, res)
 			}
+			if embed != nil {
+				session.ChannelMessageSendEmbed(message.ChannelID, embed)
+			}
 
 		// mary daily -> gives user 100 coins
 		case strings.ToLower(command[1]) == "daily":
-			res := database.Economy(MONGO_URI, guildID, guildName, userID, userName, "daily", 100)
-			session.ChannelMessageSend(message.ChannelID, res)
+			res, err := database.Economy(MONGO_URI, guildID, guildName, userID, userName, "daily", 0) // The payout comes from the server's settings
+			session.ChannelMessageSend(message.ChannelID, reply.Economy(userID, res, err))
 		
 		// mary beg -> gives user 1-10 coins
 		case strings.ToLower(command[1]) == "beg":
-			res := database.Economy(MONGO_URI, guildID, guildName, userID, userName, "beg", 0)
-			session.ChannelMessageSend(message.ChannelID, res)
+			res, err := database.Economy(MONGO_URI, guildID, guildName, userID, userName, "beg", 0)
+			session.ChannelMessageSend(message.ChannelID, reply.Economy(userID, res, err))
 
-		// mary rob @user -> steals 1-50 coins from user
+		// mary rob @user -> tries to steal from user, with a fine and jail time if caught
 		case strings.ToLower(command[1]) == "rob":
+			if len(command) < 3 {
+				session.ChannelMessageSend(message.ChannelID, reply.Text("rob.no_user"))
+				break
+			}
 			pingedUserID := strings.Trim(command[2], "<@!>")
 			pingedUser, err := strconv.Atoi(pingedUserID)
 			if err != nil {
-				fmt.Printf("Error converting pinged user ID! %s\n", err)
+				logger.Warn("Error converting pinged user ID!", "target", pingedUserID, "err", err)
 			}
-			res := database.UserInteraction(MONGO_URI, guildID, guildName, userID, userName, pingedUser, "rob", 0)
-			session.ChannelMessageSend(message.ChannelID, res)
+			res, err := database.UserInteraction(MONGO_URI, guildID, guildName, userID, userName, pingedUser, "rob", 0)
+			session.ChannelMessageSend(message.ChannelID, reply.Interaction(userID, "rob", res, err))
+
+		// mary bail -> pay your way out of jail after a failed robbery
+		case strings.ToLower(command[1]) == "bail":
+			bail, err := database.Bail(MONGO_URI, guildID, guildName, userID, userName)
+			session.ChannelMessageSend(message.ChannelID, reply.Bail(userID, bail, err))
 
 		// mary pay @user amount -> gives user amount of coins
 		case strings.ToLower(command[1]) == "pay":
 			if len(command) == 3 {
-				session.ChannelMessageSend(message.ChannelID, "Please specify an amount to be paid!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("pay.no_amount"))
 				return
 			} else if len(command) == 4 && valid.IsInt(command[3]) == false { // &^ is bitwise AND NOT
-				session.ChannelMessageSend(message.ChannelID, "Please specify a valid amount to be paid!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("pay.invalid_amount"))
 				return
 			} else if strings.HasPrefix(command[3], "-") {
-				session.ChannelMessageSend(message.ChannelID, "Please specify a positive amount to be paid!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("pay.negative_amount"))
 				return
 			}
 			pingedUserID := strings.Trim(command[2], "<@!>")
 			pingedUser, err := strconv.Atoi(pingedUserID)
 			if err != nil {
-				fmt.Printf("Error converting pinged user ID! %s\n", err)
+				logger.Warn("Error converting pinged user ID!", "target", pingedUserID, "err", err)
 			}
 			amount, err := strconv.Atoi(command[3])
 			if err != nil {
-				fmt.Printf("Error converting amount! %s\n", err)
+				logger.Warn("Error converting amount!", "err", err)
+			}
+			res, err := database.UserInteraction(MONGO_URI, guildID, guildName, userID, userName, pingedUser, "pay", amount)
+			session.ChannelMessageSend(message.ChannelID, reply.Interaction(userID, "pay", res, err))
+
+		// mary global join/leave -> opts in or out of the global leaderboard
+		case strings.ToLower(command[1]) == "global":
+			if len(command) < 3 {
+				session.ChannelMessageSend(message.ChannelID, reply.Text("global.usage"))
+				break
 			}
-			res := database.UserInteraction(MONGO_URI, guildID, guildName, userID, userName, pingedUser, "pay", amount)
-			session.ChannelMessageSend(message.ChannelID, res)
+			switch strings.ToLower(command[2]) {
+			case "join":
+				session.ChannelMessageSend(message.ChannelID, reply.GlobalJoin(userID, database.GlobalJoin(MONGO_URI, userID, userName)))
+			case "leave":
+				session.ChannelMessageSend(message.ChannelID, reply.GlobalLeave(userID, database.GlobalLeave(MONGO_URI, userID)))
+			default:
+				session.ChannelMessageSend(message.ChannelID, reply.Text("global.usage"))
+			}
+
+		// mary top/leaderboard global [ranking] -> ranks everyone who opted in across every server Mary is in
+		case (strings.ToLower(command[1]) == "leaderboard" || strings.ToLower(command[1]) == "top") && len(command) > 2 && strings.ToLower(command[2]) == "global":
+			rankingName := ""
+			if len(command) > 3 {
+				rankingName = command[3]
+			}
+			pageSize := 8 // Three fields per user, so 8 users fit under Discord's limit of 25 fields
 
-		// mary top/leaderboard -> shows users with the most coins in descending order
+			// Adding up every server is slow, so do it once and flip through the result
+			res, err := database.GlobalLeaderboard(MONGO_URI, botGuildIDs(session), userID, rankingName, 0, math.MaxInt32)
+			if err != nil {
+				session.ChannelMessageSend(message.ChannelID, reply.Error(userID, err))
+				break
+			}
+			if res.Total == 0 {
+				session.ChannelMessageSend(message.ChannelID, reply.Text("global.nobody"))
+				break
+			}
+
+			embed := reply.Leaderboard(res, true)
+			pages := commands.PaginateFields(*embed, embed.Fields, pageSize * 3)
+			commands.SendPaginator(session, message.ChannelID, message.Author.ID, pages, 0)
+
+		// mary top/leaderboard [ranking] -> shows users ranked by wallet, net worth, trivia wins or gambling profit
 		case strings.ToLower(command[1]) == "leaderboard" || strings.ToLower(command[1]) == "top":
-			err, res := database.Leaderboard(MONGO_URI, guildID)
-			if err != "" { // Different error than usual
-				session.ChannelMessageSend(message.ChannelID, err)
+			rankingName := ""
+			if len(command) > 2 {
+				rankingName = command[2]
+			}
+			pageSize := 8 // Three fields per user, so 8 users fit under Discord's limit of 25 fields
+
+			// Get the first page to find out how many pages there are
+			first, err := database.Leaderboard(MONGO_URI, guildID, userID, rankingName, 0, pageSize)
+			if err != nil {
+				session.ChannelMessageSend(message.ChannelID, reply.Error(userID, err))
+				break
+			}
+			if first.Total == 0 {
+				session.ChannelMessageSend(message.ChannelID, reply.Text("leaderboard.nobody"))
+				break
 			}
 				
 			// Get profile picture of the server
-			mary, err3 := discordgo.New("Bot " + os.Getenv("TOKEN"))
-			if err3 != nil {
-				session.ChannelMessageSend(message.ChannelID, "Error retrieving server profile picture!")
-			}
-			guild, err4 := mary.Guild(message.Message.GuildID)
+			guildIconURL := ""
+			guild, err4 := session.Guild(message.Message.GuildID)
 			if err4 != nil {
-				session.ChannelMessageSend(message.ChannelID, "Error retrieving server profile picture!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("leaderboard.icon_error"))
+			} else {
+				guildIconURL = guild.IconURL()
 			}
-			guildIconURL := guild.IconURL()
 
-			// Create rich embed 
-			embed := &discordgo.MessageEmbed{
-				Title: "Leaderboard",
-				Color: 0xffc0cb,
-				Thumbnail: &discordgo.MessageEmbedThumbnail{
+			pageCount := (first.Total + pageSize - 1) / pageSize
+			commands.SendPageLoader(session, message.ChannelID, message.Author.ID, pageCount, 0, func(page int) (string, *discordgo.MessageEmbed) {
+				res := first
+				if page != 0 {
+					var err error
+					res, err = database.Leaderboard(MONGO_URI, guildID, userID, rankingName, page, pageSize)
+					if err != nil {
+						return reply.Error(userID, err), nil
+					}
+				}
+
+				embed := reply.Leaderboard(res, false)
+				embed.Thumbnail = &discordgo.MessageEmbedThumbnail{
 					URL: guildIconURL,
-				},
-			}
-
-			// Use the values from res dict to create fields
-			for _, data := range res {
-				fields := []*discordgo.MessageEmbedField{
-					{
-						Name: "Rank",
-						Value: strconv.Itoa(int(data["Rank"].(int))),
-						Inline: true,
-					},{
-						Name: "Name",
-						Value: data["Name"].(string),
-						Inline: true,
-					},{
-						Name: "Balance",
-						Value: strconv.FormatInt(data["Balance"].(int64), 10),
-						Inline: true,
-					},
 				}
-				embed.Fields = append(embed.Fields, fields...)
-			}
-			session.ChannelMessageSendEmbed(message.ChannelID, embed)
+				return "", embed
+			})
 
 		// mary trivia -> starts a trivia game
 		case strings.ToLower(command[1]) == "trivia" || strings.ToLower(command[1]) == "triv" || strings.ToLower(command[1]) == "quiz":
@@ -728,61 +1013,60 @@ func createMessage(session *discordgo.Session, message *discordgo.MessageCreate)
 			if len(command) == 3 {
 				// Check if user specified a valid amount to gamble
 				if valid.IsInt(command[2]) == false {
-					session.ChannelMessageSend(message.ChannelID, "Please specify a valid amount to gamble!")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("trivia.invalid_amount"))
 					return
 				} else {
 					res, err := strconv.Atoi(command[2])
 					if err != nil {
-						fmt.Printf("Error converting gamble amount! %s\n", err)
+						logger.Warn("Error converting gamble amount!", "err", err)
 					}
 					gambleAmount = res
-					session.ChannelMessageSend(message.ChannelID, "Gambling " + command[2] + " coins. Checking balance...")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("trivia.checking", command[2]))
 					time.Sleep(1 * time.Second)
 				}
 			}
 			// Check if user has enough coins to gamble
 			// The reason we check it here is so that if the user hasn't been added to the database yet, they will be added
-			res1 := database.CheckBalance(session, message, MONGO_URI, guildID, guildName, userID, userName, gambleAmount)
-			if res1 != "" {
-				session.ChannelMessageSend(message.ChannelID, res1)
+			err := database.CheckBalance(session, message, MONGO_URI, guildID, guildName, userID, userName, gambleAmount)
+			if err != nil {
+				session.ChannelMessageSend(message.ChannelID, reply.Economy(userID, database.EconomyResult{}, err))
 				return
 			}
 
-			err, res, correctAnswer, difficulty := database.Trivia(session, message, MONGO_URI, guildID, guildName, userID, userName)
-			if err != "" {
-				session.ChannelMessageSend(message.ChannelID, err)
+			round, err := database.Trivia(session, message, MONGO_URI, guildID, guildName, userID, userName)
+			if err != nil {
+				session.ChannelMessageSend(message.ChannelID, reply.Error(userID, err))
 			} else {
-				session.ChannelMessageSendEmbed(message.ChannelID, res)
+				session.ChannelMessageSendEmbed(message.ChannelID, reply.Trivia(round))
+				correctAnswer, difficulty := round.Answer, round.Difficulty
 
 				// Wait for user to respond
 				msg, err := database.WaitForResponse(session, message.ChannelID, message.Author.ID)
 				if err != nil {
-					session.ChannelMessageSend(message.ChannelID, "Error waiting for response!")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("trivia.wait_error"))
 				}
 				if msg == "You ran out of time!" {
-					session.ChannelMessageSend(message.ChannelID, msg)
+					session.ChannelMessageSend(message.ChannelID, reply.Text("trivia.timeout"))
 					return
 				}
 
 				// Check if user's response is correct
 				if strings.ToLower(msg) == strings.ToLower(correctAnswer) {
-					session.ChannelMessageSend(message.ChannelID, "Correct!")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("trivia.correct"))
 					// Give user coins based on difficulty
 					// If the user gambled coins, pay them differently 
-					res := ""
-					if gambleAmount != 0 {
-						res = database.PayForCorrectAnswer(session, message, difficulty, MONGO_URI, guildID, guildName, userID, userName, gambleAmount)
-					} else {
-						res = database.PayForCorrectAnswer(session, message, difficulty, MONGO_URI, guildID, guildName, userID, userName, 0)
-					}
-					session.ChannelMessageSend(message.ChannelID, res)
+					res, err := database.PayForCorrectAnswer(session, message, difficulty, MONGO_URI, guildID, guildName, userID, userName, gambleAmount)
+					session.ChannelMessageSend(message.ChannelID, reply.Economy(userID, res, err))
 				} else {
-					session.ChannelMessageSend(message.ChannelID, "Incorrect! The correct answer is " + correctAnswer + ".")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("trivia.incorrect", correctAnswer))
 					// If the user gambled coins, take them away
 					if gambleAmount != 0 {
-						res := database.PayForCorrectAnswer(session, message, difficulty, MONGO_URI, guildID, guildName, userID, userName, -gambleAmount)
-						_ = res // Unused variable
-						session.ChannelMessageSend(message.ChannelID, "<@" + strconv.Itoa(userID) + ">, you lose. -" + command[2] + " coins.")
+						_, err := database.PayForCorrectAnswer(session, message, difficulty, MONGO_URI, guildID, guildName, userID, userName, -gambleAmount)
+						if err != nil {
+							session.ChannelMessageSend(message.ChannelID, reply.Error(userID, err))
+							return
+						}
+						session.ChannelMessageSend(message.ChannelID, reply.Text("gamble.lose", "<@" + strconv.Itoa(userID) + ">", gambleAmount))
 				}
 			}
 		}
@@ -790,57 +1074,59 @@ func createMessage(session *discordgo.Session, message *discordgo.MessageCreate)
 		// mary gamble amount -> gamble amount of coins
 		case strings.ToLower(command[1]) == "gamble":
 			if len(command) == 2 {
-				session.ChannelMessageSend(message.ChannelID, "Please specify an amount to be gambled!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("gamble.no_amount"))
 				return
 			} else if len(command) == 3 && valid.IsInt(command[2]) == false { // &^ is bitwise AND NOT
-				session.ChannelMessageSend(message.ChannelID, "Please specify a valid amount to be gambled!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("gamble.invalid_amount"))
 				return
 			} else if strings.HasPrefix(command[2], "-") {
-				session.ChannelMessageSend(message.ChannelID, "Please specify a positive amount to be gambled!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("gamble.negative_amount"))
 				return
 			} else {
-				session.ChannelMessageSend(message.ChannelID, "Gambling " + command[2] + " coins...")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("gamble.gambling", command[2]))
 				time.Sleep(1 * time.Second)
 			}
 			amount, err := strconv.Atoi(command[2])	
 			if err != nil {
-				fmt.Printf("Error converting amount! %s\n", err)
+				logger.Warn("Error converting amount!", "err", err)
 			}
-			res := database.Economy(MONGO_URI, guildID, guildName, userID, userName, "gamble", amount)
-			session.ChannelMessageSend(message.ChannelID, res)
+			res, err := database.Economy(MONGO_URI, guildID, guildName, userID, userName, "gamble", amount)
+			session.ChannelMessageSend(message.ChannelID, reply.Economy(userID, res, err))
 
-		// mary lottery -> enter lottery for 100 coins
+		// mary lottery -> enter lottery for the server's lottery bet (100 coins by default)
 		case strings.ToLower(command[1]) == "lottery":
+			bet := strconv.Itoa(settings.Payouts.LotteryBet)
 			if len(command) > 2 {
-				session.ChannelMessageSend(message.ChannelID, "You can only spend 100 coins on the lottery!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("lottery.limit", settings.Payouts.LotteryBet))
 				time.Sleep(500 * time.Millisecond)
-				session.ChannelMessageSend(message.ChannelID, "Gambling 100 coins...")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("gamble.gambling", bet))
 				time.Sleep(1 * time.Second)
 			} else {
-				session.ChannelMessageSend(message.ChannelID, "Gambling 100 coins...")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("gamble.gambling", bet))
 				time.Sleep(1 * time.Second)
 			}
-			res := database.Economy(MONGO_URI, guildID, guildName, userID, userName, "lottery", 100)
-			session.ChannelMessageSend(message.ChannelID, res)
+			res, err := database.Economy(MONGO_URI, guildID, guildName, userID, userName, "lottery", 0) // The bet comes from the server's settings
+			session.ChannelMessageSend(message.ChannelID, reply.Economy(userID, res, err))
 
-		// mary slots -> play slots for 10 coins
+		// mary slots -> play slots for the server's slots bet (10 coins by default)
 		case strings.ToLower(command[1]) == "slots":
+			bet := strconv.Itoa(settings.Payouts.SlotsBet)
 			if len(command) > 2 {
-				session.ChannelMessageSend(message.ChannelID, "You can only spend 10 coins on slots!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("slots.limit", settings.Payouts.SlotsBet))
 				time.Sleep(500 * time.Millisecond)
-				session.ChannelMessageSend(message.ChannelID, "Gambling 10 coins...")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("gamble.gambling", bet))
 				time.Sleep(1 * time.Second)
 			} else {
-				session.ChannelMessageSend(message.ChannelID, "Gambling 10 coins...")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("gamble.gambling", bet))
 				time.Sleep(1 * time.Second)
 			}
-			res := database.Economy(MONGO_URI, guildID, guildName, userID, userName, "slots", 10)
-			session.ChannelMessageSend(message.ChannelID, res)
+			res, err := database.Economy(MONGO_URI, guildID, guildName, userID, userName, "slots", 0) // The bet comes from the server's settings
+			session.ChannelMessageSend(message.ChannelID, reply.Economy(userID, res, err))
 		
 		// mary use -> uses an item from the user's inventory on a target
 		case strings.ToLower(command[1]) == "use":
 			if len(command) == 2 {
-				session.ChannelMessageSend(message.ChannelID, "Please specify an item to use!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("use.no_item"))
 				break
 			}
 			// Get item specified
@@ -848,109 +1134,109 @@ func createMessage(session *discordgo.Session, message *discordgo.MessageCreate)
 			item := strings.ToLower(words[2])
 			switch item {
 			case "chocolate": { // mary use chocolate 
-				res := database.Use(MONGO_URI, guildID, guildName, userID, userName, "chocolate", 0)
-				session.ChannelMessageSend(message.ChannelID, res)
+				res, err := database.Use(MONGO_URI, guildID, guildName, userID, userName, "chocolate", 0)
+				session.ChannelMessageSend(message.ChannelID, reply.Use(userID, res, err))
 			}
 			case "car": { // mary use car @target
 				// Check if the user has specified a target
 				if len(words) < 4 {
-					session.ChannelMessageSend(message.ChannelID, "Please specify a target!")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("target.missing"))
 					break
 				}
 				pingedUser := strings.Trim(command[len(words)-1], "<@!>") // Get the target
 				if pingedUser == "" {
-					session.ChannelMessageSend(message.ChannelID, "Please specify a valid target!")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("target.invalid"))
 					break
 				}
 				pingedUserID, err := strconv.Atoi(pingedUser)
 				if err != nil {
-					session.ChannelMessageSend(message.ChannelID, "Please specify a valid target!")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("target.invalid"))
 					break
 				}
 				// Make sure the user doesn't use the car on themselves
 				if pingedUserID == userID {
-					session.ChannelMessageSend(message.ChannelID, "You can't run yourself over!")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("use.self.car"))
 					break
 				}
-				res := database.Use(MONGO_URI, guildID, guildName, userID, userName, "car", pingedUserID)
-				session.ChannelMessageSend(message.ChannelID, res)
+				res, err := database.Use(MONGO_URI, guildID, guildName, userID, userName, "car", pingedUserID)
+				session.ChannelMessageSend(message.ChannelID, reply.Use(userID, res, err))
 			}
-			case "gun": { // mary use gun @target
+			case "gun", "crossbow": { // mary use gun/crossbow @target
 				// Check if the user has specified a target
 				if len(words) < 4 {
-					session.ChannelMessageSend(message.ChannelID, "Please specify a target!")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("target.missing"))
 					break
 				}
 				pingedUser := strings.Trim(command[len(words)-1], "<@!>") // Get the target
 				if pingedUser == "" {
-					session.ChannelMessageSend(message.ChannelID, "Please specify a valid target!")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("target.invalid"))
 					break
 				}
 				pingedUserID, err := strconv.Atoi(pingedUser)
 				if err != nil {
-					session.ChannelMessageSend(message.ChannelID, "Please specify a valid target!")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("target.invalid"))
 					break
 				}
 				// Make sure the user doesn't use the gun on themselves
 				if pingedUserID == userID {
-					session.ChannelMessageSend(message.ChannelID, "You can't rob yourself!")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("use.self.rob"))
 					break
 				}
-				res := database.Use(MONGO_URI, guildID, guildName, userID, userName, "gun", pingedUserID)
-				session.ChannelMessageSend(message.ChannelID, res)
+				res, err := database.Use(MONGO_URI, guildID, guildName, userID, userName, item, pingedUserID)
+				session.ChannelMessageSend(message.ChannelID, reply.Use(userID, res, err))
 			} 
 			case "bow": { // mary use bow @target [optional: amount]
 				// Check if the user has specified a target
 				if len(words) < 4 {
-					session.ChannelMessageSend(message.ChannelID, "Please specify a target!")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("target.missing"))
 					break
 				}
 				pingedUser := strings.Trim(command[len(words)-1], "<@!>") // Get the target
 				if pingedUser == "" {
-					session.ChannelMessageSend(message.ChannelID, "Please specify a valid target!")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("target.invalid"))
 					break
 				}
 				pingedUserID, err := strconv.Atoi(pingedUser)
 				if err != nil {
-					session.ChannelMessageSend(message.ChannelID, "Please specify a valid target!")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("target.invalid"))
 					break
 				}
 				// Make sure the user doesn't use the bow on themselves
 				if pingedUserID == userID {
-					session.ChannelMessageSend(message.ChannelID, "You can't rob yourself!")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("use.self.rob"))
 					break
 				}
-				res := database.Use(MONGO_URI, guildID, guildName, userID, userName, "bow", pingedUserID)
-				session.ChannelMessageSend(message.ChannelID, res)
+				res, err := database.Use(MONGO_URI, guildID, guildName, userID, userName, "bow", pingedUserID)
+				session.ChannelMessageSend(message.ChannelID, reply.Use(userID, res, err))
 			}
 			case "ring": { // mary use ring @target
 				// Check if the user has specified a target
 				if len(words) < 4 {
-					session.ChannelMessageSend(message.ChannelID, "Please specify a target!")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("target.missing"))
 					break
 				}
 				pingedUser := strings.Trim(command[len(words)-1], "<@!>") // Get the target
 				if pingedUser == "" {
-					session.ChannelMessageSend(message.ChannelID, "Please specify a valid target!")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("target.invalid"))
 					break
 				}
 				pingedUserID, err := strconv.Atoi(pingedUser)
 				if err != nil {
-					session.ChannelMessageSend(message.ChannelID, "Please specify a valid target!")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("target.invalid"))
 					break
 				}
 				if pingedUserID == userID {
-					session.ChannelMessageSend(message.ChannelID, "You can't marry yourself!")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("use.self.marry"))
 					break
 				}
-				res := database.Use(MONGO_URI, guildID, guildName, userID, userName, "ring", pingedUserID)
-				session.ChannelMessageSend(message.ChannelID, res)
+				res, err := database.Use(MONGO_URI, guildID, guildName, userID, userName, "ring", pingedUserID)
+				session.ChannelMessageSend(message.ChannelID, reply.Use(userID, res, err))
 			}
 		}
 
 		case strings.ToLower(command[1]) == "eat": {
 			if len(command) == 2 {
-				session.ChannelMessageSend(message.ChannelID, "Please specify an item to eat!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("eat.no_item"))
 				break
 			}
 			// Get item specified
@@ -958,11 +1244,11 @@ func createMessage(session *discordgo.Session, message *discordgo.MessageCreate)
 			item := strings.ToLower(words[2])
 			switch item {
 			case "chocolate": { // mary eat chocolate
-				res := database.Use(MONGO_URI, guildID, guildName, userID, userName, "chocolate", 0)
-				session.ChannelMessageSend(message.ChannelID, res)
+				res, err := database.Use(MONGO_URI, guildID, guildName, userID, userName, "chocolate", 0)
+				session.ChannelMessageSend(message.ChannelID, reply.Use(userID, res, err))
 				}
 			default: {
-				session.ChannelMessageSend(message.ChannelID, "You can't eat that!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("eat.cant"))
 				}
 			}
 		}
@@ -971,129 +1257,181 @@ func createMessage(session *discordgo.Session, message *discordgo.MessageCreate)
 			// Check if the user has specified a target
 			pingedUser := strings.Trim(command[len(command)-1], "<@!>") // Get the target
 				if pingedUser == "" {
-					session.ChannelMessageSend(message.ChannelID, "Please specify a valid target!")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("target.invalid"))
 					break
 				}
 				pingedUserID, err := strconv.Atoi(pingedUser)
 				if err != nil {
-					session.ChannelMessageSend(message.ChannelID, "Please specify a valid target!")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("target.invalid"))
 					break
 				}
 				// Make sure the user doesn't run themselves over
 				if pingedUserID == userID {
-					session.ChannelMessageSend(message.ChannelID, "You can't rob yourself!")
+					session.ChannelMessageSend(message.ChannelID, reply.Text("use.self.rob"))
 					break
 				}
-				res := database.Use(MONGO_URI, guildID, guildName, userID, userName, "car", pingedUserID)
-				session.ChannelMessageSend(message.ChannelID, res)
+				res, err := database.Use(MONGO_URI, guildID, guildName, userID, userName, "car", pingedUserID)
+				session.ChannelMessageSend(message.ChannelID, reply.Use(userID, res, err))
 		}
 
 		// Add in the synonyms for the specific use commands here
 		case strings.ToLower(command[1]) == "shoot": {
 			// Check if the user has specified a target
 			if len(command) < 3 {
-				session.ChannelMessageSend(message.ChannelID, "Please specify a target!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("target.missing"))
 				break
 			}
 			pingedUser := strings.Trim(command[2], "<@!>") // Get the target
 			if pingedUser == "" {
-				session.ChannelMessageSend(message.ChannelID, "Please specify a valid target!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("target.invalid"))
 				break
 			}
 			pingedUserID, err := strconv.Atoi(pingedUser)
 			if err != nil {
-				session.ChannelMessageSend(message.ChannelID, "Please specify a valid target!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("target.invalid"))
 				break
 			}
 			// Make sure the user doesn't use the weapon on themselves
 			if pingedUserID == userID {
-				session.ChannelMessageSend(message.ChannelID, "You can't rob yourself!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("use.self.rob"))
 				break
 			}
-			res := database.Use(MONGO_URI, guildID, guildName, userID, userName, "gun", pingedUserID)
-			if res == "You do not have that item in your inventory!" || res == "You do not have enough of that item in your inventory to use!" {
-				res = database.Use(MONGO_URI, guildID, guildName, userID, userName, "bow", pingedUserID)
+			res, err := database.Use(MONGO_URI, guildID, guildName, userID, userName, "gun", pingedUserID)
+			// Fall back to a bow if they don't have a gun they can use
+			var broken database.ErrItemBroken
+			if errors.Is(err, database.ErrItemNotFound) || errors.Is(err, database.ErrNotEnoughItems) || errors.As(err, &broken) {
+				res, err = database.Use(MONGO_URI, guildID, guildName, userID, userName, "bow", pingedUserID)
 			}
-			session.ChannelMessageSend(message.ChannelID, res)
+			session.ChannelMessageSend(message.ChannelID, reply.Use(userID, res, err))
 		}
 
 		case strings.ToLower(command[1]) == "kill": {
 			// Check if the user has specified a target
 			if len(command) < 3 {
-				session.ChannelMessageSend(message.ChannelID, "Please specify a target!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("target.missing"))
 				break
 			}
 			pingedUser := strings.Trim(command[2], "<@!>") // Get the target
 			if pingedUser == "" {
-				session.ChannelMessageSend(message.ChannelID, "Please specify a valid target!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("target.invalid"))
 				break
 			}
 			pingedUserID, err := strconv.Atoi(pingedUser)
 			if err != nil {
-				session.ChannelMessageSend(message.ChannelID, "Please specify a valid target!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("target.invalid"))
 				break
 			}
 			// Make sure the user doesn't use the gun on themselves
 			if pingedUserID == userID {
-				session.ChannelMessageSend(message.ChannelID, "You can't rob yourself!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("use.self.rob"))
 				break
 			}
-			res := database.Use(MONGO_URI, guildID, guildName, userID, userName, "gun", pingedUserID)
-			session.ChannelMessageSend(message.ChannelID, res)
+			res, err := database.Use(MONGO_URI, guildID, guildName, userID, userName, "gun", pingedUserID)
+			session.ChannelMessageSend(message.ChannelID, reply.Use(userID, res, err))
 		}
 
 		case strings.ToLower(command[1]) == "marry": {
 			// Check if the user has specified a target
 			if len(command) < 3 {
-				session.ChannelMessageSend(message.ChannelID, "Please specify a target!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("target.missing"))
 				break
 			}
 			pingedUser := strings.Trim(command[2], "<@!>")
 			if pingedUser == "" {
-				session.ChannelMessageSend(message.ChannelID, "Please specify a valid target!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("target.invalid"))
 				break
 			}
 			pingedUserID, err := strconv.Atoi(pingedUser)
 			if err != nil {
-				session.ChannelMessageSend(message.ChannelID, "Please specify a valid target!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("target.invalid"))
 				break
 			}
 			if pingedUserID == userID {
-				session.ChannelMessageSend(message.ChannelID, "You can't marry yourself!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("use.self.marry"))
 				break
 			}
-			res := database.Use(MONGO_URI, guildID, guildName, userID, userName, "ring", pingedUserID)
-			session.ChannelMessageSend(message.ChannelID, res)
+			res, err := database.Use(MONGO_URI, guildID, guildName, userID, userName, "ring", pingedUserID)
+			session.ChannelMessageSend(message.ChannelID, reply.Use(userID, res, err))
 		}
 
 		case strings.ToLower(command[1]) == "divorce": {
 			// Check if the user has specified a target
 			if len(command) < 3 {
-				session.ChannelMessageSend(message.ChannelID, "Please specify a target!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("target.missing"))
 				break
 			}
 			pingedUser := strings.Trim(command[2], "<@!>")
 			if pingedUser == "" {
-				session.ChannelMessageSend(message.ChannelID, "Please specify a valid target!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("target.invalid"))
 				break
 			}
 			pingedUserID, err := strconv.Atoi(pingedUser)
 			if err != nil {
-				session.ChannelMessageSend(message.ChannelID, "Please specify a valid target!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("target.invalid"))
 				break
 			}
 			if pingedUserID == userID {
-				session.ChannelMessageSend(message.ChannelID, "You can't marry yourself!")
+				session.ChannelMessageSend(message.ChannelID, reply.Text("use.self.marry"))
 				break
 			}
-			res := database.Divorce(MONGO_URI, guildID, guildName, userID, userName, pingedUserID)
-			session.ChannelMessageSend(message.ChannelID, res)
+			res, err := database.Divorce(MONGO_URI, guildID, guildName, userID, userName, pingedUserID)
+			session.ChannelMessageSend(message.ChannelID, reply.Divorce(userID, res, err))
 		}
 
 		// Everything else (will most likely return "I'm sorry, I dont recognize that command.")
 		default:
-			res := database.Economy(MONGO_URI, guildID, guildName, userID, userName, command[1], 0)
-			session.ChannelMessageSend(message.ChannelID, res)
+			res, err := database.Economy(MONGO_URI, guildID, guildName, userID, userName, command[1], 0)
+			session.ChannelMessageSend(message.ChannelID, reply.Economy(userID, res, err))
+		}
+	}
+}
+
+// The largest file `mary import` will read, 8 MB (Discord's upload limit for most servers)
+const maxImportSize = 8 << 20
+
+// Every command Mary knows, for labelling metrics
+// Anything else is counted as "other" so typos can't add new labels
+var knownCommands = map[string]bool{
+	"achievements": true, "badges": true, "bail": true, "bal": true, "bankrupt": true, "beg": true,
+	"buy": true, "config": true, "craft": true, "daily": true, "del": true, "divorce": true, "eat": true,
+	"export": true, "gamble": true, "give": true, "global": true, "help": true, "import": true, "inv": true, "inventory": true,
+	"kill": true, "lang": true, "language": true, "leaderboard": true, "level": true, "levelrole": true,
+	"levelroles": true, "lottery": true, "market": true, "marry": true, "pay": true, "profile": true,
+	"quiz": true, "quote": true, "rank": true, "recipes": true, "repair": true, "rob": true,
+	"run": true, "runover": true, "sell": true, "shoot": true, "shop": true, "slots": true,
+	"start": true, "test": true, "top": true, "trade": true, "triv": true, "trivia": true, "use": true,
+}
+
+// Commands that change the data of the user who runs them, which take their lock first
+// Trivia holds it while waiting for the answer, since the coins gambled on it are still at stake
+var changesUser = map[string]bool{
+	"bail": true, "beg": true, "buy": true, "craft": true, "daily": true, "divorce": true, "eat": true,
+	"gamble": true, "give": true, "kill": true, "lottery": true, "market": true, "marry": true, "pay": true,
+	"quiz": true, "repair": true, "rob": true, "run": true, "runover": true, "sell": true, "shoot": true,
+	"slots": true, "start": true, "trade": true, "triv": true, "trivia": true, "use": true,
+}
+
+// Helper to get the name of a command for logs and metrics, e.g. "bal" for "mary bal @user"
+func commandName(command []string) (string) {
+	if len(command) < 2 {
+		return "other"
+	}
+	name := strings.ToLower(command[1])
+	if !knownCommands[name] {
+		return "other"
+	}
+	return name
+}
+
+// Helper to get the IDs of every server Mary is in, for the global leaderboard
+func botGuildIDs(session *discordgo.Session) ([]int) {
+	guildIDs := []int{}
+	for _, guild := range session.State.Guilds {
+		guildID, err := strconv.Atoi(guild.ID)
+		if err != nil {
+			continue
 		}
+		guildIDs = append(guildIDs, guildID)
 	}
+	return guildIDs
 }
@@ -0,0 +1,129 @@
+// Package metrics counts what Mary is doing and serves it on /metrics in the Prometheus text format
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Define the metrics
+var (
+	CommandsTotal = newCounter("mary_commands_total", "Commands handled, by command.", "command")
+	ErrorsTotal   = newCounter("mary_errors_total", "Errors, by where they happened.", "source")
+	DBLatency     = newSummary("mary_db_command_duration_seconds", "Time spent on MongoDB commands, by command.", "command")
+	CoinsMinted   = newCounter("mary_coins_minted_total", "Coins created, by where they came from.", "source")
+	CoinsBurned   = newCounter("mary_coins_burned_total", "Coins destroyed, by where they went.", "sink")
+)
+
+// Everything that's written out on /metrics, in the order it was defined
+var registry []metric
+
+type metric interface {
+	write(out *strings.Builder)
+}
+
+// A count that only goes up, split by one label
+type Counter struct {
+	name   string
+	help   string
+	label  string
+	lock   sync.Mutex
+	values map[string]float64
+}
+
+func newCounter(name string, help string, label string) (*Counter) {
+	counter := &Counter{name: name, help: help, label: label, values: map[string]float64{}}
+	registry = append(registry, counter)
+	return counter
+}
+
+// Adds one for a label value, e.g. CommandsTotal.Inc("bal")
+func (counter *Counter) Inc(labelValue string) {
+	counter.Add(labelValue, 1)
+}
+
+// Adds an amount for a label value, e.g. CoinsMinted.Add("daily", 100)
+// Negative amounts are ignored, counters only go up
+func (counter *Counter) Add(labelValue string, amount float64) {
+	if amount < 0 {
+		return
+	}
+	counter.lock.Lock()
+	defer counter.lock.Unlock()
+	counter.values[labelValue] += amount
+}
+
+func (counter *Counter) write(out *strings.Builder) {
+	counter.lock.Lock()
+	defer counter.lock.Unlock()
+	fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s counter\n", counter.name, counter.help, counter.name)
+	for _, labelValue := range sortedKeys(counter.values) {
+		fmt.Fprintf(out, "%s{%s=%s} %s\n", counter.name, counter.label, strconv.Quote(labelValue), formatFloat(counter.values[labelValue]))
+	}
+}
+
+// A running count and total of durations, split by one label
+type Summary struct {
+	name   string
+	help   string
+	label  string
+	lock   sync.Mutex
+	sums   map[string]float64
+	counts map[string]float64
+}
+
+func newSummary(name string, help string, label string) (*Summary) {
+	summary := &Summary{name: name, help: help, label: label, sums: map[string]float64{}, counts: map[string]float64{}}
+	registry = append(registry, summary)
+	return summary
+}
+
+// Records how long something took, e.g. DBLatency.Observe("find", 3*time.Millisecond)
+func (summary *Summary) Observe(labelValue string, duration time.Duration) {
+	summary.lock.Lock()
+	defer summary.lock.Unlock()
+	summary.sums[labelValue] += duration.Seconds()
+	summary.counts[labelValue]++
+}
+
+func (summary *Summary) write(out *strings.Builder) {
+	summary.lock.Lock()
+	defer summary.lock.Unlock()
+	fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s summary\n", summary.name, summary.help, summary.name)
+	for _, labelValue := range sortedKeys(summary.counts) {
+		label := summary.label + "=" + strconv.Quote(labelValue)
+		fmt.Fprintf(out, "%s_sum{%s} %s\n", summary.name, label, formatFloat(summary.sums[labelValue]))
+		fmt.Fprintf(out, "%s_count{%s} %s\n", summary.name, label, formatFloat(summary.counts[labelValue]))
+	}
+}
+
+// Helper to write label values in a stable order
+func sortedKeys(values map[string]float64) ([]string) {
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatFloat(value float64) (string) {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// Serves every metric in the Prometheus text format
+func Handler() (http.Handler) {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var out strings.Builder
+		for _, metric := range registry {
+			metric.write(&out)
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		w.Write([]byte(out.String()))
+	})
+}
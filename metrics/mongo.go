@@ -0,0 +1,20 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// Times every MongoDB command, e.g. find or update
+// Set it on a client with options.Client().ApplyURI(mongoURI).SetMonitor(metrics.MongoMonitor)
+var MongoMonitor = &event.CommandMonitor{
+	Succeeded: func(ctx context.Context, finished *event.CommandSucceededEvent) {
+		DBLatency.Observe(finished.CommandName, time.Duration(finished.DurationNanos))
+	},
+	Failed: func(ctx context.Context, failed *event.CommandFailedEvent) {
+		DBLatency.Observe(failed.CommandName, time.Duration(failed.DurationNanos))
+		ErrorsTotal.Inc("mongo")
+	},
+}
@@ -0,0 +1,97 @@
+package replies
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	database "mary-bot/database"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// mary bal/daily/beg/gamble/lottery/slots, and the payout at the end of trivia
+func (printer Printer) Economy(userID int, res database.EconomyResult, err error) (string) {
+	mention := "<@" + strconv.Itoa(userID) + ">"
+	if errors.Is(err, database.ErrInsufficientFunds) { // Only gambling can cost more than the user has
+		return printer.Text("gamble.cant_afford", mention)
+	} else if errors.Is(err, database.ErrNotPositive) {
+		return printer.Text("economy.negative")
+	} else if err != nil {
+		return printer.Error(userID, err)
+	}
+
+	text := ""
+	switch res.Operation {
+	case "bal":
+		text = printer.Text("economy.bal", mention, res.Balance)
+	case "daily":
+		text = printer.Text("economy.daily", mention, res.Amount, res.Streak)
+	case "beg":
+		text = printer.Text("economy.beg", mention, res.Amount)
+	case "gamble", "lottery", "slots":
+		if res.Won {
+			text = printer.Text("gamble.win", mention, res.Amount)
+		} else {
+			text = printer.Text("gamble.lose", mention, res.Bet)
+		}
+	case "trivia":
+		text = printer.Text("trivia.paid", mention, res.Amount)
+	case "start":
+		if res.Created {
+			text = printer.Text("economy.welcome", mention, res.Balance)
+		} else {
+			text = printer.Text("economy.already_started", mention, res.Balance)
+		}
+	}
+	return text + printer.Announcements(res.Announcements)
+}
+
+// mary trivia -> the question, with its category, difficulty and lettered choices
+func (printer Printer) Trivia(round database.TriviaRound) (*discordgo.MessageEmbed) {
+	return &discordgo.MessageEmbed{
+		Title:       round.Question,
+		Description: printer.Text("trivia.details", round.Category, round.Difficulty),
+		Color:       0xffc0cb,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: printer.Text("trivia.choices"), Value: strings.Join(round.Choices, "\n"), Inline: false},
+		},
+	}
+}
+
+// mary bail
+func (printer Printer) Bail(userID int, bail int64, err error) (string) {
+	mention := "<@" + strconv.Itoa(userID) + ">"
+	var cantAfford database.ErrCantAfford
+	if errors.Is(err, database.ErrNotJailed) {
+		return printer.Text("bail.not_jailed", mention)
+	} else if errors.As(err, &cantAfford) {
+		return printer.Text("bail.cant_afford", mention, cantAfford.Cost, cantAfford.Balance)
+	} else if errors.Is(err, database.ErrInsufficientFunds) { // Spent their coins while paying
+		return printer.Text("bail.no_longer_afford", mention, bail)
+	} else if err != nil {
+		return printer.Error(userID, err)
+	}
+	return printer.Text("bail.paid", mention, bail)
+}
+
+// mary rob @user / mary pay @user amount
+func (printer Printer) Interaction(userID int, operation string, res database.InteractionResult, err error) (string) {
+	if errors.Is(err, database.ErrSelfTarget) {
+		return printer.Text(operation + ".self")
+	} else if errors.Is(err, database.ErrInsufficientFunds) {
+		return printer.Text("pay.cant_afford")
+	} else if err != nil {
+		return printer.Error(userID, err)
+	}
+
+	text := ""
+	switch {
+	case res.Operation == "rob" && res.Success:
+		text = printer.Text("rob.success", res.Amount, res.TargetName)
+	case res.Operation == "rob":
+		text = printer.Text("rob.caught", res.TargetName, res.Amount, int(res.JailTime.Minutes()))
+	case res.Operation == "pay":
+		text = printer.Text("pay.success", res.TargetID, res.Amount)
+	}
+	return text + printer.Announcements(res.Announcements)
+}
@@ -0,0 +1,35 @@
+package replies
+
+import (
+	"errors"
+	"strings"
+	database "mary-bot/database"
+)
+
+// How many problems with an import file are listed before the rest are counted, to stay under Discord's message limit
+const maxSnapshotProblems = 10
+
+// mary import [dry-run]
+func (printer Printer) Import(userID int, res database.ImportResult, err error) (string) {
+	var invalid database.ErrInvalidSnapshot
+	if errors.As(err, &invalid) {
+		problems := invalid.Problems
+		text := ""
+		if len(problems) > maxSnapshotProblems {
+			text = printer.Text("import.more_problems", len(problems) - maxSnapshotProblems)
+			problems = problems[:maxSnapshotProblems]
+		}
+		return printer.Text("import.invalid", "\n- " + strings.Join(problems, "\n- ")) + text
+	}
+	if err != nil {
+		return printer.Error(userID, err)
+	}
+	if res.DryRun && res.Unmarried > 0 {
+		return printer.Text("import.dry_run", res.Created, res.Updated) + " " + printer.Text("import.dry_run_unmarried", res.Unmarried)
+	} else if res.DryRun {
+		return printer.Text("import.dry_run", res.Created, res.Updated)
+	} else if res.Unmarried > 0 {
+		return printer.Text("import.success", res.Created, res.Updated) + " " + printer.Text("import.unmarried", res.Unmarried)
+	}
+	return printer.Text("import.success", res.Created, res.Updated)
+}
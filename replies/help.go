@@ -0,0 +1,116 @@
+package replies
+
+import (
+	"mary-bot/config"
+	"github.com/bwmarrin/discordgo"
+)
+
+// A command as it's shown in `mary help`
+// The usage is what the user types, so it isn't translated
+type helpEntry struct {
+	Usage string
+	Key   string // The key for what the command does
+}
+
+// Define the help pages
+var helpPages = [][]helpEntry{
+	{
+		{"mary help [optional: page number]", "help.help"},
+		{"mary test", "help.test"},
+		{"mary test connection", "help.test_connection"},
+		{"mary del [amount] (admin only)", "help.del"},
+		{"mary bankrupt @user (admin only)", "help.bankrupt"},
+		{"mary quote", "help.quote"},
+		{"mary start", "help.start"},
+		{"mary profile [optional: @user]", "help.profile"},
+		{"mary bal [optional: @user]", "help.bal"},
+		{"mary inventory", "help.inventory"},
+		{"mary give @user [item name] [optional: amount]", "help.give"},
+		{"mary language [optional: code/default]", "help.language"},
+		{"mary language server [code] (Manage Server only)", "help.language_server"},
+		{"mary config show", "help.config_show"},
+		{"mary config set [setting] [value] (Manage Server only)", "help.config_set"},
+		{"mary export [optional: json/csv] (Administrator only)", "help.export"},
+		{"mary import [optional: dry-run] + attached file (Administrator only)", "help.import"},
+	},
+	{
+		{"mary shop [optional: page number]", "help.shop"},
+		{"mary buy [item name] [optional: amount]", "help.buy"},
+		{"mary sell [item name] [optional: amount]", "help.sell"},
+		{"mary daily", "help.daily"},
+		{"mary pay @user [amount]", "help.pay"},
+		{"mary top/leaderboard [optional: wallet/networth/trivia/gambling]", "help.top"},
+		{"mary top global [optional: wallet/networth/trivia/gambling]", "help.top_global"},
+		{"mary global join/leave", "help.global"},
+		{"mary profile global [optional: @user]", "help.profile_global"},
+		{"mary profile card [optional: theme] [optional: @user]", "help.profile_card"},
+		{"mary achievements", "help.achievements"},
+		{"mary rank", "help.rank"},
+		{"mary levelrole [level] @role", "help.levelrole"},
+		{"mary trivia [optional: amount]", "help.trivia"},
+		{"mary gamble [amount]", "help.gamble"},
+		{"mary lottery [amount]", "help.lottery"},
+		{"mary slots [amount]", "help.slots"},
+	},
+	{
+		{"mary use [item name] @user", "help.use"},
+		{"mary eat [item name]", "help.eat"},
+		{"mary runover @user", "help.runover"},
+		{"mary shoot @user", "help.shoot"},
+		{"mary kill @user", "help.kill"},
+		{"mary marry @user", "help.marry"},
+		{"mary divorce @user", "help.divorce"},
+		{"mary rob @user", "help.rob"},
+		{"mary bail", "help.bail"},
+		{"mary repair [item name]", "help.repair"},
+		{"mary recipes", "help.recipes"},
+		{"mary craft [item name] [optional: amount]", "help.craft"},
+		{"mary market [optional: page number]", "help.market"},
+		{"mary market list [item name] [quantity] [price]", "help.market_list"},
+		{"mary market auction [item name] [quantity] [starting price] [minutes]", "help.market_auction"},
+		{"mary market buy/bid/cancel [listing] [bid amount]", "help.market_buy"},
+		{"mary trade @user", "help.trade"},
+		{"mary trade add [item name/coins] [optional: amount]", "help.trade_add"},
+		{"mary trade [optional: confirm/cancel]", "help.trade_confirm"},
+	},
+}
+
+// Number of help pages
+func HelpPages() (int) {
+	return len(helpPages)
+}
+
+// mary help [optional: page number] -> one page of commands, counting from 1
+// Help entries that show the server's own payouts
+var helpArgs = map[string]func(config.Config) []interface{}{
+	"help.daily": func(settings config.Config) []interface{} { return []interface{}{settings.Payouts.Daily} },
+	"help.trivia": func(settings config.Config) []interface{} {
+		return []interface{}{settings.Payouts.TriviaEasy, settings.Payouts.TriviaMedium, settings.Payouts.TriviaHard}
+	},
+	"help.lottery": func(settings config.Config) []interface{} { return []interface{}{settings.Payouts.LotteryBet} },
+	"help.slots": func(settings config.Config) []interface{} { return []interface{}{settings.Payouts.SlotsBet} },
+}
+
+func (printer Printer) Help(page int, avatarURL string, settings config.Config) (*discordgo.MessageEmbed) {
+	embed := &discordgo.MessageEmbed{
+		Title: printer.Text("help.title"),
+		Color: 0xffc0cb,
+		Thumbnail: &discordgo.MessageEmbedThumbnail{
+			URL: avatarURL,
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: printer.Text("help.page", page, len(helpPages)),
+		},
+	}
+	for _, entry := range helpPages[page-1] {
+		var args []interface{}
+		if helpArg, ok := helpArgs[entry.Key]; ok {
+			args = helpArg(settings)
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name: entry.Usage,
+			Value: printer.Text(entry.Key, args...),
+		})
+	}
+	return embed
+}
@@ -0,0 +1,222 @@
+package replies
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	database "mary-bot/database"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// mary shop -> every item in one embed, which is split into pages when it's sent
+func (printer Printer) Shop(listings []database.ShopListing) (*discordgo.MessageEmbed) {
+	embed := &discordgo.MessageEmbed{
+		Title: printer.Text("shop.title"),
+		Color: 0xffc0cb,
+	}
+
+	// Add a field for each item
+	for _, listing := range listings {
+		item := listing.Item
+		value := printer.Text("shop.price", listing.Price, printer.itemDescription(item))
+		if listing.Deal {
+			value = printer.Text("shop.deal", int(database.DealDiscount * 100)) + "\n" + value
+		}
+		if len(listing.History) > 1 {
+			value += "\n" + printer.Text("shop.history", sparkline(listing.History))
+		}
+		if item.MaxDurability > 0 {
+			value += "\n" + printer.Text("shop.durability", item.MaxDurability)
+		}
+		if item.MinLevel > 0 {
+			value += "\n" + printer.Text("shop.min_level", item.MinLevel)
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name: item.Name,
+			Value: value,
+			Inline: false,
+		})
+	}
+	return embed
+}
+
+// Helper to get an item's description in the printer's language, e.g. from "item.gun.description"
+func (printer Printer) itemDescription(item database.ShopItem) (string) {
+	words := strings.Fields(item.Name) // The emoji comes first, e.g. "🔫 Gun"
+	return printer.textOr("item." + strings.ToLower(words[len(words)-1]) + ".description", item.Description)
+}
+
+// mary inventory -> every item the user has in one embed, which is split into pages when it's sent
+func (printer Printer) Inventory(userName string, inventory []database.InventoryItem) (*discordgo.MessageEmbed) {
+	embed := &discordgo.MessageEmbed{
+		Title: printer.Text("inventory.title", userName),
+		Color: 0xffc0cb,
+	}
+
+	for _, item := range inventory {
+		// Show how worn down durable items are
+		value := printer.Text("inventory.quantity", item.Quantity)
+		if item.Broken {
+			value += "\n" + printer.Text("inventory.broken")
+		} else if item.MaxDurability > 0 {
+			value += "\n" + printer.Text("inventory.durability", item.Durability, item.MaxDurability)
+		}
+
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name: item.Emoji + " " + strings.Title(item.Name),
+			Value: value,
+			Inline: true,
+		})
+	}
+	return embed
+}
+
+// mary buy [item] [optional: amount]
+func (printer Printer) Buy(userID int, res database.ItemResult, err error) (string) {
+	if errors.Is(err, database.ErrInsufficientFunds) {
+		return printer.Text("buy.cant_afford")
+	} else if err != nil {
+		return printer.Error(userID, err)
+	}
+	return printer.Text("buy.success", res.Amount, res.Item, res.Coins) + printer.Announcements(res.Announcements)
+}
+
+// mary sell [item] [optional: amount]
+func (printer Printer) Sell(userID int, res database.ItemResult, err error) (string) {
+	var broken database.ErrItemBroken
+	var worn database.ErrItemWorn
+	if errors.Is(err, database.ErrNotEnoughItems) {
+		return printer.Text("sell.not_enough")
+	} else if errors.As(err, &broken) {
+		return printer.Text("sell.broken", broken.Item)
+	} else if errors.As(err, &worn) {
+		return printer.Text("sell.worn", worn.Item)
+	} else if err != nil {
+		return printer.Error(userID, err)
+	}
+	return printer.Text("sell.success", res.Amount, res.Item, res.Coins) + printer.Announcements(res.Announcements)
+}
+
+// mary give @user [item] [optional: amount]
+func (printer Printer) Give(userID int, res database.ItemResult, err error) (string) {
+	var broken database.ErrItemBroken
+	var worn database.ErrItemWorn
+	if errors.Is(err, database.ErrNotPlaying) {
+		return printer.Text("give.not_playing")
+	} else if errors.Is(err, database.ErrNotEnoughItems) {
+		return printer.Text("give.not_enough")
+	} else if errors.As(err, &broken) {
+		return printer.Text("give.broken", broken.Item)
+	} else if errors.As(err, &worn) {
+		return printer.Text("give.worn", worn.Item)
+	} else if errors.Is(err, database.ErrItemNotFound) {
+		return printer.Text("give.not_found")
+	} else if err != nil {
+		return printer.Error(userID, err)
+	}
+	return printer.Text("give.success", res.Amount, res.Item, res.TargetID) + printer.Announcements(res.Announcements)
+}
+
+// mary repair [item]
+func (printer Printer) Repair(userID int, item string, cost int64, err error) (string) {
+	var cantAfford database.ErrCantAfford
+	if errors.Is(err, database.ErrNoRepairNeeded) {
+		return printer.Text("repair.not_needed", item)
+	} else if errors.As(err, &cantAfford) {
+		return printer.Text("repair.cant_afford", item, cantAfford.Cost, cantAfford.Balance)
+	} else if errors.Is(err, database.ErrInsufficientFunds) { // Spent their coins while repairing
+		return printer.Text("repair.no_longer_afford", item)
+	} else if err != nil {
+		return printer.Error(userID, err)
+	}
+	return printer.Text("repair.success", item, cost)
+}
+
+// mary recipes -> everything that can be crafted and what it needs
+func (printer Printer) Recipes(recipes []database.Recipe) (*discordgo.MessageEmbed) {
+	embed := &discordgo.MessageEmbed{
+		Title: printer.Text("recipes.title"),
+		Description: printer.Text("recipes.description"),
+		Color: 0xffc0cb,
+	}
+
+	// Add a field for each recipe
+	for _, recipe := range recipes {
+		ingredients := []string{}
+		for _, ingredient := range recipe.Ingredients {
+			ingredients = append(ingredients, strconv.Itoa(ingredient.Quantity) + "X " + database.ItemDisplayName(ingredient.Name))
+		}
+		product, _ := database.FindShopItem(recipe.Product)
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name: strconv.Itoa(recipe.Quantity) + "X " + product.Name,
+			Value: printer.Text("recipes.needs", strings.Join(ingredients, ", "), printer.itemDescription(product)),
+			Inline: false,
+		})
+	}
+	return embed
+}
+
+// mary craft [item] [optional: amount]
+func (printer Printer) Craft(userID int, res database.CraftResult, err error) (string) {
+	var missing database.ErrMissingIngredients
+	if errors.Is(err, database.ErrNotPositive) {
+		return printer.Text("craft.not_positive")
+	} else if errors.As(err, &missing) {
+		return printer.Text("craft.missing", ingredientList(missing.Needed))
+	} else if err != nil {
+		return printer.Error(userID, err)
+	}
+	return printer.Text("craft.success", res.Quantity, database.ItemDisplayName(res.Product), ingredientList(res.Used)) + printer.Announcements(res.Announcements)
+}
+
+// Helper to list ingredients, e.g. "1X bow, 1X gun"
+func ingredientList(ingredients []database.Ingredient) (string) {
+	list := []string{}
+	for _, ingredient := range ingredients {
+		list = append(list, strconv.Itoa(ingredient.Quantity) + "X " + ingredient.Name)
+	}
+	return strings.Join(list, ", ")
+}
+
+// mary use [item] [optional: @user], and its synonyms like mary shoot and mary marry
+func (printer Printer) Use(userID int, res database.UseResult, err error) (string) {
+	if errors.Is(err, database.ErrNotPlaying) {
+		return printer.Text("use.not_playing")
+	} else if err != nil {
+		return printer.Error(userID, err)
+	}
+
+	text := ""
+	switch res.Outcome {
+	case "ate":
+		text = printer.Text("use.ate")
+	case "jackpot":
+		text = printer.Text("use.jackpot", res.Amount)
+	case "target_broke", "blocked", "proposed", "married":
+		text = printer.Text("use." + res.Outcome, res.TargetID)
+	case "ran_over", "shot_back":
+		text = printer.Text("use." + res.Outcome, res.TargetID, res.Amount)
+	case "robbed":
+		switch res.Item {
+		case "crossbow", "gun":
+			text = printer.Text("use.robbed." + res.Item, res.TargetID, res.Amount)
+		default:
+			text = printer.Text("use.robbed", res.TargetID, res.Amount)
+		}
+	}
+	return text + printer.Announcements(res.Announcements)
+}
+
+// mary divorce @user
+func (printer Printer) Divorce(userID int, res database.DivorceResult, err error) (string) {
+	if errors.Is(err, database.ErrNotPlaying) {
+		return printer.Text("use.not_playing")
+	} else if err != nil {
+		return printer.Error(userID, err)
+	}
+	if res.Official {
+		return printer.Text("divorce.official", res.PartnerID)
+	}
+	return printer.Text("divorce.filed", res.PartnerID)
+}
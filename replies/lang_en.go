@@ -0,0 +1,406 @@
+package replies
+
+// English messages
+// Every key lives here, the other languages fall back to these for anything they haven't translated
+// Names from the game's data (items, achievements, rankings and timers) come from the database package in English, so they aren't repeated here
+var english = map[string]string{
+	// Errors that read the same whichever command caused them
+	"error.not_playing":            "That person is not currently playing the game!",
+	"error.insufficient_funds":     "You don't have enough {coins}!",
+	"error.item_not_found":         "You do not have that item in your inventory!",
+	"error.not_enough_items":       "You do not have enough of that item in your inventory to use!",
+	"error.empty_inventory":        "You do not have any items in your inventory!",
+	"error.no_such_item":           "That item doesn't exist!",
+	"error.not_positive":           "Please specify a positive amount!",
+	"error.self_target":            "You can't do that to yourself!",
+	"error.unknown_command":        "I'm sorry, I dont recognize that command.",
+	"error.already_married":        "You are already married!",
+	"error.target_married":         "That user is already married!",
+	"error.not_married":            "You are not married!",
+	"error.not_married_to_target":  "You are not married to that user!",
+	"error.too_poor":               "That person is too poor to rob!",
+	"error.cant_repair":            "That item can't be repaired!",
+	"error.no_recipe":              "There's no recipe for that item! Use `mary recipes` to see what you can craft.",
+	"error.market_empty":           "There's nothing on the market right now! Use `mary market list` to sell something.",
+	"error.listing_not_found":      "That listing doesn't exist or is no longer available!",
+	"error.auction_not_found":      "That auction doesn't exist or has already ended!",
+	"error.already_highest_bidder": "You're already the highest bidder!",
+	"error.bid_race":               "Someone else just bid on that auction! Check `mary market` and try again.",
+	"error.cant_cancel":            "You don't have an open listing with that number, or someone has already bid on it!",
+	"error.already_trading":        "You already have a trade open! Use `mary trade cancel` to cancel it.",
+	"error.no_trade":               "You don't have a trade open! Use `mary trade @user` to start one.",
+	"error.trade_changed":          "The trade changed before it could go through! Check `mary trade` and confirm again.",
+	"error.no_transactions":        "The market and trades are turned off because my database isn't a replica set! Let my owner know.",
+	"error.already_global":         "You're already on the global leaderboard!",
+	"error.not_global":             "You're not on the global leaderboard! Use `mary global join` to join.",
+	"error.trivia_unavailable":     "Failed to get trivia question!",
+	"error.database":               "Error occurred while %s! %s",
+	"error.jailed":                 "%s, you are in jail for another %s! Use `mary bail` to pay your way out.",
+	"error.item_broken":            "Your %[1]s is broken! Use `mary repair %[1]s` to fix it.",
+	"error.level_too_low":          "You need to be level %d to buy that item! Use `mary rank` to see your level.",
+	"error.level_too_low_other":    "<@%d> needs to be level %d to have that item!",
+	"error.bid_too_low":            "Your bid has to be at least %d {coins}!",
+	"error.target_trading":         "<@%d> is already in the middle of a trade!",
+	"error.no_level_role":          "There's no reward for level %d!",
+	"error.no_such_ranking":        "There's no leaderboard called that! Try one of %s.",
+	"error.no_such_setting":        "There's no setting called that! Try one of %s.",
+	"error.invalid_setting":        "That's not a valid value for %s! %s",
+	"error.unknown":                "Something went wrong! %s",
+	"error.converting_amount":      "Error occurred while converting amount!%s",
+	"error.user_id":                "Error occurred while getting user ID!%s",
+	"error.item_number":            "Error occurred while converting item number!%s",
+	"invalid_number":               "Please enter a valid number!",
+
+	// Cooldowns
+	"cooldown.daily":  "%s, you have already claimed your daily! Please wait %d hours, %d minutes, and %d seconds before claiming again.",
+	"cooldown.beg":    "%s, you have already begged! Please wait %d seconds before begging again.",
+	"cooldown.gamble": "%s, you must wait 10 seconds before gambling again!",
+	"cooldown.trivia": "%s, you must wait 5 seconds before playing trivia again!",
+	"cooldown.rob":    "You have already robbed someone recently! Please wait %s before robbing again.",
+	"cooldown.use":    "You must wait %s before using another item!",
+	"cooldown.other":  "Please wait %s before doing that again!",
+
+	// Shared pieces
+	"announce.achievement": "🏆 <@%d> unlocked the **%s %s** achievement! %s",
+	"announce.level_up":    "⭐ <@%d> reached level %d!",
+	"announce.level_role":  " They've been given the <@&%s> role!",
+	"duration":             "%d minutes and %d seconds",
+	"coins":                "%d {coins}",
+	"currency.name":        "coins",
+	"page":                 "Page %d of %d",
+	"date_format":          "January 2, 2006",
+	"timer.ready":          "Ready!",
+
+	// mary test
+	"test.success":    "Test successful!",
+	"test.connection": "Database connection successful!",
+
+	// mary help
+	"help.title":             "Mary's Commands",
+	"help.page":              "Page %d/%d",
+	"help.avatar_error":      "Error retrieving my avatar!",
+	"help.invalid_page":      "Please enter a valid page number!",
+	"help.help":              "Shows all commands. The default page number is 1.",
+	"help.test":              "Tests if Mary is online.",
+	"help.test_connection":   "Tests if Mary can connect to the database.",
+	"help.del":               "Deletes a set number of messages.",
+	"help.bankrupt":          "Reduces the user's balance to 0.",
+	"help.quote":             "Shows a random quote.",
+	"help.profile":           "Shows your profile or a specified user's profile, with net worth, rank and how long is left on each cooldown.",
+	"help.start":             "Joins the economy and shows how to get started. Any other command joins you too.",
+	"help.bal":               "Shows your balance or a specified user's balance.",
+	"help.inventory":         "Shows your inventory.",
+	"help.give":              "Gives an item to a specified user. The default amount is 1.",
+	"help.language":          "Shows or changes the language Mary replies to you in. `default` goes back to the server's language.",
+	"help.language_server":   "Changes the language Mary replies in for everyone on the server who hasn't picked their own.",
+	"help.config_show":       "Shows the server's economy settings: payouts, cooldowns, starting balance and currency.",
+	"help.export":            "Exports every user's balance, inventory and marriage as a JSON or CSV file.",
+	"help.import":            "Restores a file from `mary export`, attached to the message. `dry-run` only shows what would change.",
+	"help.config_set":        "Changes one of the server's economy settings, e.g. `mary config set cooldowns.daily 12h`. `default` goes back to the bot's setting.",
+	"help.shop":              "Shows the shop. Prices go up as items are bought, go down as they're sold back and settle back over time, and there are new deals every day.",
+	"help.buy":               "Buys the specified item. The default amount is 1.",
+	"help.sell":              "Sells the specified item back to the shop for part of its price, never more than part of its base price.",
+	"help.daily":             "Gives you %d {coins}.",
+	"help.pay":               "Pays the mentioned user the specified amount of {coins}.",
+	"help.top":               "Shows every user ranked by balance, net worth, trivia wins or gambling profit, and where you stand.",
+	"help.top_global":        "Ranks everyone on the global leaderboard by their stats added up across every server.",
+	"help.global":            "Joins or leaves the global leaderboard. Each server's {coins} and items stay separate.",
+	"help.profile_global":    "Shows stats added up across every server for someone on the global leaderboard.",
+	"help.profile_card":      "Draws a profile card with your avatar, level, balance, badges and items. Themes: pink, dark, mint and sunset.",
+	"help.achievements":      "Shows the achievements you've unlocked and the ones still to go. Unlocked ones show up as badges on your profile.",
+	"help.rank":              "Shows your level and XP. You earn XP by chatting and using economy commands, and some shop items need a higher level.",
+	"help.levelrole":         "Gives users a role when they reach a level. Use `mary levelrole remove [level]` to take it off, or just `mary levelrole` to list them. Needs Manage Roles.",
+	"help.trivia":            "Starts a trivia game. Pays %d, %d, or %d {coins} upon win depending on the difficulty. You can also gamble for 2X, 3X, 5X your bet.",
+	"help.gamble":            "Gamble the specified amount of {coins}.",
+	"help.lottery":           "Enter the lottery with %d {coins}.",
+	"help.slots":             "Play slots with %d {coins}.",
+	"help.use":               "Uses the specified item on the mentioned user. You can only use one item at a time.",
+	"help.eat":               "You eat a chocolate. Who knows, maybe you'll get lucky?",
+	"help.runover":           "Run over the mentioned user. Wears down your car a little.",
+	"help.shoot":             "Shoot the mentioned user with the gun. If user has no gun, it uses the bow. Wears down the gun/bow used.",
+	"help.kill":              "Shoot the mentioned user with the gun. Wears down your gun.",
+	"help.marry":             "Give the mentioned user a ring. If they give you one back, congratulations! You're married!",
+	"help.divorce":           "Divorce the mentioned user. You must be married to them or have proposed to them. Gives you back one ring.",
+	"help.rob":               "Try to rob the mentioned user. A gun improves your odds and a shield protects them. If you get caught, you pay them a fine and go to jail.",
+	"help.bail":              "Pay your way out of jail. Costs 50 {coins} for every minute left on your sentence.",
+	"help.repair":            "Repairs a worn down or broken item. The more worn down it is, the more it costs.",
+	"help.recipes":           "Shows every item you can craft and what it needs.",
+	"help.craft":             "Crafts an item from the ones in your inventory. The default amount is 1.",
+	"help.market":            "Shows what other players are selling and auctioning.",
+	"help.market_list":       "Lists items on the market for 24 hours. The items are held by the market until someone buys them.",
+	"help.market_auction":    "Auctions items off to the highest bidder.",
+	"help.market_buy":        "Buys a listing, bids on an auction, or takes down your own listing.",
+	"help.trade":             "Starts a trade with the mentioned user. Trades are cancelled after 5 minutes without changes.",
+	"help.trade_add":         "Adds items or {coins} to your side of the trade.",
+	"help.trade_confirm":     "Shows, confirms or cancels your trade. Once both sides confirm, everything is swapped at once.",
+
+	// mary language
+	"language.current":       "You're using %s. Use `mary language [code]` to change it. Languages: %s.",
+	"language.set":           "Mary will now reply to you in %s!",
+	"language.cleared":       "Mary will now reply to you in the server's language.",
+	"language.server_set":    "Mary will now reply in %s on this server, unless someone has picked their own language.",
+	"language.unknown":       "There's no language called that! Try one of %s.",
+	"language.no_permission": "You need the Manage Server permission to change the server's language!",
+	"language.server_usage":  "Please specify a language code, e.g. `mary language server es`!",
+
+	// mary config
+	"config.title":         "Server Settings",
+	"config.description":   "Change these with `mary config set [setting] [value]`.",
+	"config.changed":       "%s (changed)",
+	"config.empty":         "None",
+	"config.set":           "%s is now %s!",
+	"config.reset":         "%s is back to the default, %s!",
+	"config.usage":         "Please use `mary config show` or `mary config set [setting] [value]`!",
+	"config.no_permission": "You need the Manage Server permission to change the server's settings!",
+
+	// mary export and mary import
+	"export.no_permission":  "You need the Administrator permission to export or import the server's economy!",
+	"export.usage":          "Please use `mary export [optional: json/csv]`!",
+	"export.success":        "Here's the economy of %s, with %d players!",
+	"export.error":          "Error occurred while writing the export! %s",
+	"import.usage":             "Please attach a .json or .csv file from `mary export` to `mary import [optional: dry-run]`!",
+	"import.download_error":    "Error occurred while downloading the file! %s",
+	"import.too_big":           "That file is too big to import, the limit is %d MB!",
+	"import.invalid":           "That file can't be imported, nothing was changed:%s",
+	"import.more_problems":     "\n...and %d more.",
+	"import.dry_run":           "Dry run: importing would add %d new players and replace the balance, inventory and marriage of %d players. Nothing was changed.",
+	"import.success":           "Import done! Added %d new players and replaced the balance, inventory and marriage of %d players.",
+	"import.dry_run_unmarried": "%d players who aren't in the file are married to someone who is, and would become single.",
+	"import.unmarried":         "%d players who aren't in the file were married to someone who is, and are single now.",
+
+	// mary profile
+	"profile.title":      "Profile",
+	"profile.username":   "Username",
+	"profile.balance":    "Balance",
+	"profile.net_worth":  "Net Worth",
+	"profile.rank":       "Rank",
+	"profile.rank_of":    "#%d of %d",
+	"profile.level":      "Level",
+	"profile.server":     "Server",
+	"profile.married_to": "Married To",
+	"profile.badges":     "Badges",
+	"profile.timers":     "Timers",
+	"profile.no_spouse":  "None",
+	"profile.no_badges":  "None yet",
+	"profile.jail":       "🚔 Jail: %s",
+	"card.no_theme":      "There's no theme called that! Try one of %s.",
+	"card.draw_error":    "Error occurred while drawing profile card! %s",
+
+	// mary global
+	"global.profile_title":      "Global Profile",
+	"global.servers":            "Servers",
+	"global.not_on_leaderboard": "That person isn't on the global leaderboard! Use `mary global join` to join.",
+	"global.usage":              "Please specify `join` or `leave`!",
+	"global.nobody":             "Nobody has joined the global leaderboard yet! Use `mary global join` to join.",
+	"global.joined":             "You've joined the global leaderboard! Your stats from every server will be added up. Use `mary global leave` to leave.",
+	"global.left":               "You've left the global leaderboard. Your stats in each server haven't changed.",
+
+	// mary top/leaderboard
+	"leaderboard.title":        "Leaderboard - %s",
+	"leaderboard.global_title": "Global Leaderboard - %s",
+	"leaderboard.your_rank":    "Your rank: #%d of %d with %d %s",
+	"leaderboard.not_global":   "You're not on the global leaderboard. Use `mary global join` to join.",
+	"leaderboard.rank":         "Rank",
+	"leaderboard.name":         "Name",
+	"leaderboard.nobody":       "Nobody is playing yet!",
+	"leaderboard.icon_error":   "Error retrieving server profile picture!",
+
+	// mary rank and mary levelrole
+	"rank.title":              "%s's Rank",
+	"rank.level":              "Level",
+	"rank.server_rank":        "Server Rank",
+	"rank.xp":                 "XP",
+	"rank.progress":           "Progress",
+	"rank.to_next_level":      "%s %d XP to level %d",
+	"rank.next_reward":        "Next Reward",
+	"rank.reward":             "<@&%s> at level %d",
+	"levelrole.not_positive":  "Please specify a positive level!",
+	"levelrole.removed":       "Removed the reward for level %d.",
+	"levelrole.added":         "Users who reach level %d will now get the <@&%s> role!",
+	"levelrole.none":          "There are no level rewards yet! Use `mary levelrole [level] @role` to add one.",
+	"levelrole.line":          "Level %d: <@&%s>",
+	"levelrole.no_permission": "You need the Manage Roles permission to change level rewards!",
+	"levelrole.remove_usage":  "Please specify a level to remove the reward for!",
+	"levelrole.usage":         "Please specify a level and mention a role, e.g. `mary levelrole 5 @Regular`!",
+
+	// mary achievements
+	"achievements.title":       "%s's Achievements",
+	"achievements.unlocked":    "%d of %d unlocked",
+	"achievements.unlocked_on": "Unlocked %s",
+
+	// mary bankrupt and mary quote
+	"bankrupt.no_user": "Please mention a user! Are you trying to bankrupt yourself?",
+	"quote.error":      "Error retrieving quote!",
+
+	// Another command from the same user is still running
+	"busy":                    "%s, please wait, your previous command is still running.",
+
+	// mary bal/daily/beg/gamble/lottery/slots
+	"economy.bal":             "%s, you have %d {coins}.",
+	"economy.daily":           "%s, you have received your daily %d {coins}! 🔥 Streak: %d days",
+	"economy.beg":             "%s, you have received %d {coins}!",
+	"economy.negative":        "Balance cannot be negative!",
+	"economy.welcome":         "Welcome to the economy, %s! You start with %d {coins}. Claim more with `mary daily`, and see everything you can do with `mary help`.",
+	"economy.already_started": "%s, you're already playing! You have %d {coins}.",
+	"bal.error":               "Error retrieving balance!",
+	"gamble.cant_afford":      "%s, you don't have enough {coins} to gamble that much!",
+	"gamble.win":              "%s, you win! +%d {coins}!",
+	"gamble.lose":             "%s, you lose. -%d {coins}.",
+	"gamble.no_amount":        "Please specify an amount to be gambled!",
+	"gamble.invalid_amount":   "Please specify a valid amount to be gambled!",
+	"gamble.negative_amount":  "Please specify a positive amount to be gambled!",
+	"gamble.gambling":         "Gambling %s {coins}...",
+	"lottery.limit":           "You can only spend %d {coins} on the lottery!",
+	"slots.limit":             "You can only spend %d {coins} on slots!",
+
+	// mary trivia
+	"trivia.details":        "Category: %s \nDifficulty: %s",
+	"trivia.choices":        "Choices",
+	"trivia.paid":           "%s, you have been paid %d {coins}!",
+	"trivia.invalid_amount": "Please specify a valid amount to gamble!",
+	"trivia.checking":       "Gambling %s {coins}. Checking balance...",
+	"trivia.wait_error":     "Error waiting for response!",
+	"trivia.timeout":        "You ran out of time!",
+	"trivia.correct":        "Correct!",
+	"trivia.incorrect":      "Incorrect! The correct answer is %s.",
+
+	// mary rob/pay/bail
+	"rob.self":            "You cannot rob yourself!",
+	"rob.success":         "You successfully robbed %d {coins} from %s!",
+	"rob.caught":          "You got caught trying to rob %s! You paid them a fine of %d {coins} and were thrown in jail for %d minutes.",
+	"rob.no_user":         "Please specify a user to rob!",
+	"pay.self":            "You cannot pay yourself!",
+	"pay.cant_afford":     "You do not have enough money to pay that amount!",
+	"pay.success":         "You successfully paid <@%d> %d {coins}!",
+	"pay.no_amount":       "Please specify an amount to be paid!",
+	"pay.invalid_amount":  "Please specify a valid amount to be paid!",
+	"pay.negative_amount": "Please specify a positive amount to be paid!",
+	"bail.not_jailed":     "%s, you are not in jail!",
+	"bail.cant_afford":    "%s, your bail is %d {coins}, but you only have %d {coins}!",
+	"bail.no_longer_afford": "%s, you can no longer afford your bail of %d {coins}!",
+	"bail.paid":           "%s, you paid %d {coins} in bail and are free to go!",
+
+	// mary shop/inventory/buy/sell/give/repair
+	"shop.title":                "Shop",
+	"shop.price":                "Price: %d {coins}\n%s",
+	"shop.deal":                 "🏷️ Today's deal! %d%% off",
+	"shop.history":              "Recent prices: %s",
+	"shop.durability":           "Durability: %d uses",
+	"shop.min_level":            "🔒 Requires level %d",
+	"inventory.title":           "%s's Inventory",
+	"inventory.quantity":        "Quantity: %d",
+	"inventory.broken":          "Durability: Broken",
+	"inventory.durability":      "Durability: %d/%d",
+	"buy.cant_afford":           "You don't have enough money to buy this item!",
+	"buy.success":               "You have successfully bought %dX %s for %d {coins}!",
+	"buy.no_item":               "Please specify an item to buy!",
+	"sell.not_enough":           "You don't have enough of that item to sell!",
+	"sell.broken":               "The shop won't buy a broken %[1]s! Use `mary repair %[1]s` to fix it first.",
+	"sell.worn":                 "Your last %[1]s is worn down, so the shop won't buy it! Use `mary repair %[1]s` to fix it first.",
+	"sell.success":              "You have successfully sold %dX %s for %d {coins}!",
+	"sell.no_item":              "Please specify an item to sell!",
+	"give.not_playing":          "The user you are trying to give an item to is not playing the game!",
+	"give.not_enough":           "You do not have enough of this item to give!",
+	"give.broken":               "You can't give away a broken %[1]s! Use `mary repair %[1]s` to fix it first.",
+	"give.worn":                 "Your last %[1]s is worn down, so it can't be given away! Use `mary repair %[1]s` to fix it first.",
+	"give.not_found":            "You do not have this item in your inventory!",
+	"give.success":              "You gave %dX %s to <@%d>!",
+	"give.no_user":              "Please specify a user to give the item to!",
+	"give.no_item":              "Please specify an item to give!",
+	"give.self":                 "You can't give yourself an item!",
+	"repair.not_needed":         "Your %s doesn't need repairing!",
+	"repair.cant_afford":        "Repairing your %s costs %d {coins}, but you only have %d {coins}!",
+	"repair.no_longer_afford":   "You no longer have enough {coins} to repair your %s!",
+	"repair.success":            "You repaired your %s for %d {coins}! It's as good as new.",
+	"repair.no_item":            "Please specify an item to repair!",
+
+	// mary recipes/craft
+	"recipes.title":       "Recipes",
+	"recipes.description": "Use `mary craft [item name] [optional: amount]` to craft an item.",
+	"recipes.needs":       "Needs: %s\n%s",
+	"craft.not_positive":  "Please specify a positive amount to craft!",
+	"craft.missing":       "You don't have enough ingredients! You need %s.",
+	"craft.success":       "You crafted %dX %s from %s!",
+	"craft.no_item":       "Please specify an item to craft!",
+
+	// mary use and its synonyms
+	"use.not_playing":     "That user is not currently playing the game!",
+	"use.ate":             "You ate some chocolate. Yum!",
+	"use.jackpot":         "You found a golden ticket! You won %d {coins}!",
+	"use.target_broke":    "You ran over <@%d> with your car, but they didn't have enough money to pay you!",
+	"use.ran_over":        "You ran over <@%d> with your car and took %d {coins} from them!",
+	"use.blocked":         "You shot <@%d> with your gun, but they had a shield and it blocked the bullet!",
+	"use.robbed":          "You shot <@%d> and took %d {coins} from them!",
+	"use.robbed.crossbow": "You shot <@%d> with your crossbow and took %d {coins} from them!",
+	"use.robbed.gun":      "You held up <@%d> at gunpoint and robbed %d {coins} from them!",
+	"use.shot_back":       "You tried to rob <@%d> with a bow, but they had a gun and shot you! You lost %d {coins}!",
+	"use.married":         "🎉 Congratulations! You and <@%d> are now officially married! 🎉",
+	"use.proposed":        "You proposed to <@%d> with a ring! They now have to accept your proposal by using their own ring!",
+	"use.no_item":         "Please specify an item to use!",
+	"use.self.car":        "You can't run yourself over!",
+	"use.self.rob":        "You can't rob yourself!",
+	"use.self.marry":      "You can't marry yourself!",
+	"target.missing":      "Please specify a target!",
+	"target.invalid":      "Please specify a valid target!",
+	"eat.no_item":         "Please specify an item to eat!",
+	"eat.cant":            "You can't eat that!",
+	"divorce.official":    "The papers have gone through. You and <@%d> are now officially divorced...",
+	"divorce.filed":       "You filed for divorce with <@%d>! They now have to sign the papers to finalize the divorce.",
+
+	// mary market
+	"market.title":             "Market",
+	"market.description":       "Use `mary market buy [listing]` to buy or `mary market bid [listing] [amount]` to bid.",
+	"market.price":             "Price: %d {coins}",
+	"market.highest_bid":       "Highest bid: %d {coins} by <@%d>",
+	"market.starting_bid":      "Starting bid: %d {coins}",
+	"market.seller":            "Seller: %s\nEnds in %s",
+	"market.listing":           "Listing #%d: %dX %s",
+	"market.auction":           "Auction #%d: %dX %s",
+	"market.list.quantity":     "Please specify a positive quantity to list!",
+	"market.list.price":        "Please specify a positive price!",
+	"market.list.length":       "Auctions must last between 1 minute and %d days!",
+	"market.list.broken":       "You can't sell a broken %[1]s! Use `mary repair %[1]s` to fix it first.",
+	"market.list.worn":         "Your last %[1]s is worn down, so it can't be sold! Use `mary repair %[1]s` to fix it first.",
+	"market.list.not_enough":   "You don't have enough of that item to list!",
+	"market.list.auction":      "You put %dX %s up for auction as listing #%d! Bidding starts at %d {coins} and ends in %s.",
+	"market.list.listing":      "You listed %dX %s for %d {coins} as listing #%d! It will expire in %d hours.",
+	"market.list.usage":        "Please use `mary market list [item name] [quantity] [price]`!",
+	"market.auction.usage":     "Please use `mary market auction [item name] [quantity] [starting price] [minutes]`!",
+	"market.buy.auction":       "That listing is an auction! Use `mary market bid %d [amount]` instead.",
+	"market.buy.own":           "You can't buy your own listing! Use `mary market cancel %d` to take it down.",
+	"market.buy.cant_afford":   "You don't have enough {coins} to buy that listing!",
+	"market.buy.success":       "You bought %dX %s from %s for %d {coins}!",
+	"market.buy.usage":         "Please specify a listing number to buy!",
+	"market.bid.not_auction":   "That listing isn't an auction! Use `mary market buy %d` instead.",
+	"market.bid.own":           "You can't bid on your own auction!",
+	"market.bid.cant_afford":   "You don't have enough {coins} to bid that much!",
+	"market.bid.outbid":        "You bid %d {coins} on auction #%d and outbid <@%d>! The auction ends in %s.",
+	"market.bid.success":       "You bid %d {coins} on auction #%d! The auction ends in %s.",
+	"market.bid.usage":         "Please use `mary market bid [listing] [amount]`!",
+	"market.cancel.success":    "You took down listing #%d and got your %dX %s back.",
+	"market.cancel.usage":      "Please specify a listing number to cancel!",
+	"market.unknown_command":   "I'm sorry, I dont recognize that market command.",
+
+	// mary trade
+	"trade.self":            "You can't trade with yourself!",
+	"trade.not_positive":    "Please specify a positive amount to add!",
+	"trade.cant_afford":     "You don't have enough {coins} to offer that much!",
+	"trade.not_enough":      "You don't have enough of that item to offer!",
+	"trade.broken":          "You can't trade away a broken %[1]s! Use `mary repair %[1]s` to fix it first.",
+	"trade.worn":            "Your last %[1]s is worn down, so it can't be traded away! Use `mary repair %[1]s` to fix it first.",
+	"trade.failed":          "The trade couldn't go through. %s",
+	"trade.short_items":     "%s no longer has %dX %s!",
+	"trade.short_coins":     "%s no longer has %d {coins}!",
+	"trade.unknown_command": "I'm sorry, I dont recognize that trade command.",
+	"trade.open":            "<@%d>, <@%d> wants to trade with you!",
+	"trade.confirm":         "<@%d> confirmed the trade! Waiting for the other side...",
+	"trade.complete":        "🤝 The trade between <@%d> and <@%d> is complete!",
+	"trade.cancel":          "The trade between <@%d> and <@%d> was cancelled.",
+	"trade.nothing":         "Nothing yet",
+	"trade.confirmed":       "✅ Confirmed",
+	"trade.title":           "Trade",
+	"trade.description":     "Use `mary trade add [item name] [optional: amount]` or `mary trade add coins [amount]` to add to your offer, then `mary trade confirm`.",
+	"trade.expires":         "Expires in %s without any changes",
+	"trade.add_usage":       "Please specify an item or {coins} to add!",
+	"trade.no_user":         "Please mention a user to trade with!",
+}
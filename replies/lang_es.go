@@ -0,0 +1,459 @@
+package replies
+
+// Spanish messages
+// Anything missing falls back to English
+var spanish = map[string]string{
+	// Errors that read the same whichever command caused them
+	"error.not_playing":            "¡Esa persona no está jugando ahora mismo!",
+	"error.insufficient_funds":     "¡No tienes suficientes {coins}!",
+	"error.item_not_found":         "¡No tienes ese objeto en tu inventario!",
+	"error.not_enough_items":       "¡No tienes suficientes unidades de ese objeto en tu inventario para usarlo!",
+	"error.empty_inventory":        "¡No tienes ningún objeto en tu inventario!",
+	"error.no_such_item":           "¡Ese objeto no existe!",
+	"error.not_positive":           "¡Indica una cantidad positiva!",
+	"error.self_target":            "¡No puedes hacerte eso a ti mismo!",
+	"error.unknown_command":        "Lo siento, no reconozco ese comando.",
+	"error.already_married":        "¡Ya estás casado!",
+	"error.target_married":         "¡Esa persona ya está casada!",
+	"error.not_married":            "¡No estás casado!",
+	"error.not_married_to_target":  "¡No estás casado con esa persona!",
+	"error.too_poor":               "¡Esa persona es demasiado pobre para robarle!",
+	"error.cant_repair":            "¡Ese objeto no se puede reparar!",
+	"error.no_recipe":              "¡No hay receta para ese objeto! Usa `mary recipes` para ver lo que puedes fabricar.",
+	"error.market_empty":           "¡No hay nada en el mercado ahora mismo! Usa `mary market list` para vender algo.",
+	"error.listing_not_found":      "¡Ese anuncio no existe o ya no está disponible!",
+	"error.auction_not_found":      "¡Esa subasta no existe o ya ha terminado!",
+	"error.already_highest_bidder": "¡Ya eres el mejor postor!",
+	"error.bid_race":               "¡Alguien acaba de pujar en esa subasta! Mira `mary market` e inténtalo de nuevo.",
+	"error.cant_cancel":            "¡No tienes un anuncio abierto con ese número, o alguien ya ha pujado en él!",
+	"error.already_trading":        "¡Ya tienes un intercambio abierto! Usa `mary trade cancel` para cancelarlo.",
+	"error.no_trade":               "¡No tienes ningún intercambio abierto! Usa `mary trade @usuario` para empezar uno.",
+	"error.trade_changed":          "¡El intercambio cambió antes de completarse! Mira `mary trade` y confirma de nuevo.",
+	"error.no_transactions":        "¡El mercado y los intercambios están desactivados porque mi base de datos no es un replica set! Avisa a mi dueño.",
+	"error.already_global":         "¡Ya estás en la clasificación global!",
+	"error.not_global":             "¡No estás en la clasificación global! Usa `mary global join` para unirte.",
+	"error.trivia_unavailable":     "¡No se pudo obtener una pregunta de trivia!",
+	"error.database":               "¡Ocurrió un error al %s! %s",
+	"error.jailed":                 "%s, ¡estás en la cárcel durante %s más! Usa `mary bail` para pagar tu salida.",
+	"error.item_broken":            "¡Tu %[1]s está roto! Usa `mary repair %[1]s` para arreglarlo.",
+	"error.level_too_low":          "¡Necesitas el nivel %d para comprar ese objeto! Usa `mary rank` para ver tu nivel.",
+	"error.level_too_low_other":    "¡<@%d> necesita el nivel %d para tener ese objeto!",
+	"error.bid_too_low":            "¡Tu puja tiene que ser de al menos %d {coins}!",
+	"error.target_trading":         "¡<@%d> ya está en medio de un intercambio!",
+	"error.no_level_role":          "¡No hay recompensa para el nivel %d!",
+	"error.no_such_ranking":        "¡No hay ninguna clasificación con ese nombre! Prueba con %s.",
+	"error.no_such_setting":        "¡No hay ningún ajuste con ese nombre! Prueba con %s.",
+	"error.invalid_setting":        "¡Ese no es un valor válido para %s! %s",
+	"error.unknown":                "¡Algo salió mal! %s",
+	"error.converting_amount":      "¡Ocurrió un error al convertir la cantidad! %s",
+	"error.user_id":                "¡Ocurrió un error al obtener el ID de usuario! %s",
+	"error.item_number":            "¡Ocurrió un error al convertir el número de objetos! %s",
+	"invalid_number":               "¡Introduce un número válido!",
+
+	// What was happening when the database failed, for "error.database"
+	"op.connecting to database":            "conectar con la base de datos",
+	"op.creating MongoDB client":           "crear el cliente de MongoDB",
+	"op.creating leaderboard indexes":      "crear los índices de la clasificación",
+	"op.decoding result":                   "leer el resultado",
+	"op.finding user in database":          "buscar al usuario en la base de datos",
+	"op.finding user":                      "buscar al usuario",
+	"op.getting user balance":              "obtener el saldo del usuario",
+	"op.inserting to database":             "guardar en la base de datos",
+	"op.pinging database":                  "contactar con la base de datos",
+	"op.selecting from database":           "consultar la base de datos",
+	"op.starting database session":         "iniciar la sesión de la base de datos",
+	"op.updating database":                 "actualizar la base de datos",
+	"op.updating pinged user's inventory":  "actualizar el inventario del otro usuario",
+	"op.updating user's balance":           "actualizar el saldo del usuario",
+	"op.working out rank":                  "calcular el puesto",
+
+	// Cooldowns
+	"cooldown.daily":  "%s, ¡ya has reclamado tu recompensa diaria! Espera %d horas, %d minutos y %d segundos antes de volver a reclamarla.",
+	"cooldown.beg":    "%s, ¡ya has mendigado! Espera %d segundos antes de volver a mendigar.",
+	"cooldown.gamble": "%s, ¡debes esperar 10 segundos antes de volver a apostar!",
+	"cooldown.trivia": "%s, ¡debes esperar 5 segundos antes de volver a jugar a la trivia!",
+	"cooldown.rob":    "¡Ya has robado a alguien hace poco! Espera %s antes de volver a robar.",
+	"cooldown.use":    "¡Debes esperar %s antes de usar otro objeto!",
+	"cooldown.other":  "¡Espera %s antes de volver a hacer eso!",
+
+	// Shared pieces
+	"announce.achievement": "🏆 ¡<@%d> ha desbloqueado el logro **%s %s**! %s",
+	"announce.level_up":    "⭐ ¡<@%d> ha alcanzado el nivel %d!",
+	"announce.level_role":  " ¡Ha recibido el rol <@&%s>!",
+	"duration":             "%d minutos y %d segundos",
+	"coins":                "%d {coins}",
+	"currency.name":        "monedas",
+	"page":                 "Página %d de %d",
+	"date_format":          "02/01/2006",
+	"timer.ready":          "¡Listo!",
+
+	// Timers on the profile
+	"timer.Daily":    "Diario",
+	"timer.Beg":      "Mendigar",
+	"timer.Rob":      "Robar",
+	"timer.Gamble":   "Apostar",
+	"timer.Trivia":   "Trivia",
+	"timer.Use Item": "Usar objeto",
+
+	// Leaderboard rankings
+	"ranking.wallet.title":   "Cartera",
+	"ranking.networth.title": "Patrimonio",
+	"ranking.trivia.title":   "Victorias en trivia",
+	"ranking.trivia.unit":    "victorias",
+	"ranking.gambling.title": "Ganancias en apuestas",
+
+	// Item descriptions
+	"item.gun.description":       "Es una pistola... ¿qué esperabas?",
+	"item.car.description":       "¡Atropella a la gente con este coche!",
+	"item.chocolate.description": "No te salvará de los zombis, ¡pero a todo el mundo le encanta el chocolate!",
+	"item.ring.description":      "¡Enhorabuena! ¿Quién es la persona afortunada?",
+	"item.bow.description":       "Puede que no sea tan potente como una pistola, ¡pero es más barato!",
+	"item.shield.description":    "¡Protégete de los atacantes!",
+	"item.crossbow.description":  "Un arco con piezas de pistola atornilladas. ¡Sus virotes atraviesan los escudos!",
+	"item.giftbox.description":   "Diez chocolates bien envueltos. ¡Regálaselo a alguien especial!",
+
+	// Achievements
+	"achievement.married.name":             "Felices para siempre",
+	"achievement.married.description":      "Cásate con un anillo.",
+	"achievement.master_thief.name":        "Maestro ladrón",
+	"achievement.master_thief.description": "Roba a alguien con éxito 10 veces.",
+	"achievement.jackpot.name":             "Premio gordo",
+	"achievement.jackpot.description":      "Gana 5X en la lotería.",
+	"achievement.car_owner.name":           "De viaje",
+	"achievement.car_owner.description":    "Ten un coche.",
+	"achievement.dedicated.name":           "Constante",
+	"achievement.dedicated.description":    "Reclama tu recompensa diaria 30 días seguidos.",
+
+	// mary test
+	"test.success":    "¡Prueba superada!",
+	"test.connection": "¡Conexión con la base de datos correcta!",
+
+	// mary help
+	"help.title":           "Comandos de Mary",
+	"help.page":            "Página %d/%d",
+	"help.avatar_error":    "¡Error al obtener mi avatar!",
+	"help.invalid_page":    "¡Introduce un número de página válido!",
+	"help.help":            "Muestra todos los comandos. La página por defecto es la 1.",
+	"help.test":            "Comprueba si Mary está en línea.",
+	"help.test_connection": "Comprueba si Mary puede conectarse a la base de datos.",
+	"help.del":             "Borra un número de mensajes.",
+	"help.bankrupt":        "Deja el saldo del usuario a 0.",
+	"help.quote":           "Muestra una cita al azar.",
+	"help.profile":         "Muestra tu perfil o el de otro usuario, con su patrimonio, su puesto y cuánto le queda a cada espera.",
+	"help.start":           "Te une a la economía y te enseña cómo empezar. Cualquier otro comando también te une.",
+	"help.bal":             "Muestra tu saldo o el de otro usuario.",
+	"help.inventory":       "Muestra tu inventario.",
+	"help.give":            "Da un objeto a otro usuario. La cantidad por defecto es 1.",
+	"help.language":        "Muestra o cambia el idioma en el que te responde Mary. `default` vuelve al idioma del servidor.",
+	"help.language_server": "Cambia el idioma en el que responde Mary a todos los del servidor que no hayan elegido el suyo.",
+	"help.config_show":     "Muestra los ajustes de economía del servidor: pagos, tiempos de espera, saldo inicial y moneda.",
+	"help.export":          "Exporta el saldo, el inventario y el matrimonio de cada usuario en un archivo JSON o CSV.",
+	"help.import":          "Restaura un archivo de `mary export` adjunto al mensaje. `dry-run` solo muestra lo que cambiaría.",
+	"help.config_set":      "Cambia uno de los ajustes de economía del servidor, por ejemplo `mary config set cooldowns.daily 12h`. `default` vuelve al ajuste del bot.",
+	"help.shop":            "Muestra la tienda. Los precios suben cuando se compran objetos, bajan cuando se venden y vuelven a su nivel con el tiempo, y cada día hay ofertas nuevas.",
+	"help.buy":             "Compra el objeto indicado. La cantidad por defecto es 1.",
+	"help.sell":            "Vende el objeto indicado a la tienda por parte de su precio, nunca más que parte de su precio base.",
+	"help.daily":           "Te da %d {coins}.",
+	"help.pay":             "Paga al usuario mencionado la cantidad de {coins} indicada.",
+	"help.top":             "Muestra a todos los usuarios ordenados por saldo, patrimonio, victorias en trivia o ganancias en apuestas, y tu puesto.",
+	"help.top_global":      "Ordena a todos los de la clasificación global por sus estadísticas sumadas en todos los servidores.",
+	"help.global":          "Te une o te saca de la clasificación global. Las {coins} y objetos de cada servidor siguen separados.",
+	"help.profile_global":  "Muestra las estadísticas sumadas en todos los servidores de alguien de la clasificación global.",
+	"help.profile_card":    "Dibuja una tarjeta de perfil con tu avatar, nivel, saldo, insignias y objetos. Temas: pink, dark, mint y sunset.",
+	"help.achievements":    "Muestra los logros que has desbloqueado y los que te faltan. Los desbloqueados aparecen como insignias en tu perfil.",
+	"help.rank":            "Muestra tu nivel y tu XP. Ganas XP charlando y usando comandos de economía, y algunos objetos de la tienda necesitan un nivel más alto.",
+	"help.levelrole":       "Da un rol a los usuarios cuando alcanzan un nivel. Usa `mary levelrole remove [nivel]` para quitarlo, o solo `mary levelrole` para verlos. Necesita Gestionar roles.",
+	"help.trivia":          "Empieza una partida de trivia. Paga %d, %d o %d {coins} al ganar según la dificultad. También puedes apostar para ganar 2X, 3X o 5X tu apuesta.",
+	"help.gamble":          "Apuesta la cantidad de {coins} indicada.",
+	"help.lottery":         "Juega a la lotería con %d {coins}.",
+	"help.slots":           "Juega a la tragaperras con %d {coins}.",
+	"help.use":             "Usa el objeto indicado con el usuario mencionado. Solo puedes usar un objeto a la vez.",
+	"help.eat":             "Te comes un chocolate. ¿Quién sabe? A lo mejor tienes suerte.",
+	"help.runover":         "Atropella al usuario mencionado. Desgasta un poco tu coche.",
+	"help.shoot":           "Dispara al usuario mencionado con la pistola. Si no tienes pistola, usa el arco. Desgasta la pistola o el arco usado.",
+	"help.kill":            "Dispara al usuario mencionado con la pistola. Desgasta tu pistola.",
+	"help.marry":           "Dale un anillo al usuario mencionado. Si te devuelve uno, ¡enhorabuena! ¡Estáis casados!",
+	"help.divorce":         "Divórciate del usuario mencionado. Tienes que estar casado con él o haberle pedido matrimonio. Te devuelve un anillo.",
+	"help.rob":             "Intenta robar al usuario mencionado. Una pistola mejora tus probabilidades y un escudo le protege. Si te pillan, le pagas una multa y vas a la cárcel.",
+	"help.bail":            "Paga tu salida de la cárcel. Cuesta 50 {coins} por cada minuto que te quede de condena.",
+	"help.repair":          "Repara un objeto desgastado o roto. Cuanto más desgastado esté, más cuesta.",
+	"help.recipes":         "Muestra todos los objetos que puedes fabricar y lo que necesitan.",
+	"help.craft":           "Fabrica un objeto con los de tu inventario. La cantidad por defecto es 1.",
+	"help.market":          "Muestra lo que otros jugadores venden y subastan.",
+	"help.market_list":     "Pone objetos a la venta en el mercado durante 24 horas. El mercado guarda los objetos hasta que alguien los compra.",
+	"help.market_auction":  "Subasta objetos al mejor postor.",
+	"help.market_buy":      "Compra un anuncio, puja en una subasta o retira tu propio anuncio.",
+	"help.trade":           "Empieza un intercambio con el usuario mencionado. Los intercambios se cancelan tras 5 minutos sin cambios.",
+	"help.trade_add":       "Añade objetos o {coins} a tu lado del intercambio.",
+	"help.trade_confirm":   "Muestra, confirma o cancela tu intercambio. Cuando los dos lados confirman, todo se intercambia a la vez.",
+
+	// mary language
+	"language.current":       "Estás usando %s. Usa `mary language [código]` para cambiarlo. Idiomas: %s.",
+	"language.set":           "¡Mary ahora te responderá en %s!",
+	"language.cleared":       "Mary ahora te responderá en el idioma del servidor.",
+	"language.server_set":    "Mary ahora responderá en %s en este servidor, salvo a quien haya elegido su propio idioma.",
+	"language.unknown":       "¡No hay ningún idioma con ese nombre! Prueba con %s.",
+	"language.no_permission": "¡Necesitas el permiso Gestionar servidor para cambiar el idioma del servidor!",
+	"language.server_usage":  "¡Indica un código de idioma, por ejemplo `mary language server es`!",
+
+	// mary config
+	"config.title":         "Ajustes del servidor",
+	"config.description":   "Cámbialos con `mary config set [ajuste] [valor]`.",
+	"config.changed":       "%s (cambiado)",
+	"config.empty":         "Ninguno",
+	"config.set":           "¡%s ahora es %s!",
+	"config.reset":         "¡%s vuelve a su valor por defecto, %s!",
+	"config.usage":         "¡Usa `mary config show` o `mary config set [ajuste] [valor]`!",
+	"config.no_permission": "¡Necesitas el permiso Gestionar servidor para cambiar los ajustes del servidor!",
+
+	// mary export y mary import
+	"export.no_permission":  "¡Necesitas el permiso Administrador para exportar o importar la economía del servidor!",
+	"export.usage":          "¡Usa `mary export [opcional: json/csv]`!",
+	"export.success":        "¡Aquí tienes la economía de %s, con %d jugadores!",
+	"export.error":          "¡Ocurrió un error al escribir la exportación! %s",
+	"import.usage":             "¡Adjunta un archivo .json o .csv de `mary export` a `mary import [opcional: dry-run]`!",
+	"import.download_error":    "¡Ocurrió un error al descargar el archivo! %s",
+	"import.too_big":           "¡Ese archivo es demasiado grande para importar, el límite es de %d MB!",
+	"import.invalid":           "Ese archivo no se puede importar, no se ha cambiado nada:%s",
+	"import.more_problems":     "\n...y %d más.",
+	"import.dry_run":           "Prueba: importar añadiría %d jugadores nuevos y reemplazaría el saldo, el inventario y el matrimonio de %d jugadores. No se ha cambiado nada.",
+	"import.success":           "¡Importación hecha! Se han añadido %d jugadores nuevos y reemplazado el saldo, el inventario y el matrimonio de %d jugadores.",
+	"import.dry_run_unmarried": "%d jugadores que no están en el archivo están casados con alguien que sí está, y quedarían solteros.",
+	"import.unmarried":         "%d jugadores que no están en el archivo estaban casados con alguien que sí está, y ahora están solteros.",
+
+	// mary profile
+	"profile.title":      "Perfil",
+	"profile.username":   "Usuario",
+	"profile.balance":    "Saldo",
+	"profile.net_worth":  "Patrimonio",
+	"profile.rank":       "Puesto",
+	"profile.rank_of":    "#%d de %d",
+	"profile.level":      "Nivel",
+	"profile.server":     "Servidor",
+	"profile.married_to": "Casado con",
+	"profile.badges":     "Insignias",
+	"profile.timers":     "Esperas",
+	"profile.no_spouse":  "Nadie",
+	"profile.no_badges":  "Ninguna todavía",
+	"profile.jail":       "🚔 Cárcel: %s",
+	"card.no_theme":      "¡No hay ningún tema con ese nombre! Prueba con %s.",
+	"card.draw_error":    "¡Ocurrió un error al dibujar la tarjeta de perfil! %s",
+
+	// mary global
+	"global.profile_title":      "Perfil global",
+	"global.servers":            "Servidores",
+	"global.not_on_leaderboard": "¡Esa persona no está en la clasificación global! Usa `mary global join` para unirte.",
+	"global.usage":              "¡Indica `join` o `leave`!",
+	"global.nobody":             "¡Nadie se ha unido todavía a la clasificación global! Usa `mary global join` para unirte.",
+	"global.joined":             "¡Te has unido a la clasificación global! Se sumarán tus estadísticas de todos los servidores. Usa `mary global leave` para salir.",
+	"global.left":               "Has salido de la clasificación global. Tus estadísticas en cada servidor no han cambiado.",
+
+	// mary top/leaderboard
+	"leaderboard.title":        "Clasificación - %s",
+	"leaderboard.global_title": "Clasificación global - %s",
+	"leaderboard.your_rank":    "Tu puesto: #%d de %d con %d %s",
+	"leaderboard.not_global":   "No estás en la clasificación global. Usa `mary global join` para unirte.",
+	"leaderboard.rank":         "Puesto",
+	"leaderboard.name":         "Nombre",
+	"leaderboard.nobody":       "¡Todavía no está jugando nadie!",
+	"leaderboard.icon_error":   "¡Error al obtener la imagen del servidor!",
+
+	// mary rank and mary levelrole
+	"rank.title":              "Nivel de %s",
+	"rank.level":              "Nivel",
+	"rank.server_rank":        "Puesto en el servidor",
+	"rank.xp":                 "XP",
+	"rank.progress":           "Progreso",
+	"rank.to_next_level":      "%s %d XP para el nivel %d",
+	"rank.next_reward":        "Próxima recompensa",
+	"rank.reward":             "<@&%s> en el nivel %d",
+	"levelrole.not_positive":  "¡Indica un nivel positivo!",
+	"levelrole.removed":       "Se ha quitado la recompensa del nivel %d.",
+	"levelrole.added":         "¡Los usuarios que alcancen el nivel %d recibirán ahora el rol <@&%s>!",
+	"levelrole.none":          "¡Todavía no hay recompensas por nivel! Usa `mary levelrole [nivel] @rol` para añadir una.",
+	"levelrole.line":          "Nivel %d: <@&%s>",
+	"levelrole.no_permission": "¡Necesitas el permiso Gestionar roles para cambiar las recompensas por nivel!",
+	"levelrole.remove_usage":  "¡Indica el nivel cuya recompensa quieres quitar!",
+	"levelrole.usage":         "¡Indica un nivel y menciona un rol, por ejemplo `mary levelrole 5 @Habitual`!",
+
+	// mary achievements
+	"achievements.title":       "Logros de %s",
+	"achievements.unlocked":    "%d de %d desbloqueados",
+	"achievements.unlocked_on": "Desbloqueado el %s",
+
+	// mary bankrupt and mary quote
+	"bankrupt.no_user": "¡Menciona a un usuario! ¿Intentas arruinarte a ti mismo?",
+	"quote.error":      "¡Error al obtener la cita!",
+
+	// Another command from the same user is still running
+	"busy":                   "%s, espera, tu comando anterior todavía se está ejecutando.",
+
+	// mary bal/daily/beg/gamble/lottery/slots
+	"economy.bal":            "%s, tienes %d {coins}.",
+	"economy.daily":          "%s, ¡has recibido tus %d {coins} diarias! 🔥 Racha: %d días",
+	"economy.beg":            "%s, ¡has recibido %d {coins}!",
+	"economy.negative":       "¡El saldo no puede ser negativo!",
+	"economy.welcome":        "¡Bienvenido a la economía, %s! Empiezas con %d {coins}. Consigue más con `mary daily` y mira todo lo que puedes hacer con `mary help`.",
+	"economy.already_started": "%s, ¡ya estás jugando! Tienes %d {coins}.",
+	"bal.error":              "¡Error al obtener el saldo!",
+	"gamble.cant_afford":     "%s, ¡no tienes suficientes {coins} para apostar tanto!",
+	"gamble.win":             "%s, ¡has ganado! +%d {coins}!",
+	"gamble.lose":            "%s, has perdido. -%d {coins}.",
+	"gamble.no_amount":       "¡Indica una cantidad para apostar!",
+	"gamble.invalid_amount":  "¡Indica una cantidad válida para apostar!",
+	"gamble.negative_amount": "¡Indica una cantidad positiva para apostar!",
+	"gamble.gambling":        "Apostando %s {coins}...",
+	"lottery.limit":          "¡Solo puedes gastar %d {coins} en la lotería!",
+	"slots.limit":            "¡Solo puedes gastar %d {coins} en la tragaperras!",
+
+	// mary trivia
+	"trivia.details":        "Categoría: %s \nDificultad: %s",
+	"trivia.choices":        "Opciones",
+	"trivia.paid":           "%s, ¡te han pagado %d {coins}!",
+	"trivia.invalid_amount": "¡Indica una cantidad válida para apostar!",
+	"trivia.checking":       "Apostando %s {coins}. Comprobando el saldo...",
+	"trivia.wait_error":     "¡Error al esperar la respuesta!",
+	"trivia.timeout":        "¡Se te acabó el tiempo!",
+	"trivia.correct":        "¡Correcto!",
+	"trivia.incorrect":      "¡Incorrecto! La respuesta correcta es %s.",
+
+	// mary rob/pay/bail
+	"rob.self":              "¡No puedes robarte a ti mismo!",
+	"rob.success":           "¡Has robado con éxito %d {coins} a %s!",
+	"rob.caught":            "¡Te han pillado intentando robar a %s! Le has pagado una multa de %d {coins} y te han metido en la cárcel durante %d minutos.",
+	"rob.no_user":           "¡Indica a qué usuario quieres robar!",
+	"pay.self":              "¡No puedes pagarte a ti mismo!",
+	"pay.cant_afford":       "¡No tienes suficiente dinero para pagar esa cantidad!",
+	"pay.success":           "¡Has pagado a <@%d> %d {coins}!",
+	"pay.no_amount":         "¡Indica una cantidad para pagar!",
+	"pay.invalid_amount":    "¡Indica una cantidad válida para pagar!",
+	"pay.negative_amount":   "¡Indica una cantidad positiva para pagar!",
+	"bail.not_jailed":       "%s, ¡no estás en la cárcel!",
+	"bail.cant_afford":      "%s, tu fianza es de %d {coins}, ¡pero solo tienes %d {coins}!",
+	"bail.no_longer_afford": "%s, ¡ya no puedes pagar tu fianza de %d {coins}!",
+	"bail.paid":             "%s, ¡has pagado %d {coins} de fianza y eres libre!",
+
+	// mary shop/inventory/buy/sell/give/repair
+	"shop.title":              "Tienda",
+	"shop.price":              "Precio: %d {coins}\n%s",
+	"shop.deal":               "🏷️ ¡Oferta del día! %d%% de descuento",
+	"shop.history":            "Precios recientes: %s",
+	"shop.durability":         "Durabilidad: %d usos",
+	"shop.min_level":          "🔒 Requiere el nivel %d",
+	"inventory.title":         "Inventario de %s",
+	"inventory.quantity":      "Cantidad: %d",
+	"inventory.broken":        "Durabilidad: Roto",
+	"inventory.durability":    "Durabilidad: %d/%d",
+	"buy.cant_afford":         "¡No tienes suficiente dinero para comprar este objeto!",
+	"buy.success":             "¡Has comprado %dX %s por %d {coins}!",
+	"buy.no_item":             "¡Indica qué objeto quieres comprar!",
+	"sell.not_enough":         "¡No tienes suficientes unidades de ese objeto para venderlas!",
+	"sell.broken":             "¡La tienda no compra un %[1]s roto! Usa `mary repair %[1]s` para arreglarlo primero.",
+	"sell.worn":               "¡Tu último %[1]s está desgastado y la tienda no lo compra! Usa `mary repair %[1]s` para arreglarlo primero.",
+	"sell.success":            "¡Has vendido %dX %s por %d {coins}!",
+	"sell.no_item":            "¡Indica qué objeto quieres vender!",
+	"give.not_playing":        "¡El usuario al que intentas dar un objeto no está jugando!",
+	"give.not_enough":         "¡No tienes suficientes unidades de este objeto para darlas!",
+	"give.broken":             "¡No puedes regalar un %[1]s roto! Usa `mary repair %[1]s` para arreglarlo primero.",
+	"give.worn":               "¡Tu último %[1]s está desgastado y no se puede regalar! Usa `mary repair %[1]s` para arreglarlo primero.",
+	"give.not_found":          "¡No tienes este objeto en tu inventario!",
+	"give.success":            "¡Le has dado %dX %s a <@%d>!",
+	"give.no_user":            "¡Indica a qué usuario quieres dar el objeto!",
+	"give.no_item":            "¡Indica qué objeto quieres dar!",
+	"give.self":               "¡No puedes darte un objeto a ti mismo!",
+	"repair.not_needed":       "¡Tu %s no necesita reparación!",
+	"repair.cant_afford":      "Reparar tu %s cuesta %d {coins}, ¡pero solo tienes %d {coins}!",
+	"repair.no_longer_afford": "¡Ya no tienes suficientes {coins} para reparar tu %s!",
+	"repair.success":          "¡Has reparado tu %s por %d {coins}! Está como nuevo.",
+	"repair.no_item":          "¡Indica qué objeto quieres reparar!",
+
+	// mary recipes/craft
+	"recipes.title":       "Recetas",
+	"recipes.description": "Usa `mary craft [objeto] [opcional: cantidad]` para fabricar un objeto.",
+	"recipes.needs":       "Necesita: %s\n%s",
+	"craft.not_positive":  "¡Indica una cantidad positiva para fabricar!",
+	"craft.missing":       "¡No tienes suficientes ingredientes! Necesitas %s.",
+	"craft.success":       "¡Has fabricado %dX %s con %s!",
+	"craft.no_item":       "¡Indica qué objeto quieres fabricar!",
+
+	// mary use and its synonyms
+	"use.not_playing":     "¡Ese usuario no está jugando ahora mismo!",
+	"use.ate":             "Te has comido un chocolate. ¡Qué rico!",
+	"use.jackpot":         "¡Has encontrado un billete dorado! ¡Has ganado %d {coins}!",
+	"use.target_broke":    "Has atropellado a <@%d> con tu coche, ¡pero no tenía dinero suficiente para pagarte!",
+	"use.ran_over":        "¡Has atropellado a <@%d> con tu coche y le has quitado %d {coins}!",
+	"use.blocked":         "Has disparado a <@%d> con tu pistola, ¡pero tenía un escudo y bloqueó la bala!",
+	"use.robbed":          "¡Has disparado a <@%d> y le has quitado %d {coins}!",
+	"use.robbed.crossbow": "¡Has disparado a <@%d> con tu ballesta y le has quitado %d {coins}!",
+	"use.robbed.gun":      "¡Has atracado a <@%d> a punta de pistola y le has robado %d {coins}!",
+	"use.shot_back":       "Intentaste robar a <@%d> con un arco, ¡pero tenía una pistola y te disparó! ¡Has perdido %d {coins}!",
+	"use.married":         "🎉 ¡Enhorabuena! ¡<@%d> y tú ya estáis casados oficialmente! 🎉",
+	"use.proposed":        "¡Le has pedido matrimonio a <@%d> con un anillo! ¡Ahora tiene que aceptar usando su propio anillo!",
+	"use.no_item":         "¡Indica qué objeto quieres usar!",
+	"use.self.car":        "¡No puedes atropellarte a ti mismo!",
+	"use.self.rob":        "¡No puedes robarte a ti mismo!",
+	"use.self.marry":      "¡No puedes casarte contigo mismo!",
+	"target.missing":      "¡Indica un objetivo!",
+	"target.invalid":      "¡Indica un objetivo válido!",
+	"eat.no_item":         "¡Indica qué quieres comer!",
+	"eat.cant":            "¡No puedes comerte eso!",
+	"divorce.official":    "Los papeles se han tramitado. <@%d> y tú ya estáis divorciados oficialmente...",
+	"divorce.filed":       "¡Has pedido el divorcio a <@%d>! Ahora tiene que firmar los papeles para completarlo.",
+
+	// mary market
+	"market.title":           "Mercado",
+	"market.description":     "Usa `mary market buy [anuncio]` para comprar o `mary market bid [anuncio] [cantidad]` para pujar.",
+	"market.price":           "Precio: %d {coins}",
+	"market.highest_bid":     "Puja más alta: %d {coins} de <@%d>",
+	"market.starting_bid":    "Puja inicial: %d {coins}",
+	"market.seller":          "Vendedor: %s\nTermina en %s",
+	"market.listing":         "Anuncio #%d: %dX %s",
+	"market.auction":         "Subasta #%d: %dX %s",
+	"market.list.quantity":   "¡Indica una cantidad positiva para vender!",
+	"market.list.price":      "¡Indica un precio positivo!",
+	"market.list.length":     "¡Las subastas deben durar entre 1 minuto y %d días!",
+	"market.list.broken":     "¡No puedes vender un %[1]s roto! Usa `mary repair %[1]s` para arreglarlo primero.",
+	"market.list.worn":       "¡Tu último %[1]s está desgastado y no se puede vender! Usa `mary repair %[1]s` para arreglarlo primero.",
+	"market.list.not_enough": "¡No tienes suficientes unidades de ese objeto para venderlas!",
+	"market.list.auction":    "¡Has puesto %dX %s en subasta como anuncio #%d! Las pujas empiezan en %d {coins} y terminan en %s.",
+	"market.list.listing":    "¡Has puesto a la venta %dX %s por %d {coins} como anuncio #%d! Caducará en %d horas.",
+	"market.list.usage":      "¡Usa `mary market list [objeto] [cantidad] [precio]`!",
+	"market.auction.usage":   "¡Usa `mary market auction [objeto] [cantidad] [precio inicial] [minutos]`!",
+	"market.buy.auction":     "¡Ese anuncio es una subasta! Usa `mary market bid %d [cantidad]` en su lugar.",
+	"market.buy.own":         "¡No puedes comprar tu propio anuncio! Usa `mary market cancel %d` para retirarlo.",
+	"market.buy.cant_afford": "¡No tienes suficientes {coins} para comprar ese anuncio!",
+	"market.buy.success":     "¡Has comprado %dX %s a %s por %d {coins}!",
+	"market.buy.usage":       "¡Indica el número del anuncio que quieres comprar!",
+	"market.bid.not_auction": "¡Ese anuncio no es una subasta! Usa `mary market buy %d` en su lugar.",
+	"market.bid.own":         "¡No puedes pujar en tu propia subasta!",
+	"market.bid.cant_afford": "¡No tienes suficientes {coins} para pujar tanto!",
+	"market.bid.outbid":      "¡Has pujado %d {coins} en la subasta #%d y has superado a <@%d>! La subasta termina en %s.",
+	"market.bid.success":     "¡Has pujado %d {coins} en la subasta #%d! La subasta termina en %s.",
+	"market.bid.usage":       "¡Usa `mary market bid [anuncio] [cantidad]`!",
+	"market.cancel.success":  "Has retirado el anuncio #%d y has recuperado tus %dX %s.",
+	"market.cancel.usage":    "¡Indica el número del anuncio que quieres cancelar!",
+	"market.unknown_command": "Lo siento, no reconozco ese comando del mercado.",
+
+	// mary trade
+	"trade.self":            "¡No puedes intercambiar contigo mismo!",
+	"trade.not_positive":    "¡Indica una cantidad positiva para añadir!",
+	"trade.cant_afford":     "¡No tienes suficientes {coins} para ofrecer tanto!",
+	"trade.not_enough":      "¡No tienes suficientes unidades de ese objeto para ofrecerlas!",
+	"trade.broken":          "¡No puedes intercambiar un %[1]s roto! Usa `mary repair %[1]s` para arreglarlo primero.",
+	"trade.worn":            "¡Tu último %[1]s está desgastado y no se puede intercambiar! Usa `mary repair %[1]s` para arreglarlo primero.",
+	"trade.failed":          "No se pudo completar el intercambio. %s",
+	"trade.short_items":     "¡%s ya no tiene %dX %s!",
+	"trade.short_coins":     "¡%s ya no tiene %d {coins}!",
+	"trade.unknown_command": "Lo siento, no reconozco ese comando de intercambio.",
+	"trade.open":            "<@%[1]d>, ¡<@%[2]d> quiere hacer un intercambio contigo!",
+	"trade.confirm":         "¡<@%d> ha confirmado el intercambio! Esperando al otro lado...",
+	"trade.complete":        "🤝 ¡El intercambio entre <@%d> y <@%d> se ha completado!",
+	"trade.cancel":          "El intercambio entre <@%d> y <@%d> se ha cancelado.",
+	"trade.nothing":         "Nada todavía",
+	"trade.confirmed":       "✅ Confirmado",
+	"trade.title":           "Intercambio",
+	"trade.description":     "Usa `mary trade add [objeto] [opcional: cantidad]` o `mary trade add coins [cantidad]` para añadir a tu oferta, y luego `mary trade confirm`.",
+	"trade.expires":         "Caduca en %s sin cambios",
+	"trade.add_usage":       "¡Indica qué objeto o cuántas {coins} quieres añadir!",
+	"trade.no_user":         "¡Menciona al usuario con el que quieres intercambiar!",
+}
@@ -0,0 +1,111 @@
+package replies
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"mary-bot/config"
+)
+
+// The language used when a user and their server haven't picked one
+const DefaultLanguage = "en"
+
+// Every bundled language's messages, by language code
+// English has every key, the others fall back to it for anything they're missing
+var catalogs = map[string]map[string]string{
+	"en": english,
+	"es": spanish,
+}
+
+// What each language is called in itself, for `mary language`
+var languageNames = map[string]string{
+	"en": "English",
+	"es": "Español",
+}
+
+// Writes messages in one language
+// Currency is what the server calls its coins, which fills in {coins} in every message
+type Printer struct {
+	Language string
+	Currency config.Currency
+}
+
+// Gets a printer for a language code, or for English if there's no such language
+func For(language string) (Printer) {
+	language = strings.ToLower(language)
+	if _, ok := catalogs[language]; !ok {
+		language = DefaultLanguage
+	}
+	return Printer{Language: language}
+}
+
+// Whether there's a bundled language with this code
+func IsLanguage(language string) (bool) {
+	_, ok := catalogs[strings.ToLower(language)]
+	return ok
+}
+
+// What a language is called in itself, e.g. "Español" for "es"
+func LanguageName(language string) (string) {
+	return languageNames[For(language).Language]
+}
+
+// Every language code with its name, e.g. "`en` (English), `es` (Español)"
+func LanguageNames() (string) {
+	codes := []string{}
+	for code := range catalogs {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	names := []string{}
+	for _, code := range codes {
+		names = append(names, "`" + code + "` (" + languageNames[code] + ")")
+	}
+	return strings.Join(names, ", ")
+}
+
+// The message for a key, filled in with args
+// Falls back to English if the language doesn't have the key, and to the key itself if English doesn't either
+func (printer Printer) Text(key string, args ...interface{}) (string) {
+	template, ok := printer.lookup(key)
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return strings.ReplaceAll(template, "{coins}", printer.currency())
+	}
+	// Escaped so a % in the currency's name isn't read as a verb
+	template = strings.ReplaceAll(template, "{coins}", strings.ReplaceAll(printer.currency(), "%", "%%"))
+	return fmt.Sprintf(template, args...)
+}
+
+// What the server calls its coins, with its emoji if it has one, e.g. "ED 💎"
+// The bot's own default name is translated, names servers pick aren't
+func (printer Printer) currency() (string) {
+	name := printer.Currency.Name
+	if name == "" || name == config.Default().Currency.Name {
+		name, _ = printer.lookup("currency.name")
+	}
+	if printer.Currency.Emoji != "" {
+		return name + " " + printer.Currency.Emoji
+	}
+	return name
+}
+
+// Helper to find the template for a key, falling back to English
+func (printer Printer) lookup(key string) (string, bool) {
+	if template, ok := catalogs[printer.Language][key]; ok {
+		return template, true
+	}
+	template, ok := catalogs[DefaultLanguage][key]
+	return template, ok
+}
+
+// The message for a key if there is one, otherwise fallback
+// Used for names that come from the game's data, which only some languages translate
+func (printer Printer) textOr(key string, fallback string) (string) {
+	if template, ok := printer.lookup(key); ok {
+		return template
+	}
+	return fallback
+}
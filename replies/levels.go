@@ -0,0 +1,139 @@
+package replies
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	database "mary-bot/database"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// mary rank -> the user's level, XP and how close they are to the next level
+func (printer Printer) Rank(userName string, card database.RankCard) (*discordgo.MessageEmbed) {
+	// Progress through the current level as a bar, e.g. ▰▰▰▱▱▱▱▱▱▱
+	filled := int((card.XP - card.LevelXP) * 10 / (card.NextLevelXP - card.LevelXP))
+	if filled < 0 {
+		filled = 0
+	} else if filled > 10 {
+		filled = 10
+	}
+	bar := strings.Repeat("▰", filled) + strings.Repeat("▱", 10 - filled)
+
+	embed := &discordgo.MessageEmbed{
+		Title: printer.Text("rank.title", userName),
+		Color: 0xffc0cb,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name: printer.Text("rank.level"),
+				Value: strconv.Itoa(card.Level),
+				Inline: true,
+			},
+			{
+				Name: printer.Text("rank.server_rank"),
+				Value: "#" + strconv.Itoa(card.Rank),
+				Inline: true,
+			},
+			{
+				Name: printer.Text("rank.xp"),
+				Value: strconv.FormatInt(card.XP, 10) + " / " + strconv.FormatInt(card.NextLevelXP, 10),
+				Inline: true,
+			},
+			{
+				Name: printer.Text("rank.progress"),
+				Value: printer.Text("rank.to_next_level", bar, card.NextLevelXP - card.XP, card.Level + 1),
+				Inline: false,
+			},
+		},
+	}
+
+	// Show the next role reward, if there is one
+	if card.NextReward != nil {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name: printer.Text("rank.next_reward"),
+			Value: printer.Text("rank.reward", card.NextReward.RoleID, card.NextReward.Level),
+			Inline: false,
+		})
+	}
+	return embed
+}
+
+// mary levelrole [level] @role / mary levelrole remove [level] / mary levelrole
+// level is 0 when the rewards are only being listed
+func (printer Printer) LevelRoles(userID int, level int, roleID string, remove bool, roles []database.LevelRole, err error) (string) {
+	if errors.Is(err, database.ErrNotPositive) {
+		return printer.Text("levelrole.not_positive")
+	} else if err != nil {
+		return printer.Error(userID, err)
+	}
+
+	if remove {
+		return printer.Text("levelrole.removed", level)
+	} else if level > 0 {
+		return printer.Text("levelrole.added", level, roleID)
+	}
+
+	if len(roles) == 0 {
+		return printer.Text("levelrole.none")
+	}
+	lines := []string{}
+	for _, role := range roles {
+		lines = append(lines, printer.Text("levelrole.line", role.Level, role.RoleID))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// mary achievements -> which achievements the user has unlocked, and when
+func (printer Printer) Achievements(userName string, progress []database.AchievementProgress) (*discordgo.MessageEmbed) {
+	unlocked := 0
+	for _, achievement := range progress {
+		if achievement.Unlocked {
+			unlocked++
+		}
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: printer.Text("achievements.title", userName),
+		Description: printer.Text("achievements.unlocked", unlocked, len(progress)),
+		Color: 0xffc0cb,
+	}
+	for _, achievement := range progress {
+		name := "🔒 " + printer.achievementName(achievement.Achievement)
+		value := printer.achievementDescription(achievement.Achievement)
+		if achievement.Unlocked {
+			name = achievement.Achievement.Emoji + " " + printer.achievementName(achievement.Achievement)
+			value += "\n" + printer.Text("achievements.unlocked_on", achievement.UnlockedAt.Format(printer.Text("date_format")))
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name: name,
+			Value: value,
+			Inline: false,
+		})
+	}
+	return embed
+}
+
+// Helpers to get an achievement's name and description in the printer's language
+func (printer Printer) achievementName(achievement database.Achievement) (string) {
+	return printer.textOr("achievement." + achievement.ID + ".name", achievement.Name)
+}
+
+func (printer Printer) achievementDescription(achievement database.Achievement) (string) {
+	return printer.textOr("achievement." + achievement.ID + ".description", achievement.Description)
+}
+
+// mary global join
+func (printer Printer) GlobalJoin(userID int, err error) (string) {
+	if err != nil {
+		return printer.Error(userID, err)
+	}
+	return printer.Text("global.joined")
+}
+
+// mary global leave
+func (printer Printer) GlobalLeave(userID int, err error) (string) {
+	if err != nil {
+		return printer.Error(userID, err)
+	}
+	return printer.Text("global.left")
+}
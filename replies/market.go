@@ -0,0 +1,116 @@
+package replies
+
+import (
+	"errors"
+	"time"
+	database "mary-bot/database"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// mary market list/auction
+func (printer Printer) MarketList(userID int, quantity int, res database.MarketResult, err error) (string) {
+	var auctionLength database.ErrAuctionLength
+	var broken database.ErrItemBroken
+	var worn database.ErrItemWorn
+	if errors.Is(err, database.ErrNotPositive) && quantity < 1 {
+		return printer.Text("market.list.quantity")
+	} else if errors.Is(err, database.ErrNotPositive) {
+		return printer.Text("market.list.price")
+	} else if errors.As(err, &auctionLength) {
+		return printer.Text("market.list.length", int(auctionLength.Max.Hours() / 24))
+	} else if errors.As(err, &broken) {
+		return printer.Text("market.list.broken", broken.Item)
+	} else if errors.As(err, &worn) {
+		return printer.Text("market.list.worn", worn.Item)
+	} else if errors.Is(err, database.ErrNotEnoughItems) {
+		return printer.Text("market.list.not_enough")
+	} else if err != nil {
+		return printer.Error(userID, err)
+	}
+
+	duration := res.ExpiresAt.Sub(res.CreatedAt)
+	if res.Auction {
+		return printer.Text("market.list.auction", res.Quantity, res.Item, res.ListingID, res.Price, printer.formatDuration(duration))
+	}
+	return printer.Text("market.list.listing", res.Quantity, res.Item, res.Price, res.ListingID, int(duration.Hours()))
+}
+
+// mary market [optional: page number]
+func (printer Printer) MarketPage(page database.MarketPage) (*discordgo.MessageEmbed) {
+	embed := &discordgo.MessageEmbed{
+		Title: printer.Text("market.title"),
+		Description: printer.Text("market.description"),
+		Color: 0xffc0cb,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: printer.Text("page", page.Page+1, page.TotalPages),
+		},
+	}
+
+	// Add a field for each listing on the page
+	for _, listing := range page.Listings {
+		value := printer.Text("market.price", listing.Price)
+		if listing.Auction && listing.HighestBidderID != 0 {
+			value = printer.Text("market.highest_bid", listing.HighestBid, listing.HighestBidderID)
+		} else if listing.Auction {
+			value = printer.Text("market.starting_bid", listing.Price)
+		}
+		value += "\n" + printer.Text("market.seller", listing.SellerName, printer.formatDuration(time.Until(listing.ExpiresAt)))
+
+		kind := "market.listing"
+		if listing.Auction {
+			kind = "market.auction"
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name: printer.Text(kind, listing.ListingID, listing.Quantity, database.ItemDisplayName(listing.Item)),
+			Value: value,
+			Inline: false,
+		})
+	}
+	return embed
+}
+
+// mary market buy [listing]
+func (printer Printer) MarketBuy(userID int, res database.MarketResult, err error) (string) {
+	var wrongType database.ErrWrongListingType
+	var own database.ErrOwnListing
+	if errors.As(err, &wrongType) {
+		return printer.Text("market.buy.auction", wrongType.ListingID)
+	} else if errors.As(err, &own) {
+		return printer.Text("market.buy.own", own.ListingID)
+	} else if errors.Is(err, database.ErrInsufficientFunds) {
+		return printer.Text("market.buy.cant_afford")
+	} else if err != nil {
+		return printer.Error(userID, err)
+	}
+	return printer.Text("market.buy.success", res.Quantity, res.Item, res.SellerName, res.Price) + printer.Announcements(res.Announcements)
+}
+
+// mary market bid [listing] [amount]
+func (printer Printer) MarketBid(userID int, res database.MarketResult, err error) (string) {
+	var wrongType database.ErrWrongListingType
+	var own database.ErrOwnListing
+	if errors.As(err, &wrongType) {
+		return printer.Text("market.bid.not_auction", wrongType.ListingID)
+	} else if errors.As(err, &own) {
+		return printer.Text("market.bid.own")
+	} else if errors.Is(err, database.ErrInsufficientFunds) {
+		return printer.Text("market.bid.cant_afford")
+	} else if err != nil {
+		return printer.Error(userID, err)
+	}
+
+	endsIn := printer.formatDuration(time.Until(res.ExpiresAt))
+	if res.OutbidID != 0 {
+		return printer.Text("market.bid.outbid", res.Bid, res.ListingID, res.OutbidID, endsIn)
+	}
+	return printer.Text("market.bid.success", res.Bid, res.ListingID, endsIn)
+}
+
+// mary market cancel [listing]
+func (printer Printer) MarketCancel(userID int, res database.MarketResult, err error) (string) {
+	if err != nil {
+		return printer.Error(userID, err)
+	}
+	return printer.Text("market.cancel.success", res.ListingID, res.Quantity, res.Item)
+}
@@ -0,0 +1,180 @@
+package replies
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"mary-bot/config"
+	database "mary-bot/database"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// mary profile [@user] -> the user's profile and cooldowns
+func (printer Printer) Profile(profile database.Profile, avatarURL string) (*discordgo.MessageEmbed) {
+	spouse := profile.Spouse
+	if spouse == "" {
+		spouse = printer.Text("profile.no_spouse")
+	}
+	badges := strings.Join(profile.Badges, " ")
+	if badges == "" {
+		badges = printer.Text("profile.no_badges")
+	}
+
+	// One line per cooldown, e.g. "Daily: 3h 12m 5s"
+	timers := []string{}
+	if profile.JailedFor > 0 {
+		timers = append(timers, printer.Text("profile.jail", printer.countdown(profile.JailedFor)))
+	}
+	for _, timer := range profile.Timers {
+		timers = append(timers, printer.textOr("timer." + timer.Name, timer.Name) + ": " + printer.countdown(timer.Remaining))
+	}
+
+	return &discordgo.MessageEmbed{
+		Title: printer.Text("profile.title"),
+		Thumbnail: &discordgo.MessageEmbedThumbnail{
+			URL: avatarURL,
+		},
+		Color: 0xffc0cb,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name: printer.Text("profile.username"),
+				Value: profile.UserName,
+				Inline: true,
+			},
+			{
+				Name: printer.Text("profile.balance"),
+				Value: printer.Text("coins", profile.Balance),
+				Inline: true,
+			},
+			{
+				Name: printer.Text("profile.net_worth"),
+				Value: printer.Text("coins", profile.NetWorth),
+				Inline: true,
+			},
+			{
+				Name: printer.Text("profile.rank"),
+				Value: printer.Text("profile.rank_of", profile.Rank, profile.Players),
+				Inline: true,
+			},
+			{
+				Name: printer.Text("profile.level"),
+				Value: strconv.Itoa(profile.Level),
+				Inline: true,
+			},
+			{
+				Name: printer.Text("profile.server"),
+				Value: profile.ServerName,
+				Inline: true,
+			},
+			{
+				Name: printer.Text("profile.married_to"),
+				Value: spouse,
+				Inline: true,
+			},
+			{
+				Name: printer.Text("profile.badges"),
+				Value: badges,
+				Inline: true,
+			},
+			{
+				Name: printer.Text("profile.timers"),
+				Value: strings.Join(timers, "\n"),
+				Inline: false,
+			},
+		},
+	}
+}
+
+// Helper to show a countdown, e.g. "3h 12m 5s", or that it's ready
+func (printer Printer) countdown(d time.Duration) (string) {
+	if d <= 0 {
+		return printer.Text("timer.ready")
+	}
+	return database.FormatCountdown(d)
+}
+
+// mary profile global [@user] -> the user's stats added up across every server
+func (printer Printer) GlobalProfile(profile database.GlobalProfile, avatarURL string) (*discordgo.MessageEmbed) {
+	// Servers can call their coins different things, so totals use the default name
+	printer.Currency = config.Currency{}
+	embed := &discordgo.MessageEmbed{
+		Title: printer.Text("global.profile_title"),
+		Thumbnail: &discordgo.MessageEmbedThumbnail{
+			URL: avatarURL,
+		},
+		Color: 0xffc0cb,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name: printer.Text("profile.username"),
+				Value: profile.UserName,
+				Inline: true,
+			},
+			{
+				Name: printer.Text("global.servers"),
+				Value: strconv.Itoa(profile.Servers),
+				Inline: true,
+			},
+		},
+	}
+	for _, ranking := range database.Rankings() {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name: printer.rankingTitle(ranking),
+			Value: strconv.FormatInt(profile.Scores[ranking.Name], 10) + " " + printer.rankingUnit(ranking),
+			Inline: true,
+		})
+	}
+	return embed
+}
+
+// mary top/leaderboard [global] [ranking] -> one page of the leaderboard, three fields per user
+func (printer Printer) Leaderboard(page database.LeaderboardPage, global bool) (*discordgo.MessageEmbed) {
+	if global {
+		// Servers can call their coins different things, so totals use the default name
+		printer.Currency = config.Currency{}
+	}
+	embed := &discordgo.MessageEmbed{
+		Title: printer.Text("leaderboard.title", printer.rankingTitle(page.Ranking)),
+		Color: 0xffc0cb,
+	}
+	if global {
+		embed.Title = printer.Text("leaderboard.global_title", printer.rankingTitle(page.Ranking))
+	}
+	if page.Caller != nil {
+		embed.Description = printer.Text("leaderboard.your_rank", page.Caller.Rank, page.Total, page.Caller.Score, printer.rankingUnit(page.Ranking))
+	} else if global {
+		embed.Description = printer.Text("leaderboard.not_global")
+	}
+
+	// Use the entries to create fields
+	for _, entry := range page.Entries {
+		embed.Fields = append(embed.Fields, []*discordgo.MessageEmbedField{
+			{
+				Name: printer.Text("leaderboard.rank"),
+				Value: strconv.Itoa(entry.Rank),
+				Inline: true,
+			},{
+				Name: printer.Text("leaderboard.name"),
+				Value: entry.Name,
+				Inline: true,
+			},{
+				Name: printer.rankingTitle(page.Ranking),
+				Value: strconv.FormatInt(entry.Score, 10),
+				Inline: true,
+			},
+		}...)
+	}
+	return embed
+}
+
+// Helpers to get a ranking's title and unit in the printer's language
+func (printer Printer) rankingTitle(ranking database.Ranking) (string) {
+	return printer.textOr("ranking." + ranking.Name + ".title", ranking.Title)
+}
+
+func (printer Printer) rankingUnit(ranking database.Ranking) (string) {
+	if ranking.Unit == "coins" {
+		return printer.currency()
+	}
+	return printer.textOr("ranking." + ranking.Name + ".unit", ranking.Unit)
+}
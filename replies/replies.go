@@ -0,0 +1,177 @@
+// Package replies turns what the database package returns into the messages Mary sends
+// Every user-facing string lives in the message catalogs here, so the wording can change (or be translated) without touching the database code
+package replies
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	database "mary-bot/database"
+)
+
+// Keys for errors that read the same whichever command caused them
+var errorKeys = map[error]string{
+	database.ErrNotPlaying:           "error.not_playing",
+	database.ErrInsufficientFunds:    "error.insufficient_funds",
+	database.ErrItemNotFound:         "error.item_not_found",
+	database.ErrNotEnoughItems:       "error.not_enough_items",
+	database.ErrEmptyInventory:       "error.empty_inventory",
+	database.ErrNoSuchItem:           "error.no_such_item",
+	database.ErrNotPositive:          "error.not_positive",
+	database.ErrSelfTarget:           "error.self_target",
+	database.ErrUnknownCommand:       "error.unknown_command",
+	database.ErrAlreadyMarried:       "error.already_married",
+	database.ErrTargetMarried:        "error.target_married",
+	database.ErrNotMarried:           "error.not_married",
+	database.ErrNotMarriedToTarget:   "error.not_married_to_target",
+	database.ErrTooPoor:              "error.too_poor",
+	database.ErrCantRepair:           "error.cant_repair",
+	database.ErrNoRecipe:             "error.no_recipe",
+	database.ErrMarketEmpty:          "error.market_empty",
+	database.ErrListingNotFound:      "error.listing_not_found",
+	database.ErrAuctionNotFound:      "error.auction_not_found",
+	database.ErrAlreadyHighestBidder: "error.already_highest_bidder",
+	database.ErrBidRace:              "error.bid_race",
+	database.ErrCantCancel:           "error.cant_cancel",
+	database.ErrAlreadyTrading:       "error.already_trading",
+	database.ErrNoTrade:              "error.no_trade",
+	database.ErrTradeChanged:         "error.trade_changed",
+	database.ErrNoTransactions:       "error.no_transactions",
+	database.ErrAlreadyGlobal:        "error.already_global",
+	database.ErrNotGlobal:            "error.not_global",
+	database.ErrTriviaUnavailable:    "error.trivia_unavailable",
+}
+
+// The message for an error from the database package
+// Commands that word an error differently check for it before falling back to this
+func (printer Printer) Error(userID int, err error) (string) {
+	mention := "<@" + strconv.Itoa(userID) + ">"
+
+	var dbErr database.ErrDatabase
+	if errors.As(err, &dbErr) {
+		return printer.Text("error.database", printer.textOr("op." + dbErr.Op, dbErr.Op), strings.Title(dbErr.Err.Error()))
+	}
+	var jailed database.ErrJailed
+	if errors.As(err, &jailed) {
+		return printer.Text("error.jailed", mention, printer.formatDuration(jailed.Remaining))
+	}
+	var cooldown database.ErrCooldown
+	if errors.As(err, &cooldown) {
+		return printer.cooldownMessage(mention, cooldown)
+	}
+	var broken database.ErrItemBroken
+	if errors.As(err, &broken) {
+		return printer.Text("error.item_broken", broken.Item)
+	}
+	var levelTooLow database.ErrLevelTooLow
+	if errors.As(err, &levelTooLow) && levelTooLow.UserID != 0 && levelTooLow.UserID != userID {
+		return printer.Text("error.level_too_low_other", levelTooLow.UserID, levelTooLow.Level)
+	} else if errors.As(err, &levelTooLow) {
+		return printer.Text("error.level_too_low", levelTooLow.Level)
+	}
+	var bidTooLow database.ErrBidTooLow
+	if errors.As(err, &bidTooLow) {
+		return printer.Text("error.bid_too_low", bidTooLow.Minimum)
+	}
+	var targetTrading database.ErrTargetTrading
+	if errors.As(err, &targetTrading) {
+		return printer.Text("error.target_trading", targetTrading.UserID)
+	}
+	var noLevelRole database.ErrNoLevelRole
+	if errors.As(err, &noLevelRole) {
+		return printer.Text("error.no_level_role", noLevelRole.Level)
+	}
+	if errors.Is(err, database.ErrNoSuchRanking) {
+		return printer.Text("error.no_such_ranking", database.RankingNames())
+	}
+	var invalidSetting database.ErrInvalidSetting
+	if errors.As(err, &invalidSetting) {
+		return printer.Text("error.invalid_setting", invalidSetting.Key, invalidSetting.Reason)
+	}
+	if errors.Is(err, database.ErrNoSuchSetting) {
+		return printer.Text("error.no_such_setting", settingNames())
+	}
+
+	for sentinel, key := range errorKeys {
+		if errors.Is(err, sentinel) {
+			return printer.Text(key)
+		}
+	}
+	return printer.Text("error.unknown", strings.Title(err.Error()))
+}
+
+// Helper to word a cooldown for the command that's on it
+func (printer Printer) cooldownMessage(mention string, cooldown database.ErrCooldown) (string) {
+	seconds := int(math.Ceil(cooldown.Remaining.Seconds()))
+	switch cooldown.Action {
+	case "daily":
+		return printer.Text("cooldown.daily", mention, seconds / 3600, (seconds % 3600) / 60, seconds % 60)
+	case "beg":
+		return printer.Text("cooldown.beg", mention, seconds)
+	case "gamble":
+		return printer.Text("cooldown.gamble", mention)
+	case "trivia":
+		return printer.Text("cooldown.trivia", mention)
+	case "rob":
+		return printer.Text("cooldown.rob", printer.formatDuration(cooldown.Remaining))
+	case "use":
+		return printer.Text("cooldown.use", printer.formatDuration(cooldown.Remaining))
+	}
+	return printer.Text("cooldown.other", printer.formatDuration(cooldown.Remaining))
+}
+
+// The lines announcing achievements and level ups, each starting on a new line
+// Appended to the message for the command that caused them
+func (printer Printer) Announcements(announcements database.Announcements) (string) {
+	text := ""
+	for _, unlock := range announcements.Unlocks {
+		achievement := unlock.Achievement
+		text += "\n" + printer.Text("announce.achievement", unlock.UserID, achievement.Emoji, printer.achievementName(achievement), printer.achievementDescription(achievement))
+	}
+	for _, levelUp := range announcements.LevelUps {
+		text += "\n" + printer.LevelUp(levelUp)
+	}
+	return text
+}
+
+// Announces a user reaching a new level, along with any roles they were given
+func (printer Printer) LevelUp(levelUp database.LevelUp) (string) {
+	text := printer.Text("announce.level_up", levelUp.UserID, levelUp.Level)
+	for _, roleID := range levelUp.RoleIDs {
+		text += printer.Text("announce.level_role", roleID)
+	}
+	return text
+}
+
+// Helper to show a duration as minutes and seconds
+func (printer Printer) formatDuration(duration time.Duration) (string) {
+	seconds := int(math.Ceil(duration.Seconds()))
+	if seconds < 0 {
+		seconds = 0
+	}
+	return printer.Text("duration", seconds / 60, seconds % 60)
+}
+
+// Helper to draw recent prices as a tiny bar chart, e.g. ▂▃▅▇█
+func sparkline(history []int) (string) {
+	bars := []rune("▁▂▃▄▅▆▇█")
+	if len(history) == 0 {
+		return ""
+	}
+	low, high := history[0], history[0]
+	for _, price := range history {
+		low = int(math.Min(float64(low), float64(price)))
+		high = int(math.Max(float64(high), float64(price)))
+	}
+	var line strings.Builder
+	for _, price := range history {
+		bar := 0
+		if high > low {
+			bar = (price - low) * (len(bars) - 1) / (high - low)
+		}
+		line.WriteRune(bars[bar])
+	}
+	return line.String()
+}
@@ -0,0 +1,54 @@
+package replies
+
+import (
+	"strings"
+	"mary-bot/config"
+	"github.com/bwmarrin/discordgo"
+)
+
+// mary config show
+// Every setting the server can change, marking the ones that differ from the bot's config
+func (printer Printer) Settings(settings config.Config) (*discordgo.MessageEmbed) {
+	embed := &discordgo.MessageEmbed{
+		Title: printer.Text("config.title"),
+		Description: printer.Text("config.description"),
+		Color: 0xffc0cb,
+	}
+	for _, key := range config.GuildKeys() {
+		value := printer.settingValue(settings, key)
+		if settings.Get(key) != config.Current.Get(key) {
+			value = printer.Text("config.changed", value)
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name: key,
+			Value: value,
+			Inline: true,
+		})
+	}
+	return embed
+}
+
+// mary config set [setting] [value]
+func (printer Printer) SettingChanged(settings config.Config, key string, reset bool) (string) {
+	if reset {
+		return printer.Text("config.reset", "`" + key + "`", printer.settingValue(settings, key))
+	}
+	return printer.Text("config.set", "`" + key + "`", printer.settingValue(settings, key))
+}
+
+// Helper to show a setting's value, since embed fields can't be empty
+func (printer Printer) settingValue(settings config.Config, key string) (string) {
+	if value := settings.Get(key); value != "" {
+		return value
+	}
+	return printer.Text("config.empty")
+}
+
+// Helper to list the settings a server can change, e.g. `cooldowns.daily`, `payouts.daily`
+func settingNames() (string) {
+	names := []string{}
+	for _, key := range config.GuildKeys() {
+		names = append(names, "`" + key + "`")
+	}
+	return strings.Join(names, ", ")
+}
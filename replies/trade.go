@@ -0,0 +1,95 @@
+package replies
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	database "mary-bot/database"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// mary trade -> a message, and the trade as it stands if it's still open
+func (printer Printer) Trade(userID int, res database.TradeResult, err error) (string, *discordgo.MessageEmbed) {
+	var broken database.ErrItemBroken
+	var worn database.ErrItemWorn
+	var short database.ErrTradeShort
+	if errors.Is(err, database.ErrSelfTarget) {
+		return printer.Text("trade.self"), nil
+	} else if errors.Is(err, database.ErrNotPositive) {
+		return printer.Text("trade.not_positive"), nil
+	} else if errors.Is(err, database.ErrInsufficientFunds) {
+		return printer.Text("trade.cant_afford"), nil
+	} else if errors.Is(err, database.ErrNotEnoughItems) {
+		return printer.Text("trade.not_enough"), nil
+	} else if errors.As(err, &broken) {
+		return printer.Text("trade.broken", broken.Item), nil
+	} else if errors.As(err, &worn) {
+		return printer.Text("trade.worn", worn.Item), nil
+	} else if errors.Is(err, database.ErrTradeChanged) {
+		return printer.Text("trade.failed", printer.Error(userID, err)), nil
+	} else if errors.As(err, &short) && short.Item.Name != "" {
+		return printer.Text("trade.failed", printer.Text("trade.short_items", short.UserName, short.Item.Quantity, short.Item.Name)), nil
+	} else if errors.As(err, &short) {
+		return printer.Text("trade.failed", printer.Text("trade.short_coins", short.UserName, short.Coins)), nil
+	} else if errors.Is(err, database.ErrUnknownCommand) {
+		return printer.Text("trade.unknown_command"), nil
+	} else if err != nil {
+		return printer.Error(userID, err), nil
+	}
+
+	trade := res.Trade
+	switch res.Operation {
+	case "open":
+		return printer.Text("trade.open", trade.Partner.UserID, trade.Initiator.UserID), printer.tradeEmbed(trade)
+	case "confirm":
+		return printer.Text("trade.confirm", res.UserID), printer.tradeEmbed(trade)
+	case "complete":
+		return printer.Text("trade.complete", trade.Initiator.UserID, trade.Partner.UserID) + printer.Announcements(res.Announcements), nil
+	case "cancel":
+		return printer.Text("trade.cancel", trade.Initiator.UserID, trade.Partner.UserID), nil
+	}
+	return "", printer.tradeEmbed(trade)
+}
+
+// Helper to show both sides of a trade
+func (printer Printer) tradeEmbed(trade database.TradeSession) (*discordgo.MessageEmbed) {
+	offerText := func(offer database.TradeOffer) (string) {
+		lines := []string{}
+		for _, item := range offer.Items {
+			lines = append(lines, strconv.Itoa(item.Quantity) + "X " + database.ItemDisplayName(item.Name))
+		}
+		if offer.Coins > 0 {
+			lines = append(lines, printer.Text("coins", offer.Coins))
+		}
+		if len(lines) == 0 {
+			lines = append(lines, printer.Text("trade.nothing"))
+		}
+		if offer.Confirmed {
+			lines = append(lines, printer.Text("trade.confirmed"))
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	return &discordgo.MessageEmbed{
+		Title: printer.Text("trade.title"),
+		Description: printer.Text("trade.description"),
+		Color: 0xffc0cb,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name: trade.Initiator.UserName,
+				Value: offerText(trade.Initiator),
+				Inline: true,
+			},
+			{
+				Name: trade.Partner.UserName,
+				Value: offerText(trade.Partner),
+				Inline: true,
+			},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: printer.Text("trade.expires", printer.formatDuration(time.Until(trade.ExpiresAt))),
+		},
+	}
+}